@@ -2,19 +2,21 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
+	"task-manager-backend/api/problem"
 	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/i18n"
 	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 type LoginRequest struct {
@@ -27,37 +29,62 @@ type RegisterRequest struct {
 	Email    string `json:"email" binding:"required"`
 	Password string `json:"password" binding:"required"`
 	Role     string `json:"role" binding:"required"`
+
+	// Espacio de trabajo: el de la invitación, uno nuevo con este nombre (del que
+	// el usuario será administrador) o, si no se indica ninguno, el de por defecto
+	InviteToken   string `json:"invite_token,omitempty"`
+	WorkspaceName string `json:"workspace_name,omitempty"`
+
+	// Idioma preferido ("es" o "en"). Si no se indica se guarda el de la petición.
+	Locale string `json:"locale,omitempty"`
+}
+
+// UpdateLocaleRequest cambia el idioma preferido del usuario. Vacío elimina la
+// preferencia, de modo que se usa el de la cabecera Accept-Language.
+type UpdateLocaleRequest struct {
+	Locale string `json:"locale"`
 }
 
 func Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Println("Error parsing request:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+	if req.InviteToken != "" && req.WorkspaceName != "" {
+		respondError(c, problem.New(problem.CodeBadRequest, "Use either invite_token or workspace_name, not both"))
 		return
 	}
+	locale := localeOf(c)
+	if req.Locale != "" {
+		var ok bool
+		if locale, ok = i18n.Parse(req.Locale); !ok {
+			respondError(c, problem.New(problem.CodeBadRequest, "Unsupported locale"))
+			return
+		}
+	}
 
 	ctx := context.Background()
 	usersRef := database.Client.Collection("users")
 
-	// Check if username already exists
+	// Los nombres de usuario son únicos en todos los espacios de trabajo porque
+	// el inicio de sesión solo usa el nombre
 	docs, err := usersRef.Where("username", "==", req.Username).Documents(ctx).GetAll()
 	if err != nil {
-		log.Println("Database error (query):", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		respondError(c, problem.Internal("Database error", err))
 		return
 	}
 
 	if len(docs) > 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Username already exists"})
+		respondError(c, problem.New(problem.CodeBadRequest, "Username already exists"))
 		return
 	}
 
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		log.Println("Error hashing password:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error hashing password"})
+		respondError(c, problem.Internal("Error hashing password", err))
 		return
 	}
 
@@ -69,117 +96,152 @@ func Register(c *gin.Context) {
 		Password:  string(hashedPassword),
 		Role:      req.Role,
 		CreatedAt: time.Now(),
+		Locale:    string(locale),
 	}
 
-	// Save to Firestore
-	_, err = usersRef.Doc(user.ID).Set(ctx, map[string]interface{}{
-		"id":         user.ID,
-		"username":   user.Username,
-		"email":      user.Email,
-		"password":   user.Password,
-		"role":       user.Role,
-		"created_at": user.CreatedAt,
-	})
+	workspaceService := services.NewWorkspaceService(nil, "")
+	switch {
+	case req.InviteToken != "":
+		_, err = workspaceService.JoinWithInvite(req.InviteToken, &user)
+	case req.WorkspaceName != "":
+		_, err = workspaceService.CreateWorkspace(req.WorkspaceName, &user)
+	default:
+		user.WorkspaceID = models.DefaultWorkspaceID
+		user.WorkspaceRole = models.WorkspaceRoleMember
+
+		// Save to Firestore
+		_, err = usersRef.Doc(user.ID).Set(ctx, user.ToMap())
+	}
 
 	if err != nil {
-		log.Println("Database error (set):", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating user"})
+		switch {
+		case errors.Is(err, services.ErrWorkspaceInviteNotFound), errors.Is(err, services.ErrWorkspaceInviteUnusable):
+			respondError(c, problem.New(problem.CodeBadRequest, "Invalid or expired invite"))
+		case errors.Is(err, services.ErrInvalidWorkspace):
+			respondError(c, problem.New(problem.CodeBadRequest, err.Error()))
+		default:
+			respondError(c, problem.Internal("Error creating user", err))
+		}
 		return
 	}
 
 	log.Println("User registered successfully:", user.Username)
-	c.JSON(http.StatusCreated, gin.H{"message": "User created successfully"})
+	c.JSON(http.StatusCreated, gin.H{"message": tr(c, "User created successfully"), "workspace_id": user.WorkspaceID})
 }
 
 func Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
 		return
 	}
 
 	ctx := context.Background()
-	usersRef := database.Client.Collection("users")
 
 	// Find user by username
-	docs, err := usersRef.Where("username", "==", req.Username).Documents(ctx).GetAll()
+	user, err := services.FindUserByUsername(ctx, req.Username)
 	if err != nil {
-		log.Println("Database error (query):", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
-		return
-	}
-
-	if len(docs) == 0 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		if !errors.Is(err, services.ErrUserNotFound) {
+			log.Println("Database error (query):", err)
+		}
+		respondError(c, problem.New(problem.CodeUnauthorized, "Invalid credentials"))
 		return
 	}
 
-	userData := docs[0].Data()
-
-	// Compare passwords
-	if err := bcrypt.CompareHashAndPassword(
-		[]byte(userData["password"].(string)),
-		[]byte(req.Password),
-	); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	// Compare passwords. Un documento sin contraseña nunca coincide.
+	if err := user.ComparePassword(req.Password); err != nil {
+		respondError(c, problem.New(problem.CodeUnauthorized, "Invalid credentials"))
 		return
 	}
 
 	// Generate JWT
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": userData["id"].(string),
-		"exp":     time.Now().Add(time.Minute * 10).Unix(),
+		"user_id":      user.ID,
+		"workspace_id": user.WorkspaceID,
+		"exp":          time.Now().Add(time.Minute * 10).Unix(),
 	})
 
 	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
+		respondError(c, problem.Internal("Error generating token", err))
 		return
 	}
 
 	// Devuelve el token, username y role
 	c.JSON(http.StatusOK, gin.H{
-		"token":    tokenString,
-		"username": userData["username"].(string),
-		"role":     userData["role"].(string),
+		"token":        tokenString,
+		"username":     user.Username,
+		"role":         user.Role,
+		"workspace_id": user.WorkspaceID,
 	})
 }
 
 func GetUser(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
 		return
 	}
 
-	ctx := context.Background()
-	doc, err := database.Client.Collection("users").Doc(userID.(string)).Get(ctx)
+	user, err := services.LoadUser(context.Background(), userID.(string))
 	if err != nil {
-		if status.Code(err) == codes.NotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		if errors.Is(err, services.ErrUserNotFound) {
+			respondError(c, problem.New(problem.CodeUserNotFound, "User not found"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching user"})
+		respondError(c, problem.Internal("Error fetching user", err))
 		return
 	}
 
-	userData := doc.Data()
-	user := models.User{
-		ID:        userData["id"].(string),
-		Username:  userData["username"].(string),
-		Email:     userData["email"].(string),
-		Role:      userData["role"].(string),
-		CreatedAt: userData["created_at"].(time.Time),
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// UpdateLocale cambia el idioma preferido del usuario autenticado
+func UpdateLocale(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user": user})
+	var req UpdateLocaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+
+	var locale i18n.Locale
+	if req.Locale != "" {
+		var ok bool
+		if locale, ok = i18n.Parse(req.Locale); !ok {
+			respondError(c, problem.New(problem.CodeBadRequest, "Unsupported locale"))
+			return
+		}
+	}
+
+	if err := services.SetUserLocale(context.Background(), userID.(string), locale); err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			respondError(c, problem.New(problem.CodeUserNotFound, "User not found"))
+			return
+		}
+		respondError(c, problem.Internal("Error updating user", err))
+		return
+	}
+
+	// La respuesta ya usa el idioma nuevo
+	responseLocale := locale
+	if responseLocale == "" {
+		responseLocale = i18n.Negotiate(c.GetHeader("Accept-Language"))
+	}
+	c.Set(i18n.ContextKey, string(responseLocale))
+	c.JSON(http.StatusOK, gin.H{"locale": locale, "message": tr(c, "Language updated successfully")})
 }
 
-// SearchUser busca usuarios por correo electrónico
+// SearchUser busca usuarios del espacio de trabajo por correo electrónico
 func SearchUser(c *gin.Context) {
 	email := c.Query("email") // Obtener el correo electrónico de la query string
 	if email == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Email is required"})
+		respondError(c, problem.New(problem.CodeBadRequest, "Email is required"))
 		return
 	}
 
@@ -187,37 +249,27 @@ func SearchUser(c *gin.Context) {
 	usersRef := database.Client.Collection("users")
 
 	// Buscar usuarios por correo electrónico
-	docs, err := usersRef.Where("email", "==", email).Documents(ctx).GetAll()
+	docs, err := database.InWorkspace(usersRef.Query, workspaceOf(c)).Where("email", "==", email).Documents(ctx).GetAll()
 	if err != nil {
-		log.Println("Database error (query):", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error searching user"})
+		respondError(c, problem.Internal("Error searching user", err))
 		return
 	}
 
 	if len(docs) == 0 {
-		c.JSON(http.StatusOK, gin.H{"message": "No users found"})
+		c.JSON(http.StatusOK, gin.H{"message": tr(c, "No users found")})
 		return
 	}
 
-	// Mapear los resultados a un slice de usuarios
+	// Mapear los resultados a un slice de usuarios; los documentos que no se
+	// pueden convertir se omiten
 	var users []models.User
 	for _, doc := range docs {
-		userData := doc.Data()
-
-		// Verificar si el campo "role" existe y no está vacío
-		role, ok := userData["role"].(string)
-		if !ok || role == "" {
-			role = "user" // Valor por defecto si el campo no existe o está vacío
-		}
-
-		user := models.User{
-			ID:        userData["id"].(string),
-			Username:  userData["username"].(string),
-			Email:     userData["email"].(string),
-			Role:      role, // Usar el valor verificado
-			CreatedAt: userData["created_at"].(time.Time),
+		user, err := services.DecodeUser(doc)
+		if err != nil {
+			log.Println("Error decoding user:", err)
+			continue
 		}
-		users = append(users, user)
+		users = append(users, *user)
 	}
 
 	c.JSON(http.StatusOK, gin.H{"users": users})
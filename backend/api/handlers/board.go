@@ -0,0 +1,386 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"task-manager-backend/api/problem"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/events"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/rank"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type MoveTaskRequest struct {
+	Status       string `json:"status" binding:"required"`
+	AfterTaskID  string `json:"after_task_id,omitempty"`  // Tarea que quedará justo encima (opcional)
+	BeforeTaskID string `json:"before_task_id,omitempty"` // Tarea que quedará justo debajo (opcional)
+}
+
+// BoardColumn es una columna del tablero con sus tareas en orden
+type BoardColumn struct {
+	Status models.WorkflowStatus `json:"status"`
+	Tasks  []models.Task         `json:"tasks"`
+}
+
+// errUnrankedNeighbor indica que un vecino aún no tiene posición en su columna
+var errUnrankedNeighbor = problem.New(problem.CodeConflict, "Neighbor task has no position yet; reload the board")
+
+// MoveTask cambia el estado y la posición de una tarea en una sola escritura.
+// Si no se indican vecinos la tarea se coloca al final de la columna.
+func MoveTask(c *gin.Context) {
+	taskID := c.Param("id")
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	var req MoveTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+
+	ctx := context.Background()
+	taskRef := database.Client.Collection("tasks").Doc(taskID)
+	check := ifMatch(c)
+
+	var task, previousTask models.Task
+
+	move := func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(taskRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return problem.New(problem.CodeTaskNotFound, "Task not found")
+			}
+			return err
+		}
+
+		task = models.Task{}
+		if err := decodeTask(doc, workspaceOf(c), &task); err != nil {
+			return err
+		}
+		previousTask = task
+
+		group, err := loadTaskGroup(ctx, tx, task.WorkspaceID, task.GroupID)
+		if err != nil {
+			return err
+		}
+
+		if accessToTask(&task, group, userID.(string)) < taskAccessCollaborate {
+			return problem.New(problem.CodeForbidden, "Unauthorized to modify this task")
+		}
+
+		if check != nil && !check(task.Version) {
+			return errPreconditionFailed
+		}
+
+		workflow := taskWorkflow(group)
+		if !workflow.CanTransition(task.Status, req.Status) {
+			return problem.Newf(problem.CodeInvalidTransition, "Transition from %s to %s is not allowed", task.Status, req.Status)
+		}
+
+		prevRank, nextRank, err := neighborRanks(ctx, tx, &task, req)
+		if err != nil {
+			return err
+		}
+
+		newRank, err := rank.Between(prevRank, nextRank)
+		if err != nil {
+			return problem.New(problem.CodeConflict, "The board changed; reload it and try again")
+		}
+
+		task.Status = req.Status
+		task.Rank = newRank
+		task.UpdatedAt = time.Now()
+		task.Version++
+
+		return tx.Update(taskRef, []firestore.Update{
+			{Path: "status", Value: task.Status},
+			{Path: "rank", Value: task.Rank},
+			{Path: "updated_at", Value: task.UpdatedAt},
+			{Path: "version", Value: task.Version},
+		})
+	}
+
+	err := database.Client.RunTransaction(ctx, move)
+	if errors.Is(err, errUnrankedNeighbor) {
+		// Dar posición a las tareas de la columna destino y volver a intentarlo
+		target := task
+		if _, err = rebalanceColumn(ctx, &target, req.Status); err == nil {
+			err = database.Client.RunTransaction(ctx, move)
+		}
+	}
+	if err != nil {
+		respondError(c, problem.Internal("Error moving task", err))
+		return
+	}
+
+	// Las inserciones repetidas en el mismo hueco alargan las claves
+	if len(task.Rank) > rank.MaxLength {
+		if tasks, err := rebalanceColumn(ctx, &task, task.Status); err != nil {
+			log.Printf("Error rebalancing board column: %v", err)
+		} else {
+			for _, t := range tasks {
+				if t.ID == task.ID {
+					task.Rank = t.Rank
+				}
+			}
+		}
+	}
+
+	publishTaskEvent(c, events.TaskUpdated, &task, &previousTask)
+
+	setETag(c, task.Version)
+	c.JSON(http.StatusOK, gin.H{"message": tr(c, "Task moved successfully"), "task": task})
+}
+
+// neighborRanks determina las claves entre las que debe quedar la tarea movida
+func neighborRanks(ctx context.Context, tx *firestore.Transaction, task *models.Task, req MoveTaskRequest) (string, string, error) {
+	var prevRank, nextRank string
+
+	if req.AfterTaskID != "" {
+		after, err := loadNeighbor(tx, task, req.AfterTaskID, req.Status)
+		if err != nil {
+			return "", "", err
+		}
+		prevRank = after.Rank
+	}
+	if req.BeforeTaskID != "" {
+		before, err := loadNeighbor(tx, task, req.BeforeTaskID, req.Status)
+		if err != nil {
+			return "", "", err
+		}
+		nextRank = before.Rank
+	}
+
+	switch {
+	case req.AfterTaskID != "" && req.BeforeTaskID != "":
+		return prevRank, nextRank, nil
+	case req.AfterTaskID != "":
+		// Buscar la tarea que sigue a la indicada
+		q := columnQuery(task, req.Status).Where("rank", ">", prevRank).OrderBy("rank", firestore.Asc).Limit(2)
+		next, err := firstOtherRank(ctx, tx, q, task.ID)
+		return prevRank, next, err
+	case req.BeforeTaskID != "":
+		// Buscar la tarea que precede a la indicada
+		q := columnQuery(task, req.Status).Where("rank", "<", nextRank).OrderBy("rank", firestore.Desc).Limit(2)
+		prev, err := firstOtherRank(ctx, tx, q, task.ID)
+		return prev, nextRank, err
+	default:
+		q := columnQuery(task, req.Status).OrderBy("rank", firestore.Desc).Limit(2)
+		prev, err := firstOtherRank(ctx, tx, q, task.ID)
+		return prev, "", err
+	}
+}
+
+// loadNeighbor obtiene una tarea vecina y verifica que esté en la columna destino
+func loadNeighbor(tx *firestore.Transaction, task *models.Task, neighborID, targetStatus string) (*models.Task, error) {
+	if neighborID == task.ID {
+		return nil, problem.New(problem.CodeBadRequest, "A task cannot be placed next to itself")
+	}
+
+	doc, err := tx.Get(database.Client.Collection("tasks").Doc(neighborID))
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, problem.New(problem.CodeBadRequest, "Neighbor task not found")
+		}
+		return nil, err
+	}
+
+	var neighbor models.Task
+	if err := doc.DataTo(&neighbor); err != nil {
+		return nil, err
+	}
+	if !models.SameWorkspace(neighbor.WorkspaceID, task.WorkspaceID) {
+		return nil, problem.New(problem.CodeBadRequest, "Neighbor task not found")
+	}
+
+	sameColumn := neighbor.Status == targetStatus && sameGroup(neighbor.GroupID, task.GroupID)
+	if task.GroupID == nil || *task.GroupID == "" {
+		sameColumn = sameColumn && neighbor.UserID == task.UserID
+	}
+	if !sameColumn {
+		return nil, problem.New(problem.CodeUnprocessable, "Neighbor task is not in the target column")
+	}
+	if neighbor.Rank == "" {
+		return nil, errUnrankedNeighbor
+	}
+
+	return &neighbor, nil
+}
+
+// columnQuery devuelve la consulta de las tareas que comparten columna con una tarea.
+// Las consultas ordenadas por rank requieren un índice compuesto en Firestore.
+func columnQuery(task *models.Task, taskStatus string) firestore.Query {
+	tasksRef := database.InWorkspace(database.Client.Collection("tasks").Query, task.WorkspaceID)
+	if task.GroupID != nil && *task.GroupID != "" {
+		return tasksRef.Where("group_id", "==", *task.GroupID).Where("status", "==", taskStatus)
+	}
+	return tasksRef.Where("user_id", "==", task.UserID).Where("status", "==", taskStatus)
+}
+
+// firstOtherRank devuelve la clave del primer resultado que no sea la propia tarea
+func firstOtherRank(ctx context.Context, tx *firestore.Transaction, q firestore.Query, taskID string) (string, error) {
+	var docs []*firestore.DocumentSnapshot
+	var err error
+	if tx != nil {
+		docs, err = tx.Documents(q).GetAll()
+	} else {
+		docs, err = q.Documents(ctx).GetAll()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	for _, doc := range docs {
+		if doc.Ref.ID == taskID {
+			continue
+		}
+		if r, ok := doc.Data()["rank"].(string); ok {
+			return r, nil
+		}
+	}
+	return "", nil
+}
+
+// appendRank devuelve la clave que coloca una tarea nueva al final de su columna.
+// Si la clave resulta demasiado larga redistribuye antes la columna.
+func appendRank(ctx context.Context, task *models.Task) (string, error) {
+	q := columnQuery(task, task.Status).OrderBy("rank", firestore.Desc).Limit(2)
+	last, err := firstOtherRank(ctx, nil, q, task.ID)
+	if err != nil {
+		return "", err
+	}
+	key, err := rank.Between(last, "")
+	if err != nil || len(key) <= rank.MaxLength {
+		return key, err
+	}
+
+	tasks, err := rebalanceColumn(ctx, task, task.Status)
+	if err != nil {
+		log.Printf("Error rebalancing board column: %v", err)
+		return key, nil
+	}
+	last = ""
+	for _, t := range tasks {
+		if t.ID != task.ID && t.Rank > last {
+			last = t.Rank
+		}
+	}
+	return rank.Between(last, "")
+}
+
+// rebalanceColumn ordena las tareas de la columna de una tarea y les asigna claves
+// de rank.Spread, de modo que las claves vuelven a ser cortas y las tareas sin
+// posición (creadas antes del tablero o que cambiaron de grupo) quedan al final con
+// una. Solo cambia rank: el orden del tablero es el mismo, así que la versión no se
+// incrementa. Las tareas modificadas mientras tanto conservan su clave. Devuelve las
+// tareas en orden.
+func rebalanceColumn(ctx context.Context, task *models.Task, taskStatus string) ([]models.Task, error) {
+	docs, err := columnQuery(task, taskStatus).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]models.Task, 0, len(docs))
+	updateTimes := make(map[string]time.Time, len(docs))
+	for _, doc := range docs {
+		var task models.Task
+		if err := doc.DataTo(&task); err != nil {
+			return nil, err
+		}
+		task.ID = doc.Ref.ID
+		updateTimes[task.ID] = doc.UpdateTime
+		tasks = append(tasks, task)
+	}
+	models.SortByRank(tasks)
+
+	keys := rank.Spread(len(tasks))
+	writer := database.Client.BulkWriter(ctx)
+	jobs := make(map[int]*firestore.BulkWriterJob)
+	for i := range tasks {
+		if tasks[i].Rank == keys[i] {
+			continue
+		}
+		job, err := writer.Update(database.Client.Collection("tasks").Doc(tasks[i].ID),
+			[]firestore.Update{{Path: "rank", Value: keys[i]}},
+			firestore.LastUpdateTime(updateTimes[tasks[i].ID]))
+		if err != nil {
+			writer.End()
+			return nil, err
+		}
+		jobs[i] = job
+	}
+	writer.End()
+
+	for i, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			if status.Code(err) != codes.FailedPrecondition {
+				return nil, err
+			}
+			continue
+		}
+		tasks[i].Rank = keys[i]
+	}
+	return tasks, nil
+}
+
+// GetBoardHandler devuelve las tareas del grupo agrupadas por columna de estado y en orden
+func (h *GroupHandler) GetBoardHandler(c *gin.Context) {
+	groupID := c.Param("id")
+	userID, _ := c.Get("user_id")
+
+	group, err := h.groupService.InWorkspace(workspaceOf(c)).GetGroupByID(groupID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !group.IsMember(userID.(string)) {
+		respondError(c, problem.New(problem.CodeForbidden, "You are not a member of this group"))
+		return
+	}
+
+	ctx := context.Background()
+	docs, err := database.InWorkspace(database.Client.Collection("tasks").Query, workspaceOf(c)).
+		Where("group_id", "==", groupID).
+		Documents(ctx).GetAll()
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching group tasks", err))
+		return
+	}
+
+	byStatus := make(map[string][]models.Task)
+	for _, doc := range docs {
+		var task models.Task
+		if err := doc.DataTo(&task); err != nil {
+			log.Printf("Error converting document to task: %v", err)
+			continue
+		}
+		byStatus[task.Status] = append(byStatus[task.Status], task)
+	}
+
+	workflow := group.TaskWorkflow()
+	columns := make([]BoardColumn, 0, len(workflow.Statuses))
+	for _, s := range workflow.Statuses {
+		tasks := byStatus[s.Key]
+		if tasks == nil {
+			tasks = []models.Task{}
+		}
+		// Las tareas sin posición van al final sin escribirla: la reciben con
+		// la migración backfill-ranks o al mover tareas de su columna
+		models.SortByRank(tasks)
+		columns = append(columns, BoardColumn{Status: s, Tasks: tasks})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"group_id": group.ID, "columns": columns})
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"task-manager-backend/api/problem"
+	"task-manager-backend/internal/ical"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarHandler struct {
+	calendarService *services.CalendarService
+}
+
+func NewCalendarHandler() *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: services.NewCalendarService(),
+	}
+}
+
+// GetCalendarFeedHandler indica si el usuario tiene un feed de calendario activo
+func (h *CalendarHandler) GetCalendarFeedHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	feed, err := h.calendarService.GetFeed(userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching calendar feed", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"active": feed != nil, "feed": feed})
+}
+
+// RegenerateCalendarFeedHandler genera una URL secreta nueva para el feed del
+// usuario. La URL anterior deja de funcionar.
+func (h *CalendarHandler) RegenerateCalendarFeedHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	token, feed, err := h.calendarService.RegenerateToken(userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error generating calendar feed", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"token": token,
+		"url":   calendarFeedURL(c, token),
+		"feed":  feed,
+	})
+}
+
+// RevokeCalendarFeedHandler revoca el feed del usuario
+func (h *CalendarHandler) RevokeCalendarFeedHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	if err := h.calendarService.RevokeToken(userID.(string)); err != nil {
+		if errors.Is(err, services.ErrCalendarFeedNotFound) {
+			respondError(c, problem.New(problem.CodeNotFound, "No calendar feed is active"))
+			return
+		}
+		respondError(c, problem.Internal("Error revoking calendar feed", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": tr(c, "Calendar feed revoked successfully")})
+}
+
+// ServeCalendarFeedHandler publica las tareas con fecha de vencimiento del dueño
+// del token como un calendario iCalendar. Es una ruta pública: el token secreto
+// sustituye a la autenticación porque las aplicaciones de calendario no envían JWT.
+// Las tareas son las mismas que ve el usuario en GET /api/tasks.
+//
+//	?type=todo|event  (VTODO por defecto; VEVENT para calendarios sin soporte de tareas)
+func (h *CalendarHandler) ServeCalendarFeedHandler(c *gin.Context) {
+	component := "VTODO"
+	switch c.DefaultQuery("type", "todo") {
+	case "todo":
+	case "event":
+		component = "VEVENT"
+	default:
+		respondError(c, problem.New(problem.CodeBadRequest, "type must be todo or event"))
+		return
+	}
+
+	userID, err := h.calendarService.UserIDForToken(c.Param("token"))
+	if err != nil {
+		if errors.Is(err, services.ErrCalendarFeedNotFound) {
+			respondError(c, problem.New(problem.CodeNotFound, "Calendar feed not found"))
+			return
+		}
+		respondError(c, problem.Internal("Error fetching calendar feed", err))
+		return
+	}
+
+	ctx := context.Background()
+
+	// El feed no lleva JWT: el espacio de trabajo es el del usuario del token
+	workspaceID, err := userWorkspace(ctx, userID)
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching calendar feed", err))
+		return
+	}
+
+	tasks, err := fetchVisibleTasks(ctx, workspaceID, userID)
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching tasks", err))
+		return
+	}
+
+	var buf bytes.Buffer
+	w := ical.NewWriter(&buf)
+	now := time.Now()
+	workflows := make(map[string]*models.Workflow)
+
+	w.Begin("VCALENDAR")
+	w.Property("VERSION", "2.0")
+	w.Property("PRODID", "-//Task Manager//Tasks//EN")
+	w.Property("CALSCALE", "GREGORIAN")
+	w.Property("METHOD", "PUBLISH")
+	w.Text("X-WR-CALNAME", "Tasks")
+	w.Property("REFRESH-INTERVAL;VALUE=DURATION", "PT1H")
+	w.Property("X-PUBLISHED-TTL", "PT1H")
+
+	for i := range tasks {
+		task := &tasks[i]
+		due := task.DueAt()
+		if due == nil {
+			continue
+		}
+
+		groupKey := ""
+		if task.GroupID != nil {
+			groupKey = *task.GroupID
+		}
+		workflow, ok := workflows[groupKey]
+		if !ok {
+			if workflow, err = workflowFor(ctx, nil, task.WorkspaceID, task.GroupID); err != nil {
+				// Un grupo eliminado no debe romper el feed completo
+				log.Printf("Error fetching workflow for calendar feed: %v", err)
+				workflow = models.DefaultWorkflow()
+			}
+			workflows[groupKey] = workflow
+		}
+
+		writeCalendarTask(w, component, task, *due, workflow, now)
+	}
+
+	w.End("VCALENDAR")
+
+	if err := w.Err(); err != nil {
+		respondError(c, problem.Internal("Error generating calendar feed", err))
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+// writeCalendarTask escribe una tarea como VTODO o VEVENT
+func writeCalendarTask(w *ical.Writer, component string, task *models.Task, due time.Time, workflow *models.Workflow, now time.Time) {
+	w.Begin(component)
+	w.Property("UID", task.ID+"@task-manager")
+	w.DateTime("DTSTAMP", now)
+	w.DateTime("CREATED", task.CreatedAt)
+	w.DateTime("LAST-MODIFIED", task.UpdatedAt)
+	w.Property("SEQUENCE", strconv.FormatInt(task.Version, 10))
+	w.Text("SUMMARY", task.Title)
+
+	description := task.Description
+	if name := workflow.StatusName(task.Status); name != "" {
+		description += "\n\nStatus: " + name
+	}
+	w.Text("DESCRIPTION", description)
+
+	var categories []string
+	if task.Category != "" {
+		categories = append(categories, task.Category)
+	}
+	for _, label := range task.Labels {
+		categories = append(categories, label.Name)
+	}
+	if len(categories) > 0 {
+		w.TextList("CATEGORIES", categories)
+	}
+
+	if priority := calendarPriority(task.Priority); priority != "" {
+		w.Property("PRIORITY", priority)
+	}
+
+	if component == "VEVENT" {
+		// Un evento sin DTEND termina en el mismo instante en que empieza
+		w.DateTime("DTSTART", due)
+		w.Property("STATUS", "CONFIRMED")
+	} else {
+		// La recurrencia de un VTODO se calcula desde DTSTART, que debe ser anterior a DUE
+		start := task.CreatedAt
+		if !start.Before(due) {
+			start = due.Add(-time.Hour)
+		}
+		w.DateTime("DTSTART", start)
+		w.DateTime("DUE", due)
+
+		switch {
+		case workflow.IsFinal(task.Status):
+			w.Property("STATUS", "COMPLETED")
+			w.DateTime("COMPLETED", task.UpdatedAt)
+			w.Property("PERCENT-COMPLETE", "100")
+		case len(workflow.Statuses) > 0 && task.Status == workflow.Statuses[0].Key:
+			w.Property("STATUS", "NEEDS-ACTION")
+		default:
+			w.Property("STATUS", "IN-PROCESS")
+		}
+	}
+
+	if task.Recurrence != "" {
+		w.Property("RRULE", task.Recurrence)
+	}
+
+	w.End(component)
+}
+
+// calendarPriority traduce la prioridad de la tarea a la escala 1 (máxima) - 9 de iCalendar
+func calendarPriority(priority string) string {
+	switch priority {
+	case models.TaskPriorityUrgent:
+		return "1"
+	case models.TaskPriorityHigh:
+		return "3"
+	case models.TaskPriorityMedium:
+		return "5"
+	case models.TaskPriorityLow:
+		return "9"
+	default:
+		return ""
+	}
+}
+
+// calendarFeedURL construye la URL pública del feed a partir de la petición actual
+func calendarFeedURL(c *gin.Context, token string) string {
+	return requestBaseURL(c) + "/api/calendar/" + token + "/tasks.ics"
+}
+
+// requestBaseURL devuelve el esquema y el host con los que se hizo la petición
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
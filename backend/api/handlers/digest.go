@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"net/http"
+	"task-manager-backend/api/problem"
+	"task-manager-backend/internal/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DigestSettingsRequest struct {
+	Enabled   *bool   `json:"enabled,omitempty"`
+	Frequency *string `json:"frequency,omitempty"` // daily o weekly
+	Hour      *int    `json:"hour,omitempty"`      // Hora local de envío (0-23)
+	Weekday   *int    `json:"weekday,omitempty"`   // Día de los resúmenes semanales (0 = domingo)
+	Timezone  *string `json:"timezone,omitempty"`  // Zona horaria IANA
+}
+
+type DigestHandler struct {
+	digestService *services.DigestService
+}
+
+func NewDigestHandler() *DigestHandler {
+	return &DigestHandler{
+		digestService: services.NewDigestService(),
+	}
+}
+
+// GetDigestSettingsHandler obtiene la configuración del resumen por correo
+func (h *DigestHandler) GetDigestSettingsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	settings, err := h.digestService.GetSettings(userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching digest settings", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+// UpdateDigestSettingsHandler activa el resumen o cambia su frecuencia, hora o zona
+// horaria. Los campos que no se envían conservan su valor.
+func (h *DigestHandler) UpdateDigestSettingsHandler(c *gin.Context) {
+	var req DigestSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	settings, err := h.digestService.GetSettings(userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching digest settings", err))
+		return
+	}
+
+	if req.Enabled != nil {
+		settings.Enabled = *req.Enabled
+	}
+	if req.Frequency != nil {
+		settings.Frequency = *req.Frequency
+	}
+	if req.Hour != nil {
+		settings.Hour = *req.Hour
+	}
+	if req.Weekday != nil {
+		settings.Weekday = *req.Weekday
+	}
+	if req.Timezone != nil {
+		settings.Timezone = *req.Timezone
+	}
+
+	if !settings.Validate() {
+		respondError(c, problem.New(problem.CodeBadRequest, "Invalid digest settings"))
+		return
+	}
+
+	if err := h.digestService.UpdateSettings(settings); err != nil {
+		respondError(c, problem.Internal("Error saving digest settings", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+// PreviewDigestHandler genera el resumen que recibiría ahora el usuario, en HTML
+// o en texto plano (?format=text), sin enviarlo
+func (h *DigestHandler) PreviewDigestHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	settings, err := h.digestService.GetSettings(userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching digest settings", err))
+		return
+	}
+
+	digest, err := h.digestService.BuildDigest(settings, time.Now())
+	if err != nil {
+		respondError(c, problem.Internal("Error building digest", err))
+		return
+	}
+
+	text, html, err := digest.Render()
+	if err != nil {
+		respondError(c, problem.Internal("Error rendering digest", err))
+		return
+	}
+
+	if c.Query("format") == "text" {
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(text))
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
+}
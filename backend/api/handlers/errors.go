@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"task-manager-backend/api/problem"
+	"task-manager-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errPreconditionFailed se devuelve cuando el If-Match no coincide con la versión almacenada
+var errPreconditionFailed = problem.New(problem.CodePreconditionFailed, "The resource was modified by someone else; reload it and try again")
+
+// serviceErrors asigna un código a los errores conocidos de los servicios. El
+// detalle de la respuesta es el mensaje del error.
+var serviceErrors = []struct {
+	err  error
+	code problem.Code
+}{
+	{services.ErrGroupNotFound, problem.CodeGroupNotFound},
+	{services.ErrUserNotFound, problem.CodeUserNotFound},
+	{services.ErrNotMember, problem.CodeNotMember},
+	{services.ErrGroupForbidden, problem.CodeGroupRole},
+	{services.ErrInvalidRole, problem.CodeInvalidRole},
+	{services.ErrInvalidGroup, problem.CodeInvalidGroup},
+	{services.ErrGroupCycle, problem.CodeGroupHierarchy},
+	{services.ErrGroupTooDeep, problem.CodeGroupHierarchy},
+	{services.ErrAlreadyMember, problem.CodeAlreadyMember},
+	{services.ErrOwnerRole, problem.CodeOwnerRole},
+	{services.ErrStatusInUse, problem.CodeStatusInUse},
+	{services.ErrInheritedMember, problem.CodeInheritedMember},
+	{services.ErrHasSubgroups, problem.CodeHasSubgroups},
+	{services.ErrLastAdmin, problem.CodeLastAdmin},
+	{services.ErrInvalidWorkspace, problem.CodeBadRequest},
+	{services.ErrInvitationNotFound, problem.CodeNotFound},
+	{services.ErrInvitationExists, problem.CodeConflict},
+	{services.ErrInvitationClosed, problem.CodeInviteClosed},
+	{services.ErrInviteLinkNotFound, problem.CodeNotFound},
+	{services.ErrInviteLinkUnusable, problem.CodeInviteClosed},
+	{services.ErrWorkspaceInviteNotFound, problem.CodeNotFound},
+	{services.ErrWorkspaceInviteUnusable, problem.CodeInviteClosed},
+	{services.ErrWorkspaceInviteExists, problem.CodeConflict},
+	{services.ErrAlreadyInWorkspace, problem.CodeConflict},
+	{services.ErrNotificationNotFound, problem.CodeNotFound},
+	{services.ErrCalendarFeedNotFound, problem.CodeNotFound},
+	{services.ErrTimeEntryNotFound, problem.CodeNotFound},
+	{services.ErrTimerRunning, problem.CodeConflict},
+	{services.ErrNoTimerRunning, problem.CodeConflict},
+	{services.ErrTemplateNotFound, problem.CodeNotFound},
+	{services.ErrWebhookNotFound, problem.CodeNotFound},
+	{services.ErrWebhookDeliveryNotFound, problem.CodeNotFound},
+	{services.ErrLabelNotFound, problem.CodeNotFound},
+	{services.ErrLabelExists, problem.CodeConflict},
+	{services.ErrLabelScope, problem.CodeBadRequest},
+}
+
+// apiError convierte un error en un error de la API. Un error interno cuya causa
+// es un error de la API (por ejemplo, devuelto dentro de una transacción) o un
+// error conocido de los servicios se sustituye por este.
+func apiError(err error) *problem.Error {
+	for cause := err; cause != nil; cause = errors.Unwrap(cause) {
+		if apiErr, ok := cause.(*problem.Error); ok && apiErr.Code != problem.CodeInternal {
+			return apiErr
+		}
+		if errors.Is(cause, services.ErrVersionConflict) {
+			return errPreconditionFailed
+		}
+		for _, known := range serviceErrors {
+			if cause == known.err {
+				return problem.New(known.code, known.err.Error())
+			}
+		}
+		if status.Code(cause) == codes.NotFound {
+			return problem.New(problem.CodeNotFound, "Resource not found")
+		}
+	}
+	return problem.From(err)
+}
+
+// respondError responde con el error como problem+json (RFC 7807). Los errores
+// internos se registran con el ID de correlación y no se envían al cliente.
+func respondError(c *gin.Context, err error) {
+	problem.Respond(c, apiError(err))
+}
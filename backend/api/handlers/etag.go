@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"task-manager-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etagFor construye el ETag de un documento a partir de su versión
+func etagFor(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// setETag agrega la cabecera ETag a la respuesta
+func setETag(c *gin.Context, version int64) {
+	c.Header("ETag", etagFor(version))
+}
+
+// etagMatches compara una lista de ETags de una cabecera If-Match/If-None-Match con una versión.
+// If-Match exige la comparación fuerte (RFC 7232 §3.1), en la que un ETag débil (W/)
+// nunca coincide; If-None-Match usa la débil, que ignora el prefijo.
+func etagMatches(header string, version int64, weak bool) bool {
+	current := etagFor(version)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == current {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatch convierte la cabecera If-Match en una comprobación de versión.
+// Si el cliente no envía la cabecera, la escritura no se condiciona.
+func ifMatch(c *gin.Context) services.VersionCheck {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return nil
+	}
+	return func(current int64) bool {
+		return etagMatches(header, current, false)
+	}
+}
+
+// notModified responde 304 si el cliente ya tiene la versión actual del documento
+func notModified(c *gin.Context, version int64) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" || !etagMatches(header, version, true) {
+		return false
+	}
+	setETag(c, version)
+	c.Status(http.StatusNotModified)
+	return true
+}
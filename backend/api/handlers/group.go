@@ -1,55 +1,122 @@
 package handlers
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"task-manager-backend/api/problem"
+	"task-manager-backend/internal/events"
 	"task-manager-backend/internal/models"
 	"task-manager-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
+type UpdateGroupRequest struct {
+	Name        *string          `json:"name,omitempty"`
+	Description *string          `json:"description,omitempty"`
+	Workflow    *models.Workflow `json:"workflow,omitempty"` // Estados y transiciones de las tareas del grupo
+}
+
+type MemberRoleRequest struct {
+	Role string `json:"role" binding:"required"` // admin, member o viewer
+}
+
+type TransferOwnershipRequest struct {
+	UserID string `json:"user_id" binding:"required"` // Miembro que pasa a ser propietario
+}
+
+type MoveGroupRequest struct {
+	ParentID *string `json:"parent_id"` // Nuevo grupo padre; null para mover el grupo a la raíz
+}
+
 type GroupHandler struct {
-	groupService *services.GroupService
+	groupService      *services.GroupService
+	invitationService *services.InvitationService
 }
 
-func NewGroupHandler() *GroupHandler {
+func NewGroupHandler(invitationService *services.InvitationService) *GroupHandler {
 	return &GroupHandler{
-		groupService: services.NewGroupService(),
+		groupService:      services.NewGroupService(),
+		invitationService: invitationService,
 	}
 }
 
-// CreateGroupHandler maneja la creación de un grupo
+// CreateGroupHandler maneja la creación de un grupo. Con parent_id se crea como
+// subgrupo, y los miembros del grupo padre pasan a serlo también de este. El
+// creador es el único miembro directo: el resto de members recibe una invitación
+// que debe aceptar, igual que con AddMemberHandler.
 func (h *GroupHandler) CreateGroupHandler(c *gin.Context) {
 	var group models.Group
 	if err := c.ShouldBindJSON(&group); err != nil {
 		log.Printf("Error binding JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
 		return
 	}
 
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
 		return
 	}
 
-	// Asignar el creador y inicializar campos
+	// Los miembros que envía el cliente se invitan; no entran sin aceptar
+	var invitees []string
+	for _, memberID := range uniqueStrings(group.Members) {
+		if memberID != userID.(string) {
+			invitees = append(invitees, memberID)
+		}
+	}
+
+	// Asignar el creador como único miembro y propietario
 	group.CreatorID = userID.(string)
-	// Inicializar el slice de miembros si es nil
-	if group.Members == nil {
-		group.Members = []string{}
+	group.Members = []string{userID.(string)}
+	group.Roles = map[string]string{userID.(string): models.GroupRoleOwner}
+	// La ruta en la jerarquía la calcula el servicio a partir de parent_id
+	group.Path = nil
+
+	if !group.Validate() {
+		respondError(c, problem.New(problem.CodeBadRequest, "Invalid group data"))
+		return
 	}
-	// Agregar el creador como miembro
-	group.Members = append(group.Members, userID.(string))
 
-	if err := h.groupService.CreateGroup(&group); err != nil {
+	// Los invitados deben ser usuarios del espacio de trabajo
+	groupService := h.groupService.InWorkspace(workspaceOf(c))
+	members, err := groupService.GetGroupMembersDetails(invitees)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if len(members) != len(invitees) {
+		respondError(c, problem.New(problem.CodeBadRequest, "All members must belong to the workspace"))
+		return
+	}
+
+	if err := groupService.CreateGroup(&group); err != nil {
 		log.Printf("Error creating group: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"group": group})
+	publishGroupEvent(c, events.GroupCreated, &group, "")
+
+	// El grupo ya existe: si una invitación falla se registra y se devuelven las demás
+	invitations := make([]models.GroupInvitation, 0, len(invitees))
+	for _, inviteeID := range invitees {
+		if group.IsMember(inviteeID) {
+			// Ya tiene acceso como miembro de un grupo antecesor
+			continue
+		}
+		invitation, err := h.invitationService.Invite(&group, userID.(string), inviteeID, "")
+		if err != nil {
+			log.Printf("Error inviting %s to new group %s: %v", inviteeID, group.ID, err)
+			continue
+		}
+		publishInvitationEvent(c, invitation)
+		invitations = append(invitations, *invitation)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"group": group, "invitations": invitations})
 }
 
 // GetGroupHandler obtiene un grupo por su ID
@@ -59,125 +126,285 @@ func (h *GroupHandler) GetGroupHandler(c *gin.Context) {
 	// Verificar que el usuario tiene acceso al grupo
 	userID, _ := c.Get("user_id")
 
-	group, err := h.groupService.GetGroupByID(groupID)
+	group, err := h.groupService.InWorkspace(workspaceOf(c)).GetGroupByID(groupID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	// Verificar si el usuario es miembro del grupo
-	isMember := false
-	for _, memberID := range group.Members {
-		if memberID == userID.(string) {
-			isMember = true
-			break
-		}
+	// Verificar si el usuario es miembro del grupo o de un antecesor
+	if !group.IsMember(userID.(string)) {
+		respondError(c, problem.New(problem.CodeForbidden, "You are not a member of this group"))
+		return
 	}
 
-	if !isMember {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You are not a member of this group"})
+	if notModified(c, group.Version) {
 		return
 	}
 
 	// Obtener información detallada de los miembros
-	membersList, err := h.groupService.GetGroupMembersDetails(group.Members)
+	membersList, err := h.groupService.InWorkspace(workspaceOf(c)).GetGroupMembersDetails(group.AllMembers())
 	if err != nil {
 		log.Printf("Error getting members details: %v", err)
 		// No devolvemos error, solo continuamos con los IDs de los miembros
 	}
 
-	response := gin.H{"group": group}
+	response := gin.H{"group": group, "roles": group.MemberRoles()}
 	if membersList != nil {
 		response["members"] = membersList
 	}
 
+	setETag(c, group.Version)
 	c.JSON(http.StatusOK, response)
 }
 
-// GetAllGroupsHandler obtiene todos los grupos del usuario
+// GetGroupTasksHandler obtiene las tareas del grupo con los filtros y el orden de
+// filterAndSortTasks. ?assigned_to=<id> filtra por asignado; "me" es el usuario
+// actual y "none" las tareas sin asignar.
+func (h *GroupHandler) GetGroupTasksHandler(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	group, err := h.groupService.InWorkspace(workspaceOf(c)).GetGroupByID(c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !group.Can(userID.(string), models.PermViewGroup) {
+		respondError(c, problem.New(problem.CodeForbidden, "You are not a member of this group"))
+		return
+	}
+
+	tasks, err := fetchGroupTasks(context.Background(), workspaceOf(c), group.ID)
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching group tasks", err))
+		return
+	}
+
+	if assignee := c.Query("assigned_to"); assignee != "" {
+		if assignee == "me" {
+			assignee = userID.(string)
+		}
+		filtered := make([]models.Task, 0, len(tasks))
+		for _, task := range tasks {
+			assigned := task.AssignedTo != nil && *task.AssignedTo != ""
+			if (assignee == "none" && !assigned) || (assigned && *task.AssignedTo == assignee) {
+				filtered = append(filtered, task)
+			}
+		}
+		tasks = filtered
+	}
+
+	tasks, err = filterAndSortTasks(tasks, c.Request.URL.Query())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tasks": tasks})
+}
+
+// GetAllGroupsHandler obtiene todos los grupos del usuario, incluidos los
+// subgrupos de sus grupos, como lista ordenada por jerarquía y como árbol
 func (h *GroupHandler) GetAllGroupsHandler(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
 		return
 	}
 
-	groups, err := h.groupService.GetUserGroups(userID.(string))
+	groups, err := h.groupService.InWorkspace(workspaceOf(c)).GetUserGroups(userID.(string))
 	if err != nil {
-		log.Printf("Error getting user groups: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"groups": groups})
+	c.JSON(http.StatusOK, gin.H{"groups": groups, "tree": models.GroupTree(groups)})
 }
 
-// AddMemberHandler agrega un miembro a un grupo
+// AddMemberHandler invita a un usuario registrado al grupo. El usuario pasa a ser
+// miembro cuando acepta la invitación.
 func (h *GroupHandler) AddMemberHandler(c *gin.Context) {
+	inviteToGroup(c, h.invitationService, h.groupService.InWorkspace(workspaceOf(c)), c.Param("id"), c.Param("user_id"), "")
+}
+
+// RemoveMemberHandler elimina un miembro de un grupo. Los permisos según el rol
+// se comprueban en GroupService.RemoveMemberFromGroup.
+func (h *GroupHandler) RemoveMemberHandler(c *gin.Context) {
 	groupID := c.Param("id")
 	userID := c.Param("user_id")
+	currentUserID, _ := c.Get("user_id")
+
+	if err := h.groupService.InWorkspace(workspaceOf(c)).RemoveMemberFromGroup(groupID, currentUserID.(string), userID, ifMatch(c)); err != nil {
+		log.Printf("Error removing member from group: %v", err)
+		respondError(c, err)
+		return
+	}
+
+	publishMemberEvent(c, h.groupService.InWorkspace(workspaceOf(c)), events.GroupMemberRemoved, groupID, userID)
+
+	c.JSON(http.StatusOK, gin.H{"message": tr(c, "Member removed successfully")})
+}
+
+// UpdateMemberRoleHandler cambia el rol de un miembro del grupo
+func (h *GroupHandler) UpdateMemberRoleHandler(c *gin.Context) {
+	var req MemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
 
-	// Verificar que el usuario actual tiene permisos para añadir miembros
 	currentUserID, _ := c.Get("user_id")
 
-	group, err := h.groupService.GetGroupByID(groupID)
+	group, err := h.groupService.InWorkspace(workspaceOf(c)).SetMemberRole(c.Param("id"), currentUserID.(string), c.Param("user_id"), req.Role, ifMatch(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	// Verificar si el usuario actual es el creador o un miembro del grupo
-	isAuthorized := group.CreatorID == currentUserID.(string)
-	if !isAuthorized {
-		for _, memberID := range group.Members {
-			if memberID == currentUserID.(string) {
-				isAuthorized = true
-				break
-			}
-		}
+	publishGroupEvent(c, events.GroupUpdated, group, "")
+
+	setETag(c, group.Version)
+	c.JSON(http.StatusOK, gin.H{"group": group, "roles": group.MemberRoles()})
+}
+
+// TransferOwnershipHandler cede la propiedad del grupo a otro miembro
+func (h *GroupHandler) TransferOwnershipHandler(c *gin.Context) {
+	var req TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
 	}
 
-	if !isAuthorized {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to add members to this group"})
+	currentUserID, _ := c.Get("user_id")
+
+	group, err := h.groupService.InWorkspace(workspaceOf(c)).TransferOwnership(c.Param("id"), currentUserID.(string), req.UserID, ifMatch(c))
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	if err := h.groupService.AddMemberToGroup(groupID, userID); err != nil {
-		log.Printf("Error adding member to group: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	publishGroupEvent(c, events.GroupUpdated, group, "")
+
+	setETag(c, group.Version)
+	c.JSON(http.StatusOK, gin.H{"group": group, "roles": group.MemberRoles()})
+}
+
+// MoveGroupHandler mueve el grupo, con sus subgrupos, bajo otro grupo o a la raíz
+func (h *GroupHandler) MoveGroupHandler(c *gin.Context) {
+	var req MoveGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Member added successfully"})
+	currentUserID, _ := c.Get("user_id")
+
+	group, err := h.groupService.InWorkspace(workspaceOf(c)).MoveGroup(c.Param("id"), currentUserID.(string), req.ParentID, ifMatch(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	publishGroupEvent(c, events.GroupUpdated, group, "")
+
+	setETag(c, group.Version)
+	c.JSON(http.StatusOK, gin.H{"group": group, "roles": group.MemberRoles()})
 }
 
-// RemoveMemberHandler elimina un miembro de un grupo
-func (h *GroupHandler) RemoveMemberHandler(c *gin.Context) {
-	groupID := c.Param("id")
-	userID := c.Param("user_id")
+// publishMemberEvent publica el alta o la baja de un miembro con el estado actual del grupo
+func publishMemberEvent(c *gin.Context, groupService *services.GroupService, eventType, groupID, memberID string) {
+	group, err := groupService.GetGroupByID(groupID)
+	if err != nil {
+		log.Printf("Error fetching group for event: %v", err)
+		return
+	}
+	publishGroupEvent(c, eventType, group, memberID)
+}
 
-	// Verificar que el usuario actual tiene permisos para eliminar miembros
+// UpdateGroupHandler modifica el nombre, la descripción o el flujo de trabajo del
+// grupo. Los campos que no se envían conservan su valor.
+func (h *GroupHandler) UpdateGroupHandler(c *gin.Context) {
+	var req UpdateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+
+	if req.Workflow != nil && !req.Workflow.Validate() {
+		respondError(c, problem.New(problem.CodeBadRequest, "Invalid workflow"))
+		return
+	}
+
+	h.updateGroup(c, services.GroupChanges{Name: req.Name, Description: req.Description, Workflow: req.Workflow})
+}
+
+// UpdateWorkflowHandler reemplaza los estados y transiciones de las tareas del grupo
+func (h *GroupHandler) UpdateWorkflowHandler(c *gin.Context) {
+	var workflow models.Workflow
+	if err := c.ShouldBindJSON(&workflow); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+
+	if !workflow.Validate() {
+		respondError(c, problem.New(problem.CodeBadRequest, "Invalid workflow"))
+		return
+	}
+
+	h.updateGroup(c, services.GroupChanges{Workflow: &workflow})
+}
+
+// updateGroup aplica los cambios al grupo de la ruta y responde con el grupo actualizado
+func (h *GroupHandler) updateGroup(c *gin.Context, changes services.GroupChanges) {
 	currentUserID, _ := c.Get("user_id")
 
-	group, err := h.groupService.GetGroupByID(groupID)
+	group, err := h.groupService.InWorkspace(workspaceOf(c)).UpdateGroup(c.Param("id"), currentUserID.(string), changes, ifMatch(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	publishGroupEvent(c, events.GroupUpdated, group, "")
+
+	setETag(c, group.Version)
+	c.JSON(http.StatusOK, gin.H{"group": group})
+}
+
+// DeleteGroupHandler elimina el grupo. ?tasks indica qué hacer con sus tareas:
+// archive (por defecto), reassign para pasarlas al propietario o delete.
+func (h *GroupHandler) DeleteGroupHandler(c *gin.Context) {
+	disposal := c.DefaultQuery("tasks", services.GroupTasksArchive)
+	if !services.IsGroupTaskDisposal(disposal) {
+		respondError(c, problem.New(problem.CodeBadRequest, "tasks must be archive, reassign or delete"))
 		return
 	}
 
-	// Verificar si el usuario actual es el creador o si se está eliminando a sí mismo
-	isAuthorized := group.CreatorID == currentUserID.(string) || userID == currentUserID.(string)
+	currentUserID, _ := c.Get("user_id")
 
-	if !isAuthorized {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to remove this member from the group"})
+	group, err := h.groupService.InWorkspace(workspaceOf(c)).DeleteGroup(c.Param("id"), currentUserID.(string), disposal, ifMatch(c))
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	if err := h.groupService.RemoveMemberFromGroup(groupID, userID); err != nil {
-		log.Printf("Error removing member from group: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	publishGroupEvent(c, events.GroupDeleted, group, "")
+
+	c.JSON(http.StatusOK, gin.H{"message": tr(c, "Group deleted successfully"), "tasks": disposal})
+}
+
+// LeaveGroupHandler saca al usuario actual del grupo. El propietario debe
+// transferir antes la propiedad.
+func (h *GroupHandler) LeaveGroupHandler(c *gin.Context) {
+	groupID := c.Param("id")
+	currentUserID, _ := c.Get("user_id")
+
+	if err := h.groupService.InWorkspace(workspaceOf(c)).RemoveMemberFromGroup(groupID, currentUserID.(string), currentUserID.(string), nil); err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+	publishMemberEvent(c, h.groupService.InWorkspace(workspaceOf(c)), events.GroupMemberRemoved, groupID, currentUserID.(string))
+
+	c.JSON(http.StatusOK, gin.H{"message": tr(c, "You left the group")})
 }
@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"task-manager-backend/api/problem"
+	"task-manager-backend/internal/events"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvitationRequest struct {
+	UserID string `json:"user_id,omitempty"` // Usuario registrado
+	Email  string `json:"email,omitempty"`   // O dirección de correo, registrada o no
+}
+
+type InviteLinkRequest struct {
+	MaxUses        int `json:"max_uses" binding:"min=0"`         // 0 = sin límite
+	ExpiresInHours int `json:"expires_in_hours" binding:"min=0"` // 0 = no caduca
+}
+
+type InvitationHandler struct {
+	invitationService *services.InvitationService
+	groupService      *services.GroupService
+}
+
+func NewInvitationHandler(invitationService *services.InvitationService) *InvitationHandler {
+	return &InvitationHandler{
+		invitationService: invitationService,
+		groupService:      services.NewGroupService(),
+	}
+}
+
+// CreateInvitationHandler invita a un usuario o a una dirección de correo al grupo
+func (h *InvitationHandler) CreateInvitationHandler(c *gin.Context) {
+	var req InvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+	if (req.UserID == "") == (req.Email == "") {
+		respondError(c, problem.New(problem.CodeBadRequest, "Provide either user_id or email"))
+		return
+	}
+	if req.Email != "" && !models.IsValidEmail(req.Email) {
+		respondError(c, problem.New(problem.CodeBadRequest, "Invalid email address"))
+		return
+	}
+
+	inviteToGroup(c, h.invitationService, h.groupService.InWorkspace(workspaceOf(c)), c.Param("id"), req.UserID, req.Email)
+}
+
+// inviteToGroup crea la invitación si el rol del usuario actual permite gestionar miembros
+func inviteToGroup(c *gin.Context, invitationService *services.InvitationService, groupService *services.GroupService, groupID, inviteeID, email string) {
+	currentUserID, _ := c.Get("user_id")
+
+	group, err := groupService.GetGroupByID(groupID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !group.Can(currentUserID.(string), models.PermManageMembers) {
+		respondError(c, problem.New(problem.CodeForbidden, "You don't have permission to invite members to this group"))
+		return
+	}
+
+	invitation, err := invitationService.Invite(group, currentUserID.(string), inviteeID, email)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			respondError(c, problem.New(problem.CodeUserNotFound, "User not found"))
+		case errors.Is(err, services.ErrAlreadyMember), errors.Is(err, services.ErrInvitationExists):
+			respondError(c, problem.New(problem.CodeConflict, err.Error()))
+		default:
+			respondError(c, problem.Internal("Error creating invitation", err))
+		}
+		return
+	}
+
+	publishInvitationEvent(c, invitation)
+
+	c.JSON(http.StatusCreated, gin.H{"invitation": invitation})
+}
+
+// GetGroupInvitationsHandler obtiene las invitaciones de un grupo
+func (h *InvitationHandler) GetGroupInvitationsHandler(c *gin.Context) {
+	if _, ok := h.loadMemberGroup(c, models.PermViewGroup); !ok {
+		return
+	}
+
+	invitations, err := h.invitationService.ListGroupInvitations(workspaceOf(c), c.Param("id"))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching invitations", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// RevokeInvitationHandler cancela una invitación pendiente. Pueden hacerlo quien
+// invitó y quienes gestionan los miembros del grupo.
+func (h *InvitationHandler) RevokeInvitationHandler(c *gin.Context) {
+	group, ok := h.loadMemberGroup(c, models.PermViewGroup)
+	if !ok {
+		return
+	}
+	currentUserID, _ := c.Get("user_id")
+
+	invitation, err := h.invitationService.GetInvitation(c.Param("invitation_id"))
+	if err != nil || invitation.GroupID != group.ID {
+		if err == nil || errors.Is(err, services.ErrInvitationNotFound) {
+			respondError(c, problem.New(problem.CodeNotFound, "Invitation not found"))
+			return
+		}
+		respondError(c, problem.Internal("Error fetching invitation", err))
+		return
+	}
+
+	if invitation.InviterID != currentUserID.(string) && !group.Can(currentUserID.(string), models.PermManageMembers) {
+		respondError(c, problem.New(problem.CodeForbidden, "You don't have permission to revoke this invitation"))
+		return
+	}
+
+	if err := h.invitationService.RevokeInvitation(invitation.ID); err != nil {
+		if errors.Is(err, services.ErrInvitationClosed) {
+			respondError(c, problem.New(problem.CodeConflict, err.Error()))
+			return
+		}
+		respondError(c, problem.Internal("Error revoking invitation", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": tr(c, "Invitation revoked successfully")})
+}
+
+// GetMyInvitationsHandler obtiene las invitaciones pendientes del usuario
+func (h *InvitationHandler) GetMyInvitationsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	invitations, err := h.invitationService.ListUserInvitations(userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching invitations", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// AcceptInvitationHandler acepta una invitación y añade al usuario al grupo
+func (h *InvitationHandler) AcceptInvitationHandler(c *gin.Context) {
+	h.respond(c, true)
+}
+
+// DeclineInvitationHandler rechaza una invitación
+func (h *InvitationHandler) DeclineInvitationHandler(c *gin.Context) {
+	h.respond(c, false)
+}
+
+func (h *InvitationHandler) respond(c *gin.Context, accept bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	invitation, err := h.invitationService.Respond(c.Param("id"), userID.(string), accept)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvitationNotFound):
+			respondError(c, problem.New(problem.CodeNotFound, "Invitation not found"))
+		case errors.Is(err, services.ErrInvitationClosed):
+			respondError(c, problem.New(problem.CodeConflict, err.Error()))
+		default:
+			respondError(c, problem.Internal("Error responding to invitation", err))
+		}
+		return
+	}
+
+	if accept {
+		publishMemberEvent(c, h.groupService.InWorkspace(workspaceOf(c)), events.GroupMemberAdded, invitation.GroupID, userID.(string))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitation": invitation})
+}
+
+// CreateInviteLinkHandler crea un enlace para unirse al grupo. El token solo se
+// devuelve en esta respuesta.
+func (h *InvitationHandler) CreateInviteLinkHandler(c *gin.Context) {
+	var req InviteLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+
+	group, ok := h.loadMemberGroup(c, models.PermManageMembers)
+	if !ok {
+		return
+	}
+	currentUserID, _ := c.Get("user_id")
+
+	var expiresAt *time.Time
+	if req.ExpiresInHours > 0 {
+		expires := time.Now().Add(time.Duration(req.ExpiresInHours) * time.Hour)
+		expiresAt = &expires
+	}
+
+	token, link, err := h.invitationService.CreateInviteLink(group.ID, currentUserID.(string), req.MaxUses, expiresAt)
+	if err != nil {
+		respondError(c, problem.Internal("Error creating invite link", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"link": link, "token": token, "url": inviteLinkURL(c, token)})
+}
+
+// GetInviteLinksHandler obtiene los enlaces de invitación del grupo
+func (h *InvitationHandler) GetInviteLinksHandler(c *gin.Context) {
+	group, ok := h.loadMemberGroup(c, models.PermManageMembers)
+	if !ok {
+		return
+	}
+
+	links, err := h.invitationService.ListInviteLinks(group.ID)
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching invite links", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"links": links})
+}
+
+// RevokeInviteLinkHandler desactiva un enlace de invitación. Pueden hacerlo quien
+// lo creó y quienes gestionan los miembros del grupo.
+func (h *InvitationHandler) RevokeInviteLinkHandler(c *gin.Context) {
+	group, ok := h.loadMemberGroup(c, models.PermViewGroup)
+	if !ok {
+		return
+	}
+	currentUserID, _ := c.Get("user_id")
+
+	links, err := h.invitationService.ListInviteLinks(group.ID)
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching invite links", err))
+		return
+	}
+
+	var link *models.InviteLink
+	for i := range links {
+		if links[i].ID == c.Param("link_id") {
+			link = &links[i]
+		}
+	}
+	if link == nil {
+		respondError(c, problem.New(problem.CodeNotFound, "Invite link not found"))
+		return
+	}
+	if link.CreatorID != currentUserID.(string) && !group.Can(currentUserID.(string), models.PermManageMembers) {
+		respondError(c, problem.New(problem.CodeForbidden, "You don't have permission to revoke this invite link"))
+		return
+	}
+
+	if err := h.invitationService.RevokeInviteLink(group.ID, link.ID); err != nil {
+		if errors.Is(err, services.ErrInviteLinkNotFound) {
+			respondError(c, problem.New(problem.CodeNotFound, "Invite link not found"))
+			return
+		}
+		respondError(c, problem.Internal("Error revoking invite link", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": tr(c, "Invite link revoked successfully")})
+}
+
+// GetInviteLinkHandler muestra a qué grupo invita un enlace antes de unirse
+func (h *InvitationHandler) GetInviteLinkHandler(c *gin.Context) {
+	link, err := h.invitationService.GetInviteLink(c.Param("token"))
+	if err != nil {
+		if errors.Is(err, services.ErrInviteLinkNotFound) {
+			respondError(c, problem.New(problem.CodeNotFound, "Invite link not found"))
+			return
+		}
+		respondError(c, problem.Internal("Error fetching invite link", err))
+		return
+	}
+
+	group, err := h.groupService.InWorkspace(workspaceOf(c)).GetGroupByID(link.GroupID)
+	if err != nil {
+		if errors.Is(err, services.ErrGroupNotFound) {
+			respondError(c, problem.New(problem.CodeNotFound, "Invite link not found"))
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"group_id":    group.ID,
+		"group_name":  group.Name,
+		"description": group.Description,
+		"valid":       link.Usable(time.Now()),
+		"expires_at":  link.ExpiresAt,
+	})
+}
+
+// JoinWithInviteLinkHandler añade al usuario al grupo del enlace
+func (h *InvitationHandler) JoinWithInviteLinkHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	link, err := h.invitationService.JoinWithLink(c.Param("token"), userID.(string))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInviteLinkNotFound):
+			respondError(c, problem.New(problem.CodeNotFound, "Invite link not found"))
+		case errors.Is(err, services.ErrInviteLinkUnusable):
+			respondError(c, problem.New(problem.CodeGone, err.Error()))
+		case errors.Is(err, services.ErrAlreadyMember):
+			respondError(c, problem.New(problem.CodeConflict, err.Error()))
+		default:
+			respondError(c, problem.Internal("Error joining group", err))
+		}
+		return
+	}
+
+	publishMemberEvent(c, h.groupService.InWorkspace(workspaceOf(c)), events.GroupMemberAdded, link.GroupID, userID.(string))
+
+	c.JSON(http.StatusOK, gin.H{"message": tr(c, "Joined group successfully"), "group_id": link.GroupID})
+}
+
+// loadMemberGroup obtiene el grupo de la ruta y verifica que el rol del usuario
+// tenga el permiso indicado
+func (h *InvitationHandler) loadMemberGroup(c *gin.Context, permission models.GroupPermission) (*models.Group, bool) {
+	currentUserID, _ := c.Get("user_id")
+
+	group, err := h.groupService.InWorkspace(workspaceOf(c)).GetGroupByID(c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return nil, false
+	}
+
+	if !group.Can(currentUserID.(string), permission) {
+		if permission == models.PermViewGroup {
+			respondError(c, problem.New(problem.CodeForbidden, "You are not a member of this group"))
+		} else {
+			respondError(c, problem.New(problem.CodeForbidden, "You don't have permission to manage the members of this group"))
+		}
+		return nil, false
+	}
+
+	return group, true
+}
+
+// inviteLinkURL construye la URL del enlace de invitación a partir de la petición actual
+func inviteLinkURL(c *gin.Context, token string) string {
+	return requestBaseURL(c) + "/api/invite-links/" + token
+}
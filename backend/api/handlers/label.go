@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"task-manager-backend/api/problem"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LabelRequest struct {
+	Name    string  `json:"name" binding:"required"`
+	Color   string  `json:"color" binding:"required"`
+	GroupID *string `json:"group_id,omitempty"` // Grupo de la etiqueta (opcional, solo al crear)
+}
+
+type LabelHandler struct {
+	labelService *services.LabelService
+	groupService *services.GroupService
+}
+
+func NewLabelHandler() *LabelHandler {
+	return &LabelHandler{
+		labelService: services.NewLabelService(),
+		groupService: services.NewGroupService(),
+	}
+}
+
+// canManageLabels verifica si el usuario puede gestionar las etiquetas de un ámbito:
+// las personales su propietario y las de grupo quien tenga el permiso en él
+func (h *LabelHandler) canManageLabels(workspaceID, userID, ownerID string, groupID *string, permission models.GroupPermission) (bool, error) {
+	if groupID == nil || *groupID == "" {
+		return ownerID == userID, nil
+	}
+	group, err := h.groupService.InWorkspace(workspaceID).GetGroupByID(*groupID)
+	if err != nil {
+		return false, err
+	}
+	return group.Can(userID, permission), nil
+}
+
+// GetLabelsHandler obtiene las etiquetas personales o las de un grupo (?group_id=)
+func (h *LabelHandler) GetLabelsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	var groupID *string
+	if id := c.Query("group_id"); id != "" {
+		groupID = &id
+	}
+
+	allowed, err := h.canManageLabels(workspaceOf(c), userID.(string), userID.(string), groupID, models.PermViewGroup)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !allowed {
+		respondError(c, problem.New(problem.CodeForbidden, "You are not a member of this group"))
+		return
+	}
+
+	labels, err := h.labelService.ListLabels(userID.(string), groupID)
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching labels", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"labels": labels})
+}
+
+// CreateLabelHandler crea una etiqueta personal o de grupo
+func (h *LabelHandler) CreateLabelHandler(c *gin.Context) {
+	var req LabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	label := models.Label{
+		Name:    req.Name,
+		Color:   req.Color,
+		OwnerID: userID.(string),
+		GroupID: req.GroupID,
+	}
+
+	if !label.Validate() {
+		respondError(c, problem.New(problem.CodeBadRequest, "Invalid label data"))
+		return
+	}
+
+	allowed, err := h.canManageLabels(workspaceOf(c), userID.(string), label.OwnerID, label.GroupID, models.PermCreateTasks)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !allowed {
+		respondError(c, problem.New(problem.CodeForbidden, "You don't have permission to create labels in this group"))
+		return
+	}
+
+	if err := h.labelService.CreateLabel(&label); err != nil {
+		respondLabelError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"label": label})
+}
+
+// UpdateLabelHandler renombra o cambia el color de una etiqueta
+func (h *LabelHandler) UpdateLabelHandler(c *gin.Context) {
+	var req LabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	label, err := h.labelService.GetLabel(c.Param("id"))
+	if err != nil {
+		respondLabelError(c, err)
+		return
+	}
+
+	allowed, err := h.canManageLabels(workspaceOf(c), userID.(string), label.OwnerID, label.GroupID, models.PermCreateTasks)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !allowed {
+		respondError(c, problem.New(problem.CodeForbidden, "You don't have permission to modify this label"))
+		return
+	}
+
+	label.Name = req.Name
+	label.Color = req.Color
+	if !label.Validate() {
+		respondError(c, problem.New(problem.CodeBadRequest, "Invalid label data"))
+		return
+	}
+
+	if err := h.labelService.UpdateLabel(label); err != nil {
+		respondLabelError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"label": label})
+}
+
+// DeleteLabelHandler elimina una etiqueta y la quita de las tareas
+func (h *LabelHandler) DeleteLabelHandler(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	label, err := h.labelService.GetLabel(c.Param("id"))
+	if err != nil {
+		respondLabelError(c, err)
+		return
+	}
+
+	allowed, err := h.canManageLabels(workspaceOf(c), userID.(string), label.OwnerID, label.GroupID, models.PermCreateTasks)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !allowed {
+		respondError(c, problem.New(problem.CodeForbidden, "You don't have permission to delete this label"))
+		return
+	}
+
+	if err := h.labelService.DeleteLabel(label.ID); err != nil {
+		respondLabelError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": tr(c, "Label deleted successfully")})
+}
+
+// respondLabelError traduce los errores del servicio de etiquetas a respuestas HTTP
+func respondLabelError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrLabelNotFound):
+		respondError(c, problem.New(problem.CodeNotFound, "Label not found"))
+	case errors.Is(err, services.ErrLabelExists):
+		respondError(c, problem.New(problem.CodeConflict, err.Error()))
+	case errors.Is(err, services.ErrLabelScope):
+		respondError(c, problem.New(problem.CodeBadRequest, err.Error()))
+	default:
+		respondError(c, problem.Internal("Error managing labels", err))
+	}
+}
@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"task-manager-backend/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// localeOf devuelve el idioma de la petición (ver middleware.Locale)
+func localeOf(c *gin.Context) i18n.Locale {
+	return i18n.Of(c.GetString(i18n.ContextKey))
+}
+
+// tr traduce un mensaje al idioma de la petición (ver i18n.T)
+func tr(c *gin.Context, key string, args ...interface{}) string {
+	return i18n.T(localeOf(c), key, args...)
+}
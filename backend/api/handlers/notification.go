@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"task-manager-backend/api/problem"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationPreferencesRequest struct {
+	Types map[string]bool `json:"types" binding:"required"` // Tipo de notificación -> activado
+}
+
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+func NewNotificationHandler() *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: services.NewNotificationService(),
+	}
+}
+
+// GetNotificationsHandler obtiene las notificaciones del usuario (?unread=true para solo las no leídas)
+func (h *NotificationHandler) GetNotificationsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	notifications, err := h.notificationService.ListNotifications(userID.(string), c.Query("unread") == "true")
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching notifications", err))
+		return
+	}
+
+	unread, err := h.notificationService.UnreadCount(userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error counting unread notifications", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notifications": notifications, "unread_count": unread})
+}
+
+// GetUnreadCountHandler obtiene el número de notificaciones sin leer
+func (h *NotificationHandler) GetUnreadCountHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	unread, err := h.notificationService.UnreadCount(userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error counting unread notifications", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread_count": unread})
+}
+
+// MarkReadHandler marca una notificación como leída
+func (h *NotificationHandler) MarkReadHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	notification, err := h.notificationService.MarkRead(userID.(string), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrNotificationNotFound) {
+			respondError(c, problem.New(problem.CodeNotFound, "Notification not found"))
+			return
+		}
+		respondError(c, problem.Internal("Error updating notification", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notification": notification})
+}
+
+// MarkAllReadHandler marca como leídas todas las notificaciones del usuario
+func (h *NotificationHandler) MarkAllReadHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	updated, err := h.notificationService.MarkAllRead(userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error updating notifications", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+// GetPreferencesHandler obtiene las preferencias de notificación del usuario
+func (h *NotificationHandler) GetPreferencesHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	preferences, err := h.notificationService.GetPreferences(userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching notification preferences", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": preferencesView(preferences), "types": models.NotificationTypes})
+}
+
+// UpdatePreferencesHandler activa o desactiva tipos de notificación. Los tipos que
+// no se envían conservan su valor.
+func (h *NotificationHandler) UpdatePreferencesHandler(c *gin.Context) {
+	var req NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	for notificationType := range req.Types {
+		if !models.IsNotificationType(notificationType) {
+			respondError(c, problem.Newf(problem.CodeBadRequest, "Unknown notification type: %s", notificationType))
+			return
+		}
+	}
+
+	preferences, err := h.notificationService.GetPreferences(userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching notification preferences", err))
+		return
+	}
+	for notificationType, enabled := range req.Types {
+		preferences.Types[notificationType] = enabled
+	}
+
+	if err := h.notificationService.UpdatePreferences(preferences); err != nil {
+		respondError(c, problem.Internal("Error saving notification preferences", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": preferencesView(preferences), "types": models.NotificationTypes})
+}
+
+// preferencesView devuelve las preferencias con todos los tipos explícitos
+func preferencesView(preferences *models.NotificationPreferences) *models.NotificationPreferences {
+	view := *preferences
+	view.Types = make(map[string]bool, len(models.NotificationTypes))
+	for _, notificationType := range models.NotificationTypes {
+		view.Types[notificationType] = preferences.Enabled(notificationType)
+	}
+	return &view
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"task-manager-backend/internal/events"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// publishTaskEvent publica un cambio de una tarea para los usuarios que pueden verla.
+// En las actualizaciones, previous es el estado anterior: quienes la veían antes
+// también reciben el evento para dejar de mostrarla si perdieron el acceso.
+func publishTaskEvent(c *gin.Context, eventType string, task *models.Task, previous *models.Task) {
+	snapshot := *task
+	payload := events.TaskPayload{Task: &snapshot}
+	audience := taskAudience(task)
+	if previous != nil {
+		previousSnapshot := *previous
+		payload.Previous = &previousSnapshot
+		payload.PreviousStatus = previous.Status
+		audience = uniqueStrings(append(audience, taskAudience(previous)...))
+	}
+
+	events.Publish(events.Event{
+		Type:     eventType,
+		Data:     payload,
+		Audience: audience,
+		ActorID:  c.GetString("user_id"),
+	})
+}
+
+// publishGroupEvent publica un cambio de un grupo para sus miembros. memberID es el
+// miembro añadido o eliminado, que recibe el evento aunque ya no pertenezca al grupo.
+func publishGroupEvent(c *gin.Context, eventType string, group *models.Group, memberID string) {
+	snapshot := *group
+	audience := append([]string{group.CreatorID}, group.AllMembers()...)
+	if memberID != "" {
+		audience = append(audience, memberID)
+	}
+
+	events.Publish(events.Event{
+		Type:     eventType,
+		Data:     events.GroupPayload{Group: &snapshot, MemberID: memberID},
+		Audience: uniqueStrings(audience),
+		ActorID:  c.GetString("user_id"),
+	})
+}
+
+// publishInvitationEvent publica una invitación para quien invita y, si está
+// registrado, para el invitado
+func publishInvitationEvent(c *gin.Context, invitation *models.GroupInvitation) {
+	snapshot := *invitation
+	audience := []string{invitation.InviterID}
+	if invitation.InviteeID != nil {
+		audience = append(audience, *invitation.InviteeID)
+	}
+
+	events.Publish(events.Event{
+		Type:     events.GroupInvitationCreated,
+		Data:     events.InvitationPayload{Invitation: &snapshot},
+		Audience: uniqueStrings(audience),
+		ActorID:  c.GetString("user_id"),
+	})
+}
+
+// taskAudience devuelve los usuarios que pueden ver una tarea (ver accessToTask):
+// los miembros de su grupo, incluidos los heredados, o, si es personal, su propietario y sus colaboradores
+func taskAudience(task *models.Task) []string {
+	if task.GroupID != nil && *task.GroupID != "" {
+		if group, err := services.NewGroupService().GetGroupByID(*task.GroupID); err == nil {
+			return uniqueStrings(group.AllMembers())
+		}
+	}
+	return uniqueStrings(append([]string{task.UserID}, task.ArrCollaborators...))
+}
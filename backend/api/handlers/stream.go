@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"task-manager-backend/api/problem"
+	"task-manager-backend/internal/events"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// heartbeatInterval es el intervalo entre los comentarios que mantienen viva la conexión
+const heartbeatInterval = 15 * time.Second
+
+// streamRetry es el tiempo, en milisegundos, que el cliente espera antes de reconectar
+const streamRetry = 3000
+
+var (
+	// streamsClosed se cierra al apagar el servidor para terminar los streams abiertos
+	streamsClosed    = make(chan struct{})
+	closeStreamsOnce sync.Once
+)
+
+// CloseStreams termina todos los streams abiertos. Se registra con
+// http.Server.RegisterOnShutdown para que el apagado no espere a los clientes.
+func CloseStreams() {
+	closeStreamsOnce.Do(func() { close(streamsClosed) })
+}
+
+// StreamEvents envía mediante Server-Sent Events los cambios de las tareas y grupos
+// que el usuario puede ver. Cada evento lleva su ID; al reconectar, el navegador envía
+// Last-Event-ID (o el cliente ?last_event_id) y se reenvían los eventos perdidos.
+// Si ya no se conservan, se envía un evento "reset" para que el cliente recargue su estado.
+func StreamEvents(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+	uid, _ := userID.(string)
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	match := func(e events.Event) bool { return e.VisibleTo(uid) }
+
+	reset := false
+	sub, err := events.Subscribe(lastEventID, match)
+	if errors.Is(err, events.ErrHistoryUnavailable) {
+		reset = true
+		sub, err = events.Subscribe("", match)
+	}
+	if err != nil {
+		respondError(c, problem.Internal("Error opening event stream", err))
+		return
+	}
+	defer sub.Close()
+
+	// El stream no tiene duración máxima: se anula el WriteTimeout del servidor
+	rc := http.NewResponseController(c.Writer)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Error disabling write deadline for event stream: %v", err)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Evita que un proxy nginx acumule la respuesta
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry)
+	if reset {
+		writeSSE(w, "", "reset", gin.H{"reason": tr(c, "stream.reset")})
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-streamsClosed:
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				// El cliente se quedó atrás; al reconectar recupera los eventos desde el último recibido
+				return
+			}
+			if err := writeSSE(w, event.ID, event.Type, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeSSE escribe un evento con el formato de Server-Sent Events
+func writeSSE(w io.Writer, id, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error encoding stream event: %v", err)
+		return nil
+	}
+
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, payload)
+	return err
+}
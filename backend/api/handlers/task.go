@@ -3,10 +3,18 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"log"
 	"net/http"
+	"sort"
+	"task-manager-backend/api/problem"
 	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/events"
+	"task-manager-backend/internal/jsonpatch"
 	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
 	"time"
 
 	"cloud.google.com/go/firestore"
@@ -26,18 +34,61 @@ type CreateTaskRequest struct {
 	GroupID          *string       `json:"group_id,omitempty"`          // ID del grupo (opcional)
 	AssignedTo       *string       `json:"assigned_to,omitempty"`       // ID del usuario asignado (opcional)
 	ArrCollaborators []string      `json:"arr_collaborators,omitempty"` // IDs de colaboradores (opcional)
+	Priority         string        `json:"priority,omitempty"`          // low, medium, high o urgent (opcional)
+	LabelIDs         []string      `json:"label_ids,omitempty"`         // IDs de etiquetas (opcional)
+	EstimatedEffort  time.Duration `json:"estimated_effort,omitempty"`  // Esfuerzo estimado en nanosegundos (opcional)
+	DueDate          *time.Time    `json:"due_date,omitempty"`          // Fecha de vencimiento (opcional)
+	ParentID         *string       `json:"parent_id,omitempty"`         // Tarea padre si es una subtarea (opcional)
+	Recurrence       string        `json:"recurrence,omitempty"`        // Regla RRULE (opcional)
 }
 
 type UpdateTaskRequest struct {
-	Title            string        `json:"title"`
-	Description      string        `json:"description"`
-	Status           string        `json:"status"`
-	TimeUntilFinish  time.Duration `json:"time_until_finish"`
-	RemindMe         bool          `json:"remind_me"`
-	Category         string        `json:"category"`
-	GroupID          *string       `json:"group_id,omitempty"`          // ID del grupo (opcional)
-	AssignedTo       *string       `json:"assigned_to,omitempty"`       // ID del usuario asignado (opcional)
-	ArrCollaborators []string      `json:"arr_collaborators,omitempty"` // IDs de colaboradores (opcional)
+	Title            string         `json:"title"`
+	Description      string         `json:"description"`
+	Status           string         `json:"status"`
+	TimeUntilFinish  time.Duration  `json:"time_until_finish"`
+	RemindMe         *bool          `json:"remind_me,omitempty"` // nil = no se modifica
+	Category         string         `json:"category"`
+	GroupID          *string        `json:"group_id,omitempty"`          // ID del grupo (opcional)
+	AssignedTo       *string        `json:"assigned_to,omitempty"`       // ID del usuario asignado (opcional)
+	ArrCollaborators []string       `json:"arr_collaborators,omitempty"` // IDs de colaboradores (opcional)
+	Priority         string         `json:"priority,omitempty"`          // low, medium, high o urgent (opcional)
+	LabelIDs         []string       `json:"label_ids,omitempty"`         // IDs de etiquetas (nil = no se modifica)
+	EstimatedEffort  *time.Duration `json:"estimated_effort,omitempty"`  // Esfuerzo estimado (nil = no se modifica)
+	DueDate          *time.Time     `json:"due_date,omitempty"`          // Fecha de vencimiento (nil = no se modifica)
+	Recurrence       *string        `json:"recurrence,omitempty"`        // Regla RRULE, "" para quitarla (nil = no se modifica)
+}
+
+// Campos de la tarea que el propietario puede modificar mediante PATCH
+var ownerEditableTaskFields = map[string]bool{
+	"title":             true,
+	"description":       true,
+	"status":            true,
+	"time_until_finish": true,
+	"remind_me":         true,
+	"category":          true,
+	"group_id":          true,
+	"assigned_to":       true,
+	"arr_collaborators": true,
+	"priority":          true,
+	"label_ids":         true,
+	"estimated_effort":  true,
+	"due_date":          true,
+	"recurrence":        true,
+}
+
+// Campos de la tarea que un colaborador puede modificar mediante PATCH
+var collaboratorEditableTaskFields = map[string]bool{
+	"title":             true,
+	"description":       true,
+	"status":            true,
+	"time_until_finish": true,
+	"category":          true,
+	"priority":          true,
+	"label_ids":         true,
+	"estimated_effort":  true,
+	"due_date":          true,
+	"recurrence":        true,
 }
 
 type GetTaskRequest struct {
@@ -47,7 +98,7 @@ type GetTaskRequest struct {
 func CreateTask(c *gin.Context) {
 	var req CreateTaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
 		return
 	}
 
@@ -55,7 +106,7 @@ func CreateTask(c *gin.Context) {
 
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
 		return
 	}
 
@@ -74,85 +125,134 @@ func CreateTask(c *gin.Context) {
 		GroupID:          req.GroupID,          // ID del grupo (puede ser nil)
 		AssignedTo:       req.AssignedTo,       // ID del usuario asignado (puede ser nil)
 		ArrCollaborators: req.ArrCollaborators, // IDs de colaboradores
+		Priority:         req.Priority,
+		LabelIDs:         req.LabelIDs,
+		EstimatedEffort:  req.EstimatedEffort,
+		DueDate:          req.DueDate,
+		ParentID:         req.ParentID,
+		Recurrence:       req.Recurrence,
+		WorkspaceID:      workspaceOf(c),
+		Version:          1,
 	}
+	task.TrackAssignment(nil, task.CreatedAt)
 
-	// Validate task before saving
-	if !task.Validate() {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task data"})
+	ctx := context.Background()
+
+	if req.ParentID != nil {
+		// Solo se pueden crear subtareas de tareas accesibles
+		if _, err := loadAccessibleTask(ctx, task.WorkspaceID, *req.ParentID, userID.(string)); err != nil {
+			respondError(c, problem.Internal("Error fetching parent task", err))
+			return
+		}
+	}
+
+	if err := prepareNewTask(ctx, &task); err != nil {
+		respondError(c, problem.Internal("Error creating task", err))
 		return
 	}
 
-	ctx := context.Background()
 	_, err := database.Client.Collection("tasks").Doc(task.ID).Set(ctx, task)
 
 	if err != nil {
-		log.Printf("Error creating task: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creating task"})
+		respondError(c, problem.Internal("Error creating task", err))
 		return
 	}
 
+	publishTaskEvent(c, events.TaskCreated, &task, nil)
+
 	c.JSON(http.StatusCreated, gin.H{"task": task})
 }
 
 func GetUserTasks(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
 		return
 	}
 
-	ctx := context.Background()
-	tasksRef := database.Client.Collection("tasks")
+	uniqueTasks, err := fetchVisibleTasks(context.Background(), workspaceOf(c), userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching tasks", err))
+		return
+	}
+
+	// Aplicar filtros y orden solicitados
+	uniqueTasks, err = filterAndSortTasks(uniqueTasks, c.Request.URL.Query())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tasks": uniqueTasks})
+}
+
+// fetchVisibleTasks obtiene las tareas del espacio de trabajo que el usuario puede
+// ver (ver accessToTask): las personales de las que es propietario o colaborador y
+// las de sus grupos
+func fetchVisibleTasks(ctx context.Context, workspaceID, userID string) ([]models.Task, error) {
+	tasksRef := database.InWorkspace(database.Client.Collection("tasks").Query, workspaceID)
 
 	// Consulta para obtener las tareas donde el usuario es el propietario
-	queryOwner := tasksRef.Where("user_id", "==", userID.(string))
+	queryOwner := tasksRef.Where("user_id", "==", userID)
 
 	// Consulta para obtener las tareas donde el usuario es un colaborador
-	queryCollaborator := tasksRef.Where("arr_collaborators", "array-contains", userID.(string))
+	queryCollaborator := tasksRef.Where("arr_collaborators", "array-contains", userID)
 
-	// Ejecutar ambas consultas en paralelo
-	docsOwner, errOwner := queryOwner.Documents(ctx).GetAll()
-	docsCollaborator, errCollaborator := queryCollaborator.Documents(ctx).GetAll()
-
-	// Manejar errores
-	if errOwner != nil {
-		log.Printf("Error fetching tasks where user is owner: %v", errOwner)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching tasks where user is owner"})
-		return
+	docsOwner, err := queryOwner.Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error fetching tasks where user is owner: %v", err)
+		return nil, err
 	}
-	if errCollaborator != nil {
-		log.Printf("Error fetching tasks where user is collaborator: %v", errCollaborator)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching tasks where user is collaborator"})
-		return
+	docsCollaborator, err := queryCollaborator.Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error fetching tasks where user is collaborator: %v", err)
+		return nil, err
 	}
 
-	// Combinar los resultados de ambas consultas
-	var tasks []models.Task
+	// Tareas de los grupos del usuario
+	groups, err := services.NewGroupService().InWorkspace(workspaceID).GetUserGroups(userID)
+	if err != nil {
+		return nil, err
+	}
+	memberOf := make(map[string]bool, len(groups))
+	groupIDs := make([]string, 0, len(groups))
+	for _, group := range groups {
+		memberOf[group.ID] = true
+		groupIDs = append(groupIDs, group.ID)
+	}
 
-	// Procesar tareas donde el usuario es el propietario
-	for _, doc := range docsOwner {
-		var task models.Task
-		if err := doc.DataTo(&task); err != nil {
-			log.Printf("Error converting document to task: %v", err)
-			continue
+	var docsGroups []*firestore.DocumentSnapshot
+	// Firestore admite como máximo 30 valores en una consulta "in"
+	for start := 0; start < len(groupIDs); start += 30 {
+		end := start + 30
+		if end > len(groupIDs) {
+			end = len(groupIDs)
 		}
-		tasks = append(tasks, task)
+		docs, err := tasksRef.Where("group_id", "in", groupIDs[start:end]).Documents(ctx).GetAll()
+		if err != nil {
+			log.Printf("Error fetching tasks of user groups: %v", err)
+			return nil, err
+		}
+		docsGroups = append(docsGroups, docs...)
 	}
 
-	// Procesar tareas donde el usuario es un colaborador
-	for _, doc := range docsCollaborator {
+	// Combinar los resultados de las consultas
+	var tasks []models.Task
+	for _, doc := range append(append(docsOwner, docsCollaborator...), docsGroups...) {
 		var task models.Task
 		if err := doc.DataTo(&task); err != nil {
 			log.Printf("Error converting document to task: %v", err)
 			continue
 		}
+		// Las tareas de los grupos que el usuario dejó ya no son visibles
+		if task.GroupID != nil && *task.GroupID != "" && !memberOf[*task.GroupID] {
+			continue
+		}
 		tasks = append(tasks, task)
 	}
 
 	// Eliminar duplicados (en caso de que el usuario sea tanto el propietario como un colaborador)
-	uniqueTasks := removeDuplicateTasks(tasks)
-
-	c.JSON(http.StatusOK, gin.H{"tasks": uniqueTasks})
+	return removeDuplicateTasks(tasks), nil
 }
 
 // GetTaskByID obtiene una tarea por su ID para el usuario actual
@@ -160,38 +260,317 @@ func GetTaskByID(c *gin.Context) {
 	taskID := c.Param("id")
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
 		return
 	}
 
 	ctx := context.Background()
 
 	// Obtener tarea por ID y verificar si el usuario es el propietario o un colaborador
-	docRef := database.Client.Collection("tasks").Doc(taskID)
-	doc, err := docRef.Get(ctx)
+	task, err := loadAccessibleTask(ctx, workspaceOf(c), taskID, userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching task by ID", err))
+		return
+	}
+
+	if notModified(c, task.Version) {
+		return
+	}
+
+	setETag(c, task.Version)
+	c.JSON(http.StatusOK, gin.H{"task": task})
+}
+
+// prepareNewTask valida una tarea nueva contra el flujo de trabajo y los miembros
+// de su grupo, resuelve sus etiquetas y la coloca al final de su columna del tablero
+func prepareNewTask(ctx context.Context, task *models.Task) error {
+	group, err := loadTaskGroup(ctx, nil, task.WorkspaceID, task.GroupID)
+	if err != nil {
+		return err
+	}
+	if group != nil && !group.Can(task.UserID, models.PermCreateTasks) {
+		return errGroupRole
+	}
+	if err := checkTaskMembers(ctx, task, group); err != nil {
+		return err
+	}
+
+	if err := resolveLabels(task); err != nil {
+		return err
+	}
+
+	// Validate task before saving
+	if !task.Validate(taskWorkflow(group)) {
+		return problem.New(problem.CodeBadRequest, "Invalid task data")
+	}
+
+	// Colocar la tarea al final de su columna del tablero
+	if task.Rank, err = appendRank(ctx, task); err != nil {
+		// Sin posición la tarea se muestra al final de la columna
+		log.Printf("Error computing task rank: %v", err)
+	}
+
+	return nil
+}
+
+// workflowFor devuelve el flujo de trabajo que aplica a una tarea: el de su grupo
+// o el flujo por defecto si es una tarea personal. tx puede ser nil.
+func workflowFor(ctx context.Context, tx *firestore.Transaction, workspaceID string, groupID *string) (*models.Workflow, error) {
+	group, err := loadTaskGroup(ctx, tx, workspaceID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return taskWorkflow(group), nil
+}
+
+// taskWorkflow devuelve el flujo de trabajo de las tareas del grupo, o el flujo
+// por defecto si group es nil
+func taskWorkflow(group *models.Group) *models.Workflow {
+	if group == nil {
+		return models.DefaultWorkflow()
+	}
+	return group.TaskWorkflow()
+}
+
+// loadTaskGroup obtiene el grupo de una tarea del espacio de trabajo, o nil si es
+// una tarea personal. Los grupos de otros espacios de trabajo no existen para ella.
+// tx puede ser nil.
+func loadTaskGroup(ctx context.Context, tx *firestore.Transaction, workspaceID string, groupID *string) (*models.Group, error) {
+	if groupID == nil || *groupID == "" {
+		return nil, nil
+	}
+
+	groupRef := database.Client.Collection("groups").Doc(*groupID)
+
+	var doc *firestore.DocumentSnapshot
+	var err error
+	if tx != nil {
+		doc, err = tx.Get(groupRef)
+	} else {
+		doc, err = groupRef.Get(ctx)
+	}
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
-		} else {
-			log.Printf("Error fetching task by ID: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching task by ID"})
+			return nil, problem.New(problem.CodeBadRequest, "Group not found")
 		}
-		return
+		return nil, err
+	}
+
+	var group models.Group
+	if err := doc.DataTo(&group); err != nil {
+		return nil, err
+	}
+	if !models.SameWorkspace(group.WorkspaceID, workspaceID) {
+		return nil, problem.New(problem.CodeBadRequest, "Group not found")
+	}
+	if err := services.InheritRoles(ctx, tx, &group); err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// errGroupRole es el error de las acciones que el rol del usuario en el grupo no permite
+var errGroupRole = problem.New(problem.CodeForbidden, "Your role in this group does not allow this action")
+
+// authorizeGroupTask verifica que el rol del usuario en el grupo de una tarea le dé
+// el permiso indicado. Las tareas personales no dependen de ningún rol. tx puede ser nil.
+func authorizeGroupTask(ctx context.Context, tx *firestore.Transaction, task *models.Task, userID string, permission models.GroupPermission) error {
+	group, err := loadTaskGroup(ctx, tx, task.WorkspaceID, task.GroupID)
+	if err != nil {
+		return err
+	}
+
+	if group != nil && !group.Can(userID, permission) {
+		return errGroupRole
+	}
+	return nil
+}
+
+// authorizeTaskDeletion verifica que el usuario pueda eliminar la tarea: las personales
+// solo su propietario y las de grupo su propietario, si su rol lo permite, o quien
+// pueda eliminar cualquier tarea del grupo. tx puede ser nil.
+func authorizeTaskDeletion(ctx context.Context, tx *firestore.Transaction, task *models.Task, userID string) error {
+	group, err := loadTaskGroup(ctx, tx, task.WorkspaceID, task.GroupID)
+	if err != nil {
+		return err
+	}
+
+	if !canDeleteTask(task, group, userID) {
+		return problem.New(problem.CodeForbidden, "Unauthorized to delete this task")
+	}
+	return nil
+}
+
+// canDeleteTask indica si el usuario puede eliminar la tarea (ver authorizeTaskDeletion)
+func canDeleteTask(task *models.Task, group *models.Group, userID string) bool {
+	if group == nil {
+		return task.UserID == userID
+	}
+	return group.Can(userID, models.PermDeleteAnyTask) || (task.UserID == userID && group.Can(userID, models.PermDeleteOwnTasks))
+}
+
+// checkTaskMembers verifica que el asignado y los colaboradores de una tarea de
+// grupo sean miembros del grupo, y los de una tarea personal usuarios de su espacio
+// de trabajo. group es nil en las tareas personales.
+func checkTaskMembers(ctx context.Context, task *models.Task, group *models.Group) error {
+	if group == nil {
+		return checkWorkspaceUsers(ctx, task)
+	}
+	if task.AssignedTo != nil && *task.AssignedTo != "" && group.RoleOf(*task.AssignedTo) == "" {
+		return problem.New(problem.CodeBadRequest, "The assigned user is not a member of the group")
+	}
+	for _, collaboratorID := range task.ArrCollaborators {
+		if group.RoleOf(collaboratorID) == "" {
+			return problem.New(problem.CodeBadRequest, "Collaborators must be members of the group")
+		}
+	}
+	return nil
+}
+
+// checkWorkspaceUsers verifica que el asignado y los colaboradores de una tarea
+// personal sean usuarios de su espacio de trabajo
+func checkWorkspaceUsers(ctx context.Context, task *models.Task) error {
+	userIDs := append([]string(nil), task.ArrCollaborators...)
+	if task.AssignedTo != nil && *task.AssignedTo != "" {
+		userIDs = append(userIDs, *task.AssignedTo)
+	}
+	userIDs = uniqueStrings(userIDs)
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	workspaces, err := services.UserWorkspaces(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+	for _, id := range userIDs {
+		workspaceID, ok := workspaces[id]
+		if !ok || !models.SameWorkspace(workspaceID, task.WorkspaceID) {
+			return problem.New(problem.CodeBadRequest, "The assigned user and collaborators must belong to the workspace")
+		}
+	}
+	return nil
+}
+
+// checkWorkflow valida una tarea modificada contra el flujo de trabajo de su grupo,
+// incluyendo la transición desde su estado anterior
+func checkWorkflow(ctx context.Context, tx *firestore.Transaction, task *models.Task, previousStatus string, previousGroupID *string) error {
+	workflow, err := workflowFor(ctx, tx, task.WorkspaceID, task.GroupID)
+	if err != nil {
+		return err
+	}
+
+	// Al mover la tarea a otro grupo solo se exige que el estado exista en el nuevo flujo
+	if sameGroup(task.GroupID, previousGroupID) && !workflow.CanTransition(previousStatus, task.Status) {
+		return problem.Newf(problem.CodeInvalidTransition, "Transition from %s to %s is not allowed", previousStatus, task.Status)
+	}
+
+	// Validar la tarea actualizada
+	if !task.Validate(workflow) {
+		return problem.New(problem.CodeBadRequest, "Invalid task data")
+	}
+
+	return nil
+}
+
+// resolveLabels valida las etiquetas de una tarea y actualiza la copia guardada en ella
+func resolveLabels(task *models.Task) error {
+	if err := services.NewLabelService().ResolveTaskLabels(task); err != nil {
+		if errors.Is(err, services.ErrLabelNotFound) || errors.Is(err, services.ErrLabelScope) {
+			return problem.New(problem.CodeBadRequest, err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// sameGroup compara dos IDs de grupo opcionales
+func sameGroup(a, b *string) bool {
+	if a == nil || b == nil {
+		return (a == nil || *a == "") && (b == nil || *b == "")
+	}
+	return *a == *b
+}
+
+// loadAccessibleTask obtiene una tarea del espacio de trabajo y verifica que el
+// usuario pueda acceder a ella
+func loadAccessibleTask(ctx context.Context, workspaceID, taskID, userID string) (*models.Task, error) {
+	doc, err := database.Client.Collection("tasks").Doc(taskID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, problem.New(problem.CodeTaskNotFound, "Task not found")
+		}
+		return nil, err
 	}
 
 	var task models.Task
-	if err := doc.DataTo(&task); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parsing task data"})
-		return
+	if err := decodeTask(doc, workspaceID, &task); err != nil {
+		return nil, err
 	}
 
-	// Verificar si el usuario es propietario o colaborador
-	if task.UserID != userID.(string) && !contains(task.ArrCollaborators, userID.(string)) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "User is not authorized to access this task"})
-		return
+	group, err := loadTaskGroup(ctx, nil, task.WorkspaceID, task.GroupID)
+	if err != nil {
+		return nil, err
 	}
 
-	c.JSON(http.StatusOK, gin.H{"task": task})
+	if accessToTask(&task, group, userID) == taskAccessNone {
+		return nil, problem.New(problem.CodeForbidden, "User is not authorized to access this task")
+	}
+
+	return &task, nil
+}
+
+// decodeTask convierte el documento en una tarea. Las tareas de otros espacios de
+// trabajo se tratan como si no existieran.
+func decodeTask(doc *firestore.DocumentSnapshot, workspaceID string, task *models.Task) error {
+	if err := doc.DataTo(task); err != nil {
+		return err
+	}
+	if !models.SameWorkspace(task.WorkspaceID, workspaceID) {
+		return problem.New(problem.CodeTaskNotFound, "Task not found")
+	}
+	return nil
+}
+
+// taskAccess es lo que un usuario puede hacer con una tarea
+type taskAccess int
+
+const (
+	taskAccessNone        taskAccess = iota
+	taskAccessView                   // Solo ver la tarea
+	taskAccessCollaborate            // Modificar los campos de colaborador
+	taskAccessFull                   // Modificar todos los campos, como el propietario
+)
+
+// accessToTask decide el acceso del usuario a una tarea. En las personales depende
+// de ser su propietario o colaborador. En las de grupo depende del rol: los
+// observadores solo ven las tareas, los miembros colaboran en todas y tienen acceso
+// completo a las suyas, y los administradores y el propietario tienen acceso completo
+// a todas. Quien no es miembro no accede a las tareas del grupo. group es el grupo
+// de la tarea, o nil si es personal.
+func accessToTask(task *models.Task, group *models.Group, userID string) taskAccess {
+	if group == nil {
+		switch {
+		case task.UserID == userID:
+			return taskAccessFull
+		case contains(task.ArrCollaborators, userID):
+			return taskAccessCollaborate
+		default:
+			return taskAccessNone
+		}
+	}
+
+	switch {
+	case !group.Can(userID, models.PermViewGroup):
+		return taskAccessNone
+	case !group.Can(userID, models.PermEditTasks):
+		return taskAccessView
+	case task.UserID == userID || group.Can(userID, models.PermManageTasks):
+		return taskAccessFull
+	default:
+		return taskAccessCollaborate
+	}
 }
 
 // Función para eliminar tareas duplicadas
@@ -223,165 +602,458 @@ func UpdateTask(c *gin.Context) {
 	taskID := c.Param("id")
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
 		return
 	}
 
 	var req UpdateTaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
 		return
 	}
 
 	ctx := context.Background()
 	taskRef := database.Client.Collection("tasks").Doc(taskID)
+	check := ifMatch(c)
 
-	// Obtener la tarea existente
-	doc, err := taskRef.Get(ctx)
-	if err != nil {
-		if status.Code(err) == codes.NotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
-			return
+	var existingTask, previousTask models.Task
+
+	// Leer, fusionar y escribir dentro de una transacción para que dos
+	// colaboradores no se sobrescriban entre sí
+	err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		// Obtener la tarea existente
+		doc, err := tx.Get(taskRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return problem.New(problem.CodeTaskNotFound, "Task not found")
+			}
+			return err
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching task"})
+
+		existingTask = models.Task{}
+		if err := decodeTask(doc, workspaceOf(c), &existingTask); err != nil {
+			return err
+		}
+		previousTask = existingTask
+		previousTask.ArrCollaborators = append([]string(nil), existingTask.ArrCollaborators...)
+
+		// Verificar si el usuario tiene acceso de propietario o de colaborador
+		group, err := loadTaskGroup(ctx, tx, existingTask.WorkspaceID, existingTask.GroupID)
+		if err != nil {
+			return err
+		}
+		access := accessToTask(&existingTask, group, userID.(string))
+		isOwner := access == taskAccessFull
+		isCollaborator := access == taskAccessCollaborate
+
+		if !isOwner && !isCollaborator {
+			return problem.New(problem.CodeForbidden, "Unauthorized to modify this task")
+		}
+
+		if check != nil && !check(existingTask.Version) {
+			return errPreconditionFailed
+		}
+
+		previousStatus := existingTask.Status
+		previousGroupID := existingTask.GroupID
+
+		// Definir los campos que se pueden actualizar
+		var firestoreUpdates []firestore.Update
+		existingTask.UpdatedAt = time.Now()
+		existingTask.Version++
+		firestoreUpdates = append(firestoreUpdates,
+			firestore.Update{Path: "updated_at", Value: existingTask.UpdatedAt},
+			firestore.Update{Path: "version", Value: existingTask.Version},
+		)
+
+		if isOwner {
+			// El propietario puede modificar todos los campos
+			if req.Title != "" {
+				firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "title", Value: req.Title})
+				existingTask.Title = req.Title
+			}
+			if req.Description != "" {
+				firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "description", Value: req.Description})
+				existingTask.Description = req.Description
+			}
+			if req.Status != "" {
+				firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "status", Value: req.Status})
+				existingTask.Status = req.Status
+			}
+			if req.TimeUntilFinish != 0 {
+				firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "time_until_finish", Value: req.TimeUntilFinish})
+				existingTask.TimeUntilFinish = req.TimeUntilFinish
+			}
+			if req.RemindMe != nil {
+				firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "remind_me", Value: *req.RemindMe})
+				existingTask.RemindMe = *req.RemindMe
+			}
+			if req.Category != "" {
+				firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "category", Value: req.Category})
+				existingTask.Category = req.Category
+			}
+			if req.GroupID != nil {
+				firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "group_id", Value: req.GroupID})
+				existingTask.GroupID = req.GroupID
+			}
+			if req.AssignedTo != nil {
+				previousAssignee := existingTask.AssignedTo
+				existingTask.AssignedTo = req.AssignedTo
+				existingTask.TrackAssignment(previousAssignee, time.Now())
+				firestoreUpdates = append(firestoreUpdates,
+					firestore.Update{Path: "assigned_to", Value: req.AssignedTo},
+					firestore.Update{Path: "assigned_at", Value: existingTask.AssignedAt},
+				)
+			}
+			if req.ArrCollaborators != nil {
+				firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "arr_collaborators", Value: req.ArrCollaborators})
+				existingTask.ArrCollaborators = req.ArrCollaborators
+			}
+		} else if isCollaborator {
+			// El colaborador solo puede modificar ciertos campos
+			if req.Title != "" {
+				firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "title", Value: req.Title})
+				existingTask.Title = req.Title
+			}
+			if req.Description != "" {
+				firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "description", Value: req.Description})
+				existingTask.Description = req.Description
+			}
+			if req.Status != "" {
+				firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "status", Value: req.Status})
+				existingTask.Status = req.Status
+			}
+			if req.TimeUntilFinish != 0 {
+				firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "time_until_finish", Value: req.TimeUntilFinish})
+				existingTask.TimeUntilFinish = req.TimeUntilFinish
+			}
+			if req.Category != "" {
+				firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "category", Value: req.Category})
+				existingTask.Category = req.Category
+			}
+		}
+
+		// Propietario y colaboradores pueden cambiar la prioridad, la estimación y las etiquetas
+		if req.Priority != "" {
+			firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "priority", Value: req.Priority})
+			existingTask.Priority = req.Priority
+		}
+		if req.EstimatedEffort != nil {
+			firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "estimated_effort", Value: *req.EstimatedEffort})
+			existingTask.EstimatedEffort = *req.EstimatedEffort
+		}
+		if req.DueDate != nil {
+			firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "due_date", Value: *req.DueDate})
+			existingTask.DueDate = req.DueDate
+		}
+		if req.Recurrence != nil {
+			firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "recurrence", Value: *req.Recurrence})
+			existingTask.Recurrence = *req.Recurrence
+		}
+		if req.LabelIDs != nil || !sameGroup(existingTask.GroupID, previousGroupID) {
+			if req.LabelIDs != nil {
+				existingTask.LabelIDs = req.LabelIDs
+			}
+			if err := resolveLabels(&existingTask); err != nil {
+				return err
+			}
+			firestoreUpdates = append(firestoreUpdates,
+				firestore.Update{Path: "label_ids", Value: existingTask.LabelIDs},
+				firestore.Update{Path: "labels", Value: existingTask.Labels},
+			)
+		}
+
+		// Mover la tarea a otro grupo equivale a crearla en él
+		if !sameGroup(existingTask.GroupID, previousGroupID) {
+			if group, err = loadTaskGroup(ctx, tx, existingTask.WorkspaceID, existingTask.GroupID); err != nil {
+				return err
+			}
+			if group != nil && !group.Can(userID.(string), models.PermCreateTasks) {
+				return errGroupRole
+			}
+		}
+		if req.AssignedTo != nil || req.ArrCollaborators != nil || !sameGroup(existingTask.GroupID, previousGroupID) {
+			if err := checkTaskMembers(ctx, &existingTask, group); err != nil {
+				return err
+			}
+		}
+
+		if err := checkWorkflow(ctx, tx, &existingTask, previousStatus, previousGroupID); err != nil {
+			return err
+		}
+
+		// Actualizar la tarea en Firestore
+		return tx.Update(taskRef, firestoreUpdates)
+	})
+	if err != nil {
+		respondError(c, problem.Internal("Error updating task", err))
 		return
 	}
 
-	var existingTask models.Task
-	if err := doc.DataTo(&existingTask); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parsing task data"})
+	publishTaskEvent(c, events.TaskUpdated, &existingTask, &previousTask)
+
+	setETag(c, existingTask.Version)
+	c.JSON(http.StatusOK, gin.H{"message": tr(c, "Task updated successfully"), "task": existingTask})
+}
+
+// PatchTask aplica una actualización parcial a una tarea. Acepta JSON Merge Patch
+// (application/merge-patch+json o application/json) y JSON Patch (application/json-patch+json).
+// Un valor null en un merge patch elimina el campo (por ejemplo, para desasignar la tarea).
+func PatchTask(c *gin.Context) {
+	taskID := c.Param("id")
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
 		return
 	}
 
-	// Verificar si el usuario es el propietario o un colaborador
-	isOwner := existingTask.UserID == userID.(string)
-	isCollaborator := contains(existingTask.ArrCollaborators, userID.(string))
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondError(c, problem.New(problem.CodeBadRequest, "Error reading request body"))
+		return
+	}
 
-	if !isOwner && !isCollaborator {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to modify this task"})
+	applyPatch, err := parseTaskPatch(c.ContentType(), body)
+	if err != nil {
+		respondError(c, problem.Internal("Invalid patch document", err))
 		return
 	}
 
-	// Definir los campos que se pueden actualizar
-	var firestoreUpdates []firestore.Update
-	firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "updated_at", Value: time.Now()})
+	ctx := context.Background()
+	taskRef := database.Client.Collection("tasks").Doc(taskID)
+	check := ifMatch(c)
+
+	var patchedTask, previousTask models.Task
 
-	if isOwner {
-		// El propietario puede modificar todos los campos
-		if req.Title != "" {
-			firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "title", Value: req.Title})
-			existingTask.Title = req.Title
+	err = database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(taskRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return problem.New(problem.CodeTaskNotFound, "Task not found")
+			}
+			return err
 		}
-		if req.Description != "" {
-			firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "description", Value: req.Description})
-			existingTask.Description = req.Description
+
+		var existingTask models.Task
+		if err := decodeTask(doc, workspaceOf(c), &existingTask); err != nil {
+			return err
 		}
-		if req.Status != "" {
-			firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "status", Value: req.Status})
-			existingTask.Status = req.Status
+		previousTask = existingTask
+
+		// Verificar si el usuario tiene acceso de propietario o de colaborador
+		group, err := loadTaskGroup(ctx, tx, existingTask.WorkspaceID, existingTask.GroupID)
+		if err != nil {
+			return err
 		}
-		if req.TimeUntilFinish != 0 {
-			firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "time_until_finish", Value: req.TimeUntilFinish})
-			existingTask.TimeUntilFinish = req.TimeUntilFinish
+		access := accessToTask(&existingTask, group, userID.(string))
+		isOwner := access == taskAccessFull
+		isCollaborator := access == taskAccessCollaborate
+
+		if !isOwner && !isCollaborator {
+			return problem.New(problem.CodeForbidden, "Unauthorized to modify this task")
 		}
-		firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "remind_me", Value: req.RemindMe})
-		existingTask.RemindMe = req.RemindMe
-		if req.Category != "" {
-			firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "category", Value: req.Category})
-			existingTask.Category = req.Category
+
+		if check != nil && !check(existingTask.Version) {
+			return errPreconditionFailed
 		}
-		if req.GroupID != nil {
-			firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "group_id", Value: req.GroupID})
-			existingTask.GroupID = req.GroupID
+
+		current, err := toJSONDocument(existingTask)
+		if err != nil {
+			return err
 		}
-		if req.AssignedTo != nil {
-			firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "assigned_to", Value: req.AssignedTo})
-			existingTask.AssignedTo = req.AssignedTo
+
+		patched, err := applyPatch(current)
+		if err != nil {
+			if errors.Is(err, jsonpatch.ErrTestFailed) {
+				return problem.New(problem.CodeConflict, err.Error())
+			}
+			return problem.New(problem.CodeUnprocessable, err.Error())
 		}
-		if req.ArrCollaborators != nil {
-			firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "arr_collaborators", Value: req.ArrCollaborators})
-			existingTask.ArrCollaborators = req.ArrCollaborators
+
+		patchedDoc, ok := patched.(map[string]interface{})
+		if !ok {
+			return problem.New(problem.CodeUnprocessable, "Patched task must be a JSON object")
 		}
-	} else if isCollaborator {
-		// El colaborador solo puede modificar ciertos campos
-		if req.Title != "" {
-			firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "title", Value: req.Title})
-			existingTask.Title = req.Title
+
+		// Verificar los permisos campo por campo
+		changed := changedFields(current, patchedDoc)
+		for _, field := range changed {
+			if !ownerEditableTaskFields[field] {
+				return problem.Newf(problem.CodeUnprocessable, "Field %s cannot be modified", field)
+			}
+			if !isOwner && !collaboratorEditableTaskFields[field] {
+				return problem.Newf(problem.CodeForbidden, "Collaborators cannot modify field %s", field)
+			}
 		}
-		if req.Description != "" {
-			firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "description", Value: req.Description})
-			existingTask.Description = req.Description
+
+		patchedTask = models.Task{}
+		if err := fromJSONDocument(patchedDoc, &patchedTask); err != nil {
+			return problem.Newf(problem.CodeUnprocessable, "Invalid value in patched task: %v", err)
+		}
+
+		if len(changed) == 0 {
+			return nil
 		}
-		if req.Status != "" {
-			firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "status", Value: req.Status})
-			existingTask.Status = req.Status
+
+		patchedTask.UpdatedAt = time.Now()
+		patchedTask.Version = existingTask.Version + 1
+		patchedTask.TrackAssignment(existingTask.AssignedTo, patchedTask.UpdatedAt)
+
+		// La copia de las etiquetas se recalcula a partir de label_ids
+		if !sameGroup(patchedTask.GroupID, existingTask.GroupID) || contains(changed, "label_ids") {
+			if err := resolveLabels(&patchedTask); err != nil {
+				return err
+			}
 		}
-		if req.TimeUntilFinish != 0 {
-			firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "time_until_finish", Value: req.TimeUntilFinish})
-			existingTask.TimeUntilFinish = req.TimeUntilFinish
+
+		// Mover la tarea a otro grupo equivale a crearla en él
+		if !sameGroup(patchedTask.GroupID, existingTask.GroupID) {
+			if group, err = loadTaskGroup(ctx, tx, patchedTask.WorkspaceID, patchedTask.GroupID); err != nil {
+				return err
+			}
+			if group != nil && !group.Can(userID.(string), models.PermCreateTasks) {
+				return errGroupRole
+			}
 		}
-		if req.Category != "" {
-			firestoreUpdates = append(firestoreUpdates, firestore.Update{Path: "category", Value: req.Category})
-			existingTask.Category = req.Category
+		if contains(changed, "assigned_to") || contains(changed, "arr_collaborators") || contains(changed, "group_id") {
+			if err := checkTaskMembers(ctx, &patchedTask, group); err != nil {
+				return err
+			}
 		}
-	}
 
-	// Validar la tarea actualizada
-	if !existingTask.Validate() {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task data"})
+		if err := checkWorkflow(ctx, tx, &patchedTask, existingTask.Status, existingTask.GroupID); err != nil {
+			return err
+		}
+
+		// Se reescribe el documento completo para que los campos eliminados desaparezcan
+		return tx.Set(taskRef, patchedTask)
+	})
+	if err != nil {
+		respondError(c, problem.Internal("Error updating task", err))
 		return
 	}
 
-	// Actualizar la tarea en Firestore
-	_, err = taskRef.Update(ctx, firestoreUpdates)
+	// Un parche sin cambios no incrementa la versión ni genera evento
+	if patchedTask.Version != previousTask.Version {
+		publishTaskEvent(c, events.TaskUpdated, &patchedTask, &previousTask)
+	}
+
+	setETag(c, patchedTask.Version)
+	c.JSON(http.StatusOK, gin.H{"message": tr(c, "Task updated successfully"), "task": patchedTask})
+}
+
+// parseTaskPatch interpreta el cuerpo de un PATCH según su Content-Type
+func parseTaskPatch(contentType string, body []byte) (func(interface{}) (interface{}, error), error) {
+	switch contentType {
+	case "application/json-patch+json":
+		var ops []jsonpatch.Operation
+		if err := json.Unmarshal(body, &ops); err != nil {
+			return nil, problem.New(problem.CodeBadRequest, "Invalid JSON Patch document")
+		}
+		return func(doc interface{}) (interface{}, error) {
+			return jsonpatch.Apply(doc, ops)
+		}, nil
+	case "application/merge-patch+json", "application/json", "":
+		var patch interface{}
+		if err := jsonpatch.Unmarshal(body, &patch); err != nil {
+			return nil, problem.New(problem.CodeBadRequest, "Invalid JSON Merge Patch document")
+		}
+		if _, ok := patch.(map[string]interface{}); !ok {
+			return nil, problem.New(problem.CodeBadRequest, "Merge patch must be a JSON object")
+		}
+		return func(doc interface{}) (interface{}, error) {
+			return jsonpatch.MergePatch(doc, patch), nil
+		}, nil
+	default:
+		return nil, problem.Newf(problem.CodeUnsupportedMedia, "Unsupported patch format %s", contentType)
+	}
+}
+
+// toJSONDocument convierte un valor a su representación JSON genérica. Los números
+// quedan como json.Number para que los time.Duration vuelvan exactos en fromJSONDocument.
+func toJSONDocument(v interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(v)
 	if err != nil {
-		log.Printf("Error updating task: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating task"})
-		return
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := jsonpatch.Unmarshal(raw, &doc); err != nil {
+		return nil, err
 	}
+	return doc, nil
+}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Task updated successfully", "task": existingTask})
+// fromJSONDocument decodifica una representación JSON genérica en un valor tipado
+func fromJSONDocument(doc map[string]interface{}, v interface{}) error {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// changedFields devuelve los campos de primer nivel que difieren entre dos documentos
+func changedFields(before, after map[string]interface{}) []string {
+	var fields []string
+	for key, value := range after {
+		if old, ok := before[key]; !ok || !jsonpatch.Equal(old, value) {
+			fields = append(fields, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+	return fields
 }
 
 func DeleteTask(c *gin.Context) {
 	taskID := c.Param("id")
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
 		return
 	}
 
 	ctx := context.Background()
 	taskRef := database.Client.Collection("tasks").Doc(taskID)
+	check := ifMatch(c)
 
-	// Verify task exists and belongs to user
-	doc, err := taskRef.Get(ctx)
-	if err != nil {
-		if status.Code(err) == codes.NotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
-			return
+	var task models.Task
+
+	err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		// Verify task exists and belongs to user
+		doc, err := tx.Get(taskRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return problem.New(problem.CodeTaskNotFound, "Task not found")
+			}
+			return err
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching task"})
-		return
-	}
 
-	var task models.Task
-	if err := doc.DataTo(&task); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error parsing task data"})
-		return
-	}
+		task = models.Task{}
+		if err := decodeTask(doc, workspaceOf(c), &task); err != nil {
+			return err
+		}
 
-	if task.UserID != userID.(string) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to delete this task"})
-		return
-	}
+		if err := authorizeTaskDeletion(ctx, tx, &task, userID.(string)); err != nil {
+			return err
+		}
+
+		if check != nil && !check(task.Version) {
+			return errPreconditionFailed
+		}
 
-	_, err = taskRef.Delete(ctx)
+		return tx.Delete(taskRef)
+	})
 	if err != nil {
-		log.Printf("Error deleting task: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting task"})
+		respondError(c, problem.Internal("Error deleting task", err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
+	publishTaskEvent(c, events.TaskDeleted, &task, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": tr(c, "Task deleted successfully")})
 }
@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"task-manager-backend/api/problem"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/events"
+	"task-manager-backend/internal/i18n"
+	"task-manager-backend/internal/models"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxBulkTasks limita el número de tareas de una operación masiva
+const maxBulkTasks = 500
+
+// Acciones admitidas por POST /api/tasks/bulk
+const (
+	BulkActionStatus = "status"
+	BulkActionLabels = "labels"
+	BulkActionAssign = "assign"
+	BulkActionDelete = "delete"
+)
+
+type BulkTaskRequest struct {
+	TaskIDs      []string `json:"task_ids" binding:"required"`
+	Action       string   `json:"action" binding:"required"` // status, labels, assign o delete
+	Status       string   `json:"status,omitempty"`          // Nuevo estado (action=status)
+	AddLabels    []string `json:"add_labels,omitempty"`      // Etiquetas a añadir (action=labels)
+	RemoveLabels []string `json:"remove_labels,omitempty"`   // Etiquetas a quitar (action=labels)
+	AssignedTo   *string  `json:"assigned_to,omitempty"`     // Usuario asignado, null para desasignar (action=assign)
+}
+
+// BulkTaskResult es el resultado de la operación sobre una tarea
+type BulkTaskResult struct {
+	TaskID  string       `json:"task_id"`
+	Success bool         `json:"success"`
+	Status  int          `json:"status"`
+	Code    problem.Code `json:"code,omitempty"` // Código estable del error (ver problem.Code)
+	Error   string       `json:"error,omitempty"`
+}
+
+// BulkUpdateTasks aplica un cambio de estado, de etiquetas, de asignación o un borrado
+// a varias tareas. Cada tarea se autoriza por separado y obtiene su propio resultado.
+func BulkUpdateTasks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	var req BulkTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+
+	switch req.Action {
+	case BulkActionStatus:
+		if req.Status == "" {
+			respondError(c, problem.New(problem.CodeBadRequest, "status is required for the status action"))
+			return
+		}
+	case BulkActionLabels:
+		if len(req.AddLabels) == 0 && len(req.RemoveLabels) == 0 {
+			respondError(c, problem.New(problem.CodeBadRequest, "add_labels or remove_labels is required for the labels action"))
+			return
+		}
+	case BulkActionAssign, BulkActionDelete:
+	default:
+		respondError(c, problem.New(problem.CodeBadRequest, "action must be one of status, labels, assign or delete"))
+		return
+	}
+
+	taskIDs := uniqueStrings(req.TaskIDs)
+	if len(taskIDs) == 0 || len(taskIDs) > maxBulkTasks {
+		respondError(c, problem.New(problem.CodeBadRequest, "task_ids must contain between 1 and 500 IDs"))
+		return
+	}
+
+	ctx := context.Background()
+	tasksRef := database.Client.Collection("tasks")
+
+	refs := make([]*firestore.DocumentRef, len(taskIDs))
+	for i, id := range taskIDs {
+		refs[i] = tasksRef.Doc(id)
+	}
+
+	// Leer todas las tareas en una sola llamada
+	docs, err := database.Client.GetAll(ctx, refs)
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching tasks", err))
+		return
+	}
+
+	results := make([]BulkTaskResult, len(taskIDs))
+	groups := make(map[string]*models.Group)
+
+	writer := database.Client.BulkWriter(ctx)
+	jobs := make(map[int]*firestore.BulkWriterJob)
+	changes := make(map[int]bulkChange)
+
+	for i, doc := range docs {
+		results[i] = BulkTaskResult{TaskID: taskIDs[i]}
+
+		change, err := planBulkChange(ctx, doc, workspaceOf(c), userID.(string), req, groups)
+		if err != nil {
+			results[i].fail(localeOf(c), err)
+			continue
+		}
+
+		// Las escrituras solo se aplican si la tarea no cambió desde que se leyó
+		precondition := firestore.LastUpdateTime(doc.UpdateTime)
+
+		var job *firestore.BulkWriterJob
+		if req.Action == BulkActionDelete {
+			job, err = writer.Delete(doc.Ref, precondition)
+		} else {
+			job, err = writer.Update(doc.Ref, change.updates, precondition)
+		}
+		if err != nil {
+			results[i].fail(localeOf(c), err)
+			continue
+		}
+		jobs[i] = job
+		changes[i] = change
+	}
+
+	writer.End()
+
+	succeeded := 0
+	for i := range results {
+		job, queued := jobs[i]
+		if !queued {
+			continue
+		}
+		if _, err := job.Results(); err != nil {
+			if status.Code(err) == codes.FailedPrecondition {
+				results[i].fail(localeOf(c), errPreconditionFailed)
+			} else {
+				log.Printf("Error writing task %s in bulk operation: %v", results[i].TaskID, err)
+				results[i].fail(localeOf(c), err)
+			}
+			continue
+		}
+		results[i].Success = true
+		results[i].Status = http.StatusOK
+		succeeded++
+
+		change := changes[i]
+		if req.Action == BulkActionDelete {
+			publishTaskEvent(c, events.TaskDeleted, &change.previous, nil)
+		} else {
+			publishTaskEvent(c, events.TaskUpdated, &change.task, &change.previous)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results":   results,
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+	})
+}
+
+// bulkChange es el cambio planificado sobre una tarea
+type bulkChange struct {
+	updates  []firestore.Update
+	task     models.Task // Tarea tras el cambio
+	previous models.Task // Tarea antes del cambio
+}
+
+// planBulkChange autoriza y valida el cambio sobre una tarea y devuelve las actualizaciones a escribir
+func planBulkChange(ctx context.Context, doc *firestore.DocumentSnapshot, workspaceID, userID string, req BulkTaskRequest, groups map[string]*models.Group) (bulkChange, error) {
+	if !doc.Exists() {
+		return bulkChange{}, problem.New(problem.CodeTaskNotFound, "Task not found")
+	}
+
+	var task models.Task
+	if err := decodeTask(doc, workspaceID, &task); err != nil {
+		return bulkChange{}, err
+	}
+	change := bulkChange{previous: task}
+
+	// Los grupos se guardan en groups para no leer el mismo varias veces
+	var group *models.Group
+	if task.GroupID != nil && *task.GroupID != "" {
+		var ok bool
+		if group, ok = groups[*task.GroupID]; !ok {
+			var err error
+			if group, err = loadTaskGroup(ctx, nil, task.WorkspaceID, task.GroupID); err != nil {
+				return bulkChange{}, err
+			}
+			groups[*task.GroupID] = group
+		}
+	}
+	workflow := taskWorkflow(group)
+	access := accessToTask(&task, group, userID)
+
+	// Mismas reglas que en los endpoints de una sola tarea
+	switch req.Action {
+	case BulkActionDelete:
+		if !canDeleteTask(&task, group, userID) {
+			return bulkChange{}, problem.New(problem.CodeForbidden, "Unauthorized to delete this task")
+		}
+		return change, nil
+	case BulkActionAssign:
+		if access != taskAccessFull {
+			return bulkChange{}, problem.New(problem.CodeForbidden, "Only the owner can reassign this task")
+		}
+	default:
+		if access < taskAccessCollaborate {
+			return bulkChange{}, problem.New(problem.CodeForbidden, "Unauthorized to modify this task")
+		}
+	}
+
+	now := time.Now()
+	updates := []firestore.Update{
+		{Path: "updated_at", Value: now},
+		{Path: "version", Value: task.Version + 1},
+	}
+
+	switch req.Action {
+	case BulkActionStatus:
+		if !workflow.CanTransition(task.Status, req.Status) {
+			return bulkChange{}, problem.Newf(problem.CodeInvalidTransition, "Transition from %s to %s is not allowed", task.Status, req.Status)
+		}
+		task.Status = req.Status
+		updates = append(updates, firestore.Update{Path: "status", Value: task.Status})
+	case BulkActionLabels:
+		var labelIDs []string
+		for _, id := range task.LabelIDs {
+			if !contains(req.RemoveLabels, id) {
+				labelIDs = append(labelIDs, id)
+			}
+		}
+		for _, id := range req.AddLabels {
+			if !contains(labelIDs, id) {
+				labelIDs = append(labelIDs, id)
+			}
+		}
+		task.LabelIDs = labelIDs
+		if err := resolveLabels(&task); err != nil {
+			return bulkChange{}, err
+		}
+		updates = append(updates,
+			firestore.Update{Path: "label_ids", Value: task.LabelIDs},
+			firestore.Update{Path: "labels", Value: task.Labels},
+		)
+	case BulkActionAssign:
+		task.AssignedTo = req.AssignedTo
+		if err := checkTaskMembers(ctx, &task, group); err != nil {
+			return bulkChange{}, err
+		}
+		task.TrackAssignment(change.previous.AssignedTo, now)
+		if req.AssignedTo == nil {
+			updates = append(updates,
+				firestore.Update{Path: "assigned_to", Value: firestore.Delete},
+				firestore.Update{Path: "assigned_at", Value: firestore.Delete},
+			)
+		} else {
+			updates = append(updates,
+				firestore.Update{Path: "assigned_to", Value: *req.AssignedTo},
+				firestore.Update{Path: "assigned_at", Value: task.AssignedAt},
+			)
+		}
+	}
+
+	if !task.Validate(workflow) {
+		return bulkChange{}, problem.New(problem.CodeBadRequest, "Invalid task data")
+	}
+
+	task.UpdatedAt = now
+	task.Version++
+	change.task = task
+	change.updates = updates
+	return change, nil
+}
+
+// fail registra el error de una tarea en su resultado, traducido a locale. Los
+// errores internos se registran en el log y el resultado solo indica que la
+// tarea no se actualizó.
+func (r *BulkTaskResult) fail(locale i18n.Locale, err error) {
+	apiErr := apiError(err)
+	r.Success = false
+	r.Status = apiErr.Status()
+	r.Code = apiErr.Code
+	r.Error = apiErr.Message(locale)
+	if apiErr.Code == problem.CodeInternal {
+		log.Printf("Error updating task %s: %v", r.TaskID, err)
+		r.Error = i18n.T(locale, "Error updating task")
+	}
+}
+
+// uniqueStrings elimina los elementos vacíos y duplicados conservando el orden
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		unique = append(unique, v)
+	}
+	return unique
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"task-manager-backend/api/problem"
+	"task-manager-backend/internal/models"
+)
+
+// filterAndSortTasks aplica los filtros y el orden indicados en la query string:
+//
+//	?label=<id>            (repetible; la tarea debe tener todas las etiquetas)
+//	?priority=high,urgent  (cualquiera de las prioridades indicadas)
+//	?status=pending,...    (cualquiera de los estados indicados)
+//	?sort=priority|-priority|created_at|-created_at|updated_at|-updated_at|title|-title|rank
+func filterAndSortTasks(tasks []models.Task, query url.Values) ([]models.Task, error) {
+	labels := query["label"]
+	priorities := splitList(query.Get("priority"))
+	statuses := splitList(query.Get("status"))
+
+	for _, p := range priorities {
+		if models.PriorityLevel(p) == 0 {
+			return nil, problem.Newf(problem.CodeBadRequest, "invalid priority filter: %s", p)
+		}
+	}
+
+	filtered := make([]models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if len(priorities) > 0 && !contains(priorities, task.Priority) {
+			continue
+		}
+		if len(statuses) > 0 && !contains(statuses, task.Status) {
+			continue
+		}
+		if !hasAllLabels(task, labels) {
+			continue
+		}
+		filtered = append(filtered, task)
+	}
+
+	sortKey := query.Get("sort")
+	if sortKey == "" {
+		return filtered, nil
+	}
+
+	desc := strings.HasPrefix(sortKey, "-")
+	field := strings.TrimPrefix(sortKey, "-")
+
+	var less func(a, b models.Task) bool
+	switch field {
+	case "priority":
+		less = func(a, b models.Task) bool {
+			return models.PriorityLevel(a.Priority) < models.PriorityLevel(b.Priority)
+		}
+	case "created_at":
+		less = func(a, b models.Task) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	case "updated_at":
+		less = func(a, b models.Task) bool { return a.UpdatedAt.Before(b.UpdatedAt) }
+	case "title":
+		less = func(a, b models.Task) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) }
+	case "rank":
+		models.SortByRank(filtered)
+		return filtered, nil
+	default:
+		return nil, problem.Newf(problem.CodeBadRequest, "invalid sort field: %s", field)
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if desc {
+			return less(filtered[j], filtered[i])
+		}
+		return less(filtered[i], filtered[j])
+	})
+
+	return filtered, nil
+}
+
+// hasAllLabels indica si la tarea tiene todas las etiquetas indicadas
+func hasAllLabels(task models.Task, labelIDs []string) bool {
+	for _, id := range labelIDs {
+		if !contains(task.LabelIDs, id) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitList divide una lista separada por comas ignorando los elementos vacíos
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,741 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"task-manager-backend/api/problem"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/events"
+	"task-manager-backend/internal/i18n"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/rank"
+	"task-manager-backend/internal/services"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxImportRows limita el número de filas de una importación
+const maxImportRows = 1000
+
+// taskCSVHeader son las columnas del formato CSV de exportación e importación
+var taskCSVHeader = []string{
+	"id", "title", "description", "status", "category", "priority", "labels",
+	"assigned_to", "collaborators", "due_date", "estimated_effort",
+	"time_until_finish", "remind_me", "recurrence", "parent_id", "created_at",
+}
+
+// TaskRecord es la representación portable de una tarea. Los usuarios se
+// identifican por email y las etiquetas por nombre para poder mover tareas
+// entre cuentas y herramientas.
+type TaskRecord struct {
+	ID              string     `json:"id,omitempty"` // ID de origen, para enlazar subtareas
+	Title           string     `json:"title"`
+	Description     string     `json:"description"`
+	Status          string     `json:"status,omitempty"`
+	Category        string     `json:"category"`
+	Priority        string     `json:"priority,omitempty"`
+	Labels          []string   `json:"labels,omitempty"`           // Nombres de etiqueta
+	AssignedTo      string     `json:"assigned_to,omitempty"`      // Email del usuario asignado
+	Collaborators   []string   `json:"collaborators,omitempty"`    // Emails de los colaboradores
+	DueDate         *time.Time `json:"due_date,omitempty"`         // RFC 3339
+	EstimatedEffort string     `json:"estimated_effort,omitempty"` // Duración, p. ej. "1h30m"
+	TimeUntilFinish string     `json:"time_until_finish,omitempty"`
+	RemindMe        bool       `json:"remind_me"`
+	Recurrence      string     `json:"recurrence,omitempty"` // Regla RRULE
+	ParentID        string     `json:"parent_id,omitempty"`  // ID de origen de la tarea padre
+	CreatedAt       *time.Time `json:"created_at,omitempty"`
+}
+
+// ImportRowResult es el resultado de la importación de una fila
+type ImportRowResult struct {
+	Row    int      `json:"row"`               // Número de fila, empezando en 1
+	ID     string   `json:"id,omitempty"`      // ID de origen de la fila
+	TaskID string   `json:"task_id,omitempty"` // ID de la tarea creada (o que se crearía)
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ExportTasks exporta las tareas visibles del usuario, o las de un grupo, en JSON o CSV.
+// Admite los mismos filtros que GET /api/tasks.
+//
+//	?format=json|csv&group_id=...
+func ExportTasks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		respondError(c, problem.New(problem.CodeBadRequest, "format must be json or csv"))
+		return
+	}
+
+	ctx := context.Background()
+
+	groupID, _, err := transferScope(c, userID.(string), models.PermViewGroup)
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching group", err))
+		return
+	}
+
+	var tasks []models.Task
+	if groupID != nil {
+		tasks, err = fetchGroupTasks(ctx, workspaceOf(c), *groupID)
+	} else {
+		tasks, err = fetchVisibleTasks(ctx, workspaceOf(c), userID.(string))
+	}
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching tasks", err))
+		return
+	}
+
+	tasks, err = filterAndSortTasks(tasks, c.Request.URL.Query())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Traducir los IDs de usuario a emails
+	var userIDs []string
+	for _, task := range tasks {
+		if task.AssignedTo != nil {
+			userIDs = append(userIDs, *task.AssignedTo)
+		}
+		userIDs = append(userIDs, task.ArrCollaborators...)
+	}
+	emails, err := emailsByUserID(ctx, workspaceOf(c), userIDs)
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching users", err))
+		return
+	}
+
+	records := make([]TaskRecord, 0, len(tasks))
+	for _, task := range tasks {
+		records = append(records, toTaskRecord(task, emails))
+	}
+
+	filename := fmt.Sprintf("tasks-%s.%s", time.Now().Format("20060102"), format)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	if format == "json" {
+		c.JSON(http.StatusOK, gin.H{"tasks": records})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	w.Write(taskCSVHeader)
+	for _, record := range records {
+		w.Write(record.csvRow())
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Printf("Error writing CSV export: %v", err)
+	}
+}
+
+// ImportTasks crea tareas a partir de un documento JSON ({"tasks": [...]}) o CSV
+// (Content-Type: text/csv) con el formato de la exportación. Cada fila se valida
+// por separado; las filas válidas se importan y las demás se devuelven con sus errores.
+// Con ?dry_run=true solo se valida.
+//
+//	?group_id=...&dry_run=true
+func ImportTasks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+
+	records, err := parseImport(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if len(records) == 0 || len(records) > maxImportRows {
+		respondError(c, problem.New(problem.CodeBadRequest, "The import must contain between 1 and 1000 tasks"))
+		return
+	}
+
+	ctx := context.Background()
+
+	groupID, group, err := transferScope(c, userID.(string), models.PermCreateTasks)
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching group", err))
+		return
+	}
+	workflow := taskWorkflow(group)
+
+	// Resolver emails y nombres de etiqueta con una consulta por tipo
+	var emails []string
+	for _, record := range records {
+		if record.AssignedTo != "" {
+			emails = append(emails, record.AssignedTo)
+		}
+		emails = append(emails, record.Collaborators...)
+	}
+	userIDs, err := userIDsByEmail(ctx, workspaceOf(c), emails)
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching users", err))
+		return
+	}
+
+	labels, err := services.NewLabelService().ListLabels(userID.(string), groupID)
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching labels", err))
+		return
+	}
+	labelsByName := make(map[string]models.Label)
+	for _, label := range labels {
+		labelsByName[strings.ToLower(label.Name)] = label
+	}
+
+	// Asignar los IDs nuevos antes de validar para poder enlazar subtareas
+	newIDs := make(map[string]string)
+	for _, record := range records {
+		if record.ID != "" {
+			newIDs[record.ID] = uuid.New().String()
+		}
+	}
+
+	now := time.Now()
+	results := make([]ImportRowResult, len(records))
+	tasks := make([]models.Task, len(records))
+	seenIDs := make(map[string]bool)
+
+	for i, record := range records {
+		result := ImportRowResult{Row: i + 1, ID: record.ID}
+		result.TaskID = newIDs[record.ID]
+		if result.TaskID == "" || seenIDs[record.ID] {
+			result.TaskID = uuid.New().String()
+		}
+
+		task, errs := buildImportedTask(localeOf(c), record, userID.(string), groupID, workflow, userIDs, labelsByName, newIDs)
+		if record.ID != "" {
+			if seenIDs[record.ID] {
+				errs = append(errs, tr(c, "duplicate id: %s", record.ID))
+			}
+			seenIDs[record.ID] = true
+		}
+		task.ID = result.TaskID
+		task.WorkspaceID = workspaceOf(c)
+		task.CreatedAt = now
+		task.UpdatedAt = now
+		task.TrackAssignment(nil, now)
+
+		// Los emails ya se resolvieron dentro del espacio de trabajo, así que solo
+		// queda comprobar los miembros del grupo
+		if group != nil {
+			if err := checkTaskMembers(ctx, &task, group); err != nil {
+				errs = append(errs, apiError(err).Message(localeOf(c)))
+			}
+		}
+		if len(errs) == 0 && !task.Validate(workflow) {
+			errs = append(errs, tr(c, "Invalid task data"))
+		}
+
+		result.Valid = len(errs) == 0
+		result.Errors = errs
+		results[i] = result
+		tasks[i] = task
+	}
+
+	// Las subtareas de filas no válidas tampoco se importan
+	rowByID := make(map[string]int)
+	for i, task := range tasks {
+		rowByID[task.ID] = i
+	}
+	for changed := true; changed; {
+		changed = false
+		for i, task := range tasks {
+			if !results[i].Valid || task.ParentID == nil {
+				continue
+			}
+			if parent := rowByID[*task.ParentID]; !results[parent].Valid {
+				results[i].Valid = false
+				results[i].Errors = append(results[i].Errors, fmt.Sprintf("Parent task in row %d is not valid", parent+1))
+				changed = true
+			}
+		}
+	}
+
+	valid := 0
+	for _, result := range results {
+		if result.Valid {
+			valid++
+		}
+	}
+
+	if dryRun || valid == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"dry_run":  dryRun,
+			"imported": 0,
+			"failed":   len(results) - valid,
+			"results":  results,
+		})
+		return
+	}
+
+	// Colocar las tareas importadas al final de su columna, en el orden del fichero
+	lastRanks := make(map[string]string)
+	writer := database.Client.BulkWriter(ctx)
+	jobs := make(map[int]*firestore.BulkWriterJob)
+
+	for i := range tasks {
+		if !results[i].Valid {
+			continue
+		}
+		task := &tasks[i]
+
+		previousRank, ok := lastRanks[task.Status]
+		if !ok {
+			if r, err := appendRank(ctx, task); err == nil {
+				previousRank = r
+			} else {
+				log.Printf("Error computing task rank: %v", err)
+			}
+			task.Rank = previousRank
+		} else if r, err := rank.Between(previousRank, ""); err == nil {
+			task.Rank = r
+		}
+		lastRanks[task.Status] = task.Rank
+
+		job, err := writer.Create(database.Client.Collection("tasks").Doc(task.ID), task)
+		if err != nil {
+			results[i].Valid = false
+			results[i].Errors = append(results[i].Errors, "Error creating task")
+			continue
+		}
+		jobs[i] = job
+	}
+
+	writer.End()
+
+	// Una importación grande alarga las claves del final de la columna
+	for i := range tasks {
+		task := &tasks[i]
+		if _, queued := jobs[i]; !queued || task.Rank != lastRanks[task.Status] || len(task.Rank) <= rank.MaxLength {
+			continue
+		}
+		ranked, err := rebalanceColumn(ctx, task, task.Status)
+		if err != nil {
+			log.Printf("Error rebalancing board column: %v", err)
+			continue
+		}
+		ranks := make(map[string]string, len(ranked))
+		for _, t := range ranked {
+			ranks[t.ID] = t.Rank
+		}
+		for j := range tasks {
+			if r, ok := ranks[tasks[j].ID]; ok {
+				tasks[j].Rank = r
+			}
+		}
+	}
+
+	imported := 0
+	for i, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			log.Printf("Error importing task in row %d: %v", i+1, err)
+			results[i].Valid = false
+			results[i].Errors = append(results[i].Errors, "Error creating task")
+			continue
+		}
+		imported++
+		publishTaskEvent(c, events.TaskCreated, &tasks[i], nil)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run":  false,
+		"imported": imported,
+		"failed":   len(results) - imported,
+		"results":  results,
+	})
+}
+
+// transferScope devuelve el ID y el grupo indicado en ?group_id tras verificar que
+// el rol del usuario tiene el permiso, o nil para sus tareas personales
+func transferScope(c *gin.Context, userID string, permission models.GroupPermission) (*string, *models.Group, error) {
+	groupID := c.Query("group_id")
+	if groupID == "" {
+		return nil, nil, nil
+	}
+
+	group, err := services.NewGroupService().InWorkspace(workspaceOf(c)).GetGroupByID(groupID)
+	if err != nil {
+		if errors.Is(err, services.ErrGroupNotFound) {
+			return nil, nil, problem.New(problem.CodeGroupNotFound, "Group not found")
+		}
+		return nil, nil, err
+	}
+	if !group.Can(userID, permission) {
+		if permission == models.PermViewGroup {
+			return nil, nil, problem.New(problem.CodeForbidden, "You are not a member of this group")
+		}
+		return nil, nil, errGroupRole
+	}
+
+	return &groupID, group, nil
+}
+
+// fetchGroupTasks obtiene todas las tareas de un grupo del espacio de trabajo
+func fetchGroupTasks(ctx context.Context, workspaceID, groupID string) ([]models.Task, error) {
+	docs, err := database.InWorkspace(database.Client.Collection("tasks").Query, workspaceID).
+		Where("group_id", "==", groupID).
+		Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error fetching group tasks: %v", err)
+		return nil, err
+	}
+
+	var tasks []models.Task
+	for _, doc := range docs {
+		var task models.Task
+		if err := doc.DataTo(&task); err != nil {
+			log.Printf("Error converting document to task: %v", err)
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// parseImport lee las filas a importar según el Content-Type de la petición
+func parseImport(c *gin.Context) ([]TaskRecord, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+
+	switch mediaType {
+	case "text/csv":
+		return parseCSVRecords(c.Request.Body)
+	case "application/json", "":
+		var body struct {
+			Tasks []TaskRecord `json:"tasks"`
+		}
+		if err := json.NewDecoder(c.Request.Body).Decode(&body); err != nil {
+			return nil, problem.Newf(problem.CodeBadRequest, "invalid JSON document: %v", err)
+		}
+		return body.Tasks, nil
+	default:
+		return nil, problem.New(problem.CodeBadRequest, "Content-Type must be application/json or text/csv")
+	}
+}
+
+// parseCSVRecords lee un CSV con cabecera. Las columnas se identifican por nombre,
+// por lo que pueden venir en cualquier orden y las desconocidas se ignoran.
+func parseCSVRecords(r io.Reader) ([]TaskRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, problem.New(problem.CodeBadRequest, "invalid CSV document: missing header")
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, problem.New(problem.CodeBadRequest, "invalid CSV document: missing title column")
+	}
+
+	var records []TaskRecord
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, problem.Newf(problem.CodeBadRequest, "invalid CSV document: %v", err)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		record := TaskRecord{
+			ID:              field("id"),
+			Title:           field("title"),
+			Description:     field("description"),
+			Status:          field("status"),
+			Category:        field("category"),
+			Priority:        field("priority"),
+			Labels:          splitCSVList(field("labels")),
+			AssignedTo:      field("assigned_to"),
+			Collaborators:   splitCSVList(field("collaborators")),
+			EstimatedEffort: field("estimated_effort"),
+			TimeUntilFinish: field("time_until_finish"),
+			Recurrence:      field("recurrence"),
+			ParentID:        field("parent_id"),
+		}
+		if v := field("remind_me"); v != "" {
+			if record.RemindMe, err = strconv.ParseBool(v); err != nil {
+				return nil, problem.Newf(problem.CodeBadRequest, "invalid remind_me on line %d", line)
+			}
+		}
+		if v := field("due_date"); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, problem.Newf(problem.CodeBadRequest, "invalid due_date on line %d", line)
+			}
+			record.DueDate = &t
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// buildImportedTask convierte una fila importada en una tarea del usuario y
+// devuelve los errores encontrados, traducidos a locale
+func buildImportedTask(locale i18n.Locale, record TaskRecord, userID string, groupID *string, workflow *models.Workflow,
+	userIDs map[string]string, labelsByName map[string]models.Label, newIDs map[string]string) (models.Task, []string) {
+	var errs []string
+
+	task := models.Task{
+		UserID:      userID,
+		CreatedBy:   userID,
+		GroupID:     groupID,
+		Title:       strings.TrimSpace(record.Title),
+		Description: record.Description,
+		Status:      record.Status,
+		Category:    record.Category,
+		Priority:    record.Priority,
+		RemindMe:    record.RemindMe,
+		DueDate:     record.DueDate,
+		Recurrence:  record.Recurrence,
+		Version:     1,
+	}
+
+	if task.Title == "" {
+		errs = append(errs, i18n.T(locale, "title is required"))
+	}
+
+	// Sin estado la tarea empieza en el primero del flujo
+	if task.Status == "" && len(workflow.Statuses) > 0 {
+		task.Status = workflow.Statuses[0].Key
+	} else if !workflow.HasStatus(task.Status) {
+		errs = append(errs, i18n.T(locale, "unknown status: %s", task.Status))
+	}
+
+	if task.Priority != "" && models.PriorityLevel(task.Priority) == 0 {
+		errs = append(errs, i18n.T(locale, "invalid priority: %s", task.Priority))
+	}
+
+	if task.Recurrence != "" && !models.ValidRecurrence(task.Recurrence) {
+		errs = append(errs, i18n.T(locale, "invalid recurrence: %s", task.Recurrence))
+	}
+
+	if record.EstimatedEffort != "" {
+		d, err := time.ParseDuration(record.EstimatedEffort)
+		if err != nil || d < 0 {
+			errs = append(errs, i18n.T(locale, "invalid estimated_effort: %s", record.EstimatedEffort))
+		}
+		task.EstimatedEffort = d
+	}
+	if record.TimeUntilFinish != "" {
+		d, err := time.ParseDuration(record.TimeUntilFinish)
+		if err != nil {
+			errs = append(errs, i18n.T(locale, "invalid time_until_finish: %s", record.TimeUntilFinish))
+		}
+		task.TimeUntilFinish = d
+	}
+
+	if record.AssignedTo != "" {
+		id, ok := userIDs[strings.ToLower(strings.TrimSpace(record.AssignedTo))]
+		if !ok {
+			errs = append(errs, i18n.T(locale, "unknown user: %s", record.AssignedTo))
+		} else {
+			task.AssignedTo = &id
+		}
+	} else if groupID != nil {
+		// Las tareas de grupo se asignan por defecto a quien las importa
+		task.AssignedTo = &userID
+	}
+
+	for _, email := range record.Collaborators {
+		id, ok := userIDs[strings.ToLower(strings.TrimSpace(email))]
+		if !ok {
+			errs = append(errs, i18n.T(locale, "unknown user: %s", email))
+			continue
+		}
+		if id != userID && !contains(task.ArrCollaborators, id) {
+			task.ArrCollaborators = append(task.ArrCollaborators, id)
+		}
+	}
+
+	for _, name := range record.Labels {
+		label, ok := labelsByName[strings.ToLower(name)]
+		if !ok {
+			errs = append(errs, i18n.T(locale, "unknown label: %s", name))
+			continue
+		}
+		if !contains(task.LabelIDs, label.ID) {
+			task.LabelIDs = append(task.LabelIDs, label.ID)
+			task.Labels = append(task.Labels, label.ToTaskLabel())
+		}
+	}
+
+	if record.ParentID != "" {
+		parentID, ok := newIDs[record.ParentID]
+		if !ok || record.ParentID == record.ID {
+			errs = append(errs, i18n.T(locale, "parent task not found in import: %s", record.ParentID))
+		} else {
+			task.ParentID = &parentID
+		}
+	}
+
+	return task, errs
+}
+
+// toTaskRecord convierte una tarea a su representación portable
+func toTaskRecord(task models.Task, emails map[string]string) TaskRecord {
+	record := TaskRecord{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      task.Status,
+		Category:    task.Category,
+		Priority:    task.Priority,
+		RemindMe:    task.RemindMe,
+		DueDate:     task.DueDate,
+		Recurrence:  task.Recurrence,
+	}
+
+	createdAt := task.CreatedAt
+	record.CreatedAt = &createdAt
+
+	if task.EstimatedEffort > 0 {
+		record.EstimatedEffort = task.EstimatedEffort.String()
+	}
+	if task.TimeUntilFinish > 0 {
+		record.TimeUntilFinish = task.TimeUntilFinish.String()
+	}
+	if task.ParentID != nil {
+		record.ParentID = *task.ParentID
+	}
+	if task.AssignedTo != nil {
+		record.AssignedTo = emails[*task.AssignedTo]
+	}
+	for _, id := range task.ArrCollaborators {
+		if email, ok := emails[id]; ok {
+			record.Collaborators = append(record.Collaborators, email)
+		}
+	}
+	for _, label := range task.Labels {
+		record.Labels = append(record.Labels, label.Name)
+	}
+
+	return record
+}
+
+// csvRow devuelve la fila CSV de la tarea en el orden de taskCSVHeader
+func (r TaskRecord) csvRow() []string {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	return []string{
+		r.ID, r.Title, r.Description, r.Status, r.Category, r.Priority,
+		strings.Join(r.Labels, ";"), r.AssignedTo, strings.Join(r.Collaborators, ";"),
+		formatTime(r.DueDate), r.EstimatedEffort, r.TimeUntilFinish,
+		strconv.FormatBool(r.RemindMe), r.Recurrence, r.ParentID, formatTime(r.CreatedAt),
+	}
+}
+
+// splitCSVList separa una lista de valores separados por punto y coma
+func splitCSVList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// emailsByUserID obtiene el email de cada usuario del espacio de trabajo a través
+// de la caché de perfiles
+func emailsByUserID(ctx context.Context, workspaceID string, userIDs []string) (map[string]string, error) {
+	emails := make(map[string]string)
+
+	ids := uniqueStrings(userIDs)
+	if len(ids) == 0 {
+		return emails, nil
+	}
+
+	profiles, err := services.UserProfiles.Get(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for id, user := range profiles {
+		if models.SameWorkspace(user.WorkspaceID, workspaceID) && user.Email != "" {
+			emails[id] = user.Email
+		}
+	}
+
+	return emails, nil
+}
+
+// userIDsByEmail obtiene el ID de cada usuario del espacio de trabajo indexado por
+// su email en minúsculas
+func userIDsByEmail(ctx context.Context, workspaceID string, emails []string) (map[string]string, error) {
+	ids := make(map[string]string)
+
+	// Los emails se guardan tal como se registraron: se busca el valor original y en minúsculas
+	var wanted []string
+	for _, email := range emails {
+		email = strings.TrimSpace(email)
+		wanted = append(wanted, email, strings.ToLower(email))
+	}
+	wanted = uniqueStrings(wanted)
+
+	// Firestore admite hasta 30 valores por consulta "in"
+	for start := 0; start < len(wanted); start += 30 {
+		end := start + 30
+		if end > len(wanted) {
+			end = len(wanted)
+		}
+
+		docs, err := database.InWorkspace(database.Client.Collection("users").Query, workspaceID).
+			Where("email", "in", wanted[start:end]).
+			Documents(ctx).GetAll()
+		if err != nil {
+			log.Printf("Error fetching users by email: %v", err)
+			return nil, err
+		}
+
+		for _, doc := range docs {
+			if email, ok := doc.Data()["email"].(string); ok {
+				ids[strings.ToLower(email)] = doc.Ref.ID
+			}
+		}
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,431 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"task-manager-backend/api/problem"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/events"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/rank"
+	"task-manager-backend/internal/services"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TemplateRequest struct {
+	Name          string                   `json:"name" binding:"required"`
+	Title         string                   `json:"title" binding:"required"`
+	Description   string                   `json:"description" binding:"required"`
+	Category      string                   `json:"category"`
+	Priority      string                   `json:"priority,omitempty"`
+	Collaborators []string                 `json:"collaborators,omitempty"`
+	DueOffset     time.Duration            `json:"due_offset,omitempty"` // Vencimiento relativo en nanosegundos
+	Subtasks      []models.TemplateSubtask `json:"subtasks,omitempty"`
+	GroupID       *string                  `json:"group_id,omitempty"` // Grupo con el que se comparte (opcional, solo al crear)
+}
+
+type FromTemplateRequest struct {
+	Variables  map[string]string `json:"variables,omitempty"`   // Variables adicionales para {{nombre}}
+	AssignedTo *string           `json:"assigned_to,omitempty"` // Usuario asignado (por defecto, quien crea la tarea en grupos)
+	StartAt    *time.Time        `json:"start_at,omitempty"`    // Referencia para los vencimientos relativos (por defecto, ahora)
+}
+
+type TemplateHandler struct {
+	templateService *services.TemplateService
+	groupService    *services.GroupService
+}
+
+func NewTemplateHandler() *TemplateHandler {
+	return &TemplateHandler{
+		templateService: services.NewTemplateService(),
+		groupService:    services.NewGroupService(),
+	}
+}
+
+// canUseTemplate verifica si el usuario puede ver o usar una plantilla: las personales
+// su autor y las de grupo quien tenga el permiso en él
+func (h *TemplateHandler) canUseTemplate(template *models.TaskTemplate, workspaceID, userID string, permission models.GroupPermission) (bool, error) {
+	if !template.IsGroupTemplate() {
+		return template.OwnerID == userID, nil
+	}
+	group, err := h.groupService.InWorkspace(workspaceID).GetGroupByID(*template.GroupID)
+	if err != nil {
+		return false, err
+	}
+	return group.Can(userID, permission), nil
+}
+
+// canEditTemplate verifica si el usuario puede modificar una plantilla:
+// su autor o, en plantillas de grupo, quien pueda editar el grupo
+func (h *TemplateHandler) canEditTemplate(template *models.TaskTemplate, workspaceID, userID string) (bool, error) {
+	if template.OwnerID == userID {
+		return true, nil
+	}
+	if !template.IsGroupTemplate() {
+		return false, nil
+	}
+	group, err := h.groupService.InWorkspace(workspaceID).GetGroupByID(*template.GroupID)
+	if err != nil {
+		return false, err
+	}
+	return group.Can(userID, models.PermEditGroup), nil
+}
+
+// loadTemplate obtiene una plantilla y responde con el error adecuado si no existe
+func (h *TemplateHandler) loadTemplate(c *gin.Context) (*models.TaskTemplate, bool) {
+	template, err := h.templateService.GetTemplate(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrTemplateNotFound) {
+			respondError(c, problem.New(problem.CodeNotFound, "Template not found"))
+		} else {
+			respondError(c, problem.Internal("Error fetching template", err))
+		}
+		return nil, false
+	}
+	return template, true
+}
+
+// GetTemplatesHandler obtiene las plantillas personales o las de un grupo (?group_id=)
+func (h *TemplateHandler) GetTemplatesHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	var groupID *string
+	if id := c.Query("group_id"); id != "" {
+		groupID = &id
+
+		group, err := h.groupService.InWorkspace(workspaceOf(c)).GetGroupByID(id)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		if !group.IsMember(userID.(string)) {
+			respondError(c, problem.New(problem.CodeForbidden, "You are not a member of this group"))
+			return
+		}
+	}
+
+	templates, err := h.templateService.ListTemplates(userID.(string), groupID)
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching templates", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// GetTemplateHandler obtiene una plantilla por su ID
+func (h *TemplateHandler) GetTemplateHandler(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	template, ok := h.loadTemplate(c)
+	if !ok {
+		return
+	}
+
+	allowed, err := h.canUseTemplate(template, workspaceOf(c), userID.(string), models.PermViewGroup)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !allowed {
+		respondError(c, problem.New(problem.CodeForbidden, "User is not authorized to access this template"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"template": template})
+}
+
+// CreateTemplateHandler crea una plantilla personal o compartida con un grupo
+func (h *TemplateHandler) CreateTemplateHandler(c *gin.Context) {
+	var req TemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	template := models.TaskTemplate{OwnerID: userID.(string), GroupID: req.GroupID}
+	applyTemplateRequest(&template, req)
+
+	if !template.Validate() {
+		respondError(c, problem.New(problem.CodeBadRequest, "Invalid template data"))
+		return
+	}
+
+	allowed, err := h.canUseTemplate(&template, workspaceOf(c), userID.(string), models.PermCreateTasks)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !allowed {
+		respondError(c, problem.New(problem.CodeForbidden, "You don't have permission to create templates in this group"))
+		return
+	}
+
+	if err := h.templateService.CreateTemplate(&template); err != nil {
+		respondError(c, problem.Internal("Error creating template", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"template": template})
+}
+
+// UpdateTemplateHandler reemplaza el contenido de una plantilla
+func (h *TemplateHandler) UpdateTemplateHandler(c *gin.Context) {
+	var req TemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	template, ok := h.loadTemplate(c)
+	if !ok {
+		return
+	}
+
+	allowed, err := h.canEditTemplate(template, workspaceOf(c), userID.(string))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !allowed {
+		respondError(c, problem.New(problem.CodeForbidden, "Unauthorized to modify this template"))
+		return
+	}
+
+	applyTemplateRequest(template, req)
+
+	if !template.Validate() {
+		respondError(c, problem.New(problem.CodeBadRequest, "Invalid template data"))
+		return
+	}
+
+	if err := h.templateService.UpdateTemplate(template); err != nil {
+		respondError(c, problem.Internal("Error updating template", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"template": template})
+}
+
+// DeleteTemplateHandler elimina una plantilla
+func (h *TemplateHandler) DeleteTemplateHandler(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	template, ok := h.loadTemplate(c)
+	if !ok {
+		return
+	}
+
+	allowed, err := h.canEditTemplate(template, workspaceOf(c), userID.(string))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !allowed {
+		respondError(c, problem.New(problem.CodeForbidden, "Unauthorized to delete this template"))
+		return
+	}
+
+	if err := h.templateService.DeleteTemplate(template.ID); err != nil {
+		respondError(c, problem.Internal("Error deleting template", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": tr(c, "Template deleted successfully")})
+}
+
+// CreateTaskFromTemplateHandler crea una tarea y sus subtareas a partir de una plantilla,
+// sustituyendo las variables de los títulos y descripciones
+func (h *TemplateHandler) CreateTaskFromTemplateHandler(c *gin.Context) {
+	var req FromTemplateRequest
+	// El cuerpo es opcional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, problem.New(problem.CodeValidation, err.Error()))
+			return
+		}
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	template, ok := h.loadTemplate(c)
+	if !ok {
+		return
+	}
+
+	allowed, err := h.canUseTemplate(template, workspaceOf(c), userID.(string), models.PermViewGroup)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !allowed {
+		respondError(c, problem.New(problem.CodeForbidden, "User is not authorized to use this template"))
+		return
+	}
+
+	ctx := context.Background()
+
+	start := time.Now()
+	if req.StartAt != nil {
+		start = *req.StartAt
+	}
+
+	vars, err := h.templateVariables(ctx, template, workspaceOf(c), userID.(string), start)
+	if err != nil {
+		respondError(c, problem.Internal("Error preparing template variables", err))
+		return
+	}
+	for name, value := range req.Variables {
+		vars[name] = value
+	}
+
+	workflow, err := workflowFor(ctx, nil, workspaceOf(c), template.GroupID)
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching task workflow", err))
+		return
+	}
+
+	// Las tareas de grupo necesitan un usuario asignado
+	assignedTo := req.AssignedTo
+	if assignedTo == nil && template.IsGroupTemplate() {
+		self := userID.(string)
+		assignedTo = &self
+	}
+
+	newTask := func(title, description string, dueOffset time.Duration) models.Task {
+		now := time.Now()
+		task := models.Task{
+			ID:               uuid.New().String(),
+			UserID:           userID.(string),
+			Title:            models.ExpandVariables(title, vars),
+			Description:      models.ExpandVariables(description, vars),
+			Status:           workflow.Statuses[0].Key,
+			Category:         template.Category,
+			Priority:         template.Priority,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+			CreatedBy:        userID.(string),
+			GroupID:          template.GroupID,
+			WorkspaceID:      workspaceOf(c),
+			AssignedTo:       assignedTo,
+			ArrCollaborators: append([]string(nil), template.Collaborators...),
+			Version:          1,
+		}
+		task.TrackAssignment(nil, now)
+		if dueOffset > 0 {
+			due := start.Add(dueOffset)
+			task.DueDate = &due
+		}
+		return task
+	}
+
+	parent := newTask(template.Title, template.Description, template.DueOffset)
+	if err := prepareNewTask(ctx, &parent); err != nil {
+		respondError(c, problem.Internal("Error creating task from template", err))
+		return
+	}
+
+	subtasks := make([]models.Task, 0, len(template.Subtasks))
+	previousRank := parent.Rank
+	for _, st := range template.Subtasks {
+		subtask := newTask(st.Title, st.Description, st.DueOffset)
+		subtask.ParentID = &parent.ID
+		if err := prepareNewTask(ctx, &subtask); err != nil {
+			respondError(c, problem.Internal("Error creating task from template", err))
+			return
+		}
+		// Las subtareas van a continuación de la tarea principal en la misma columna
+		if previousRank != "" {
+			if r, err := rank.Between(previousRank, ""); err == nil {
+				subtask.Rank = r
+			}
+		}
+		previousRank = subtask.Rank
+		subtasks = append(subtasks, subtask)
+	}
+
+	// Crear todas las tareas en una sola transacción
+	err = database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		tasksRef := database.Client.Collection("tasks")
+		if err := tx.Create(tasksRef.Doc(parent.ID), parent); err != nil {
+			return err
+		}
+		for _, subtask := range subtasks {
+			if err := tx.Create(tasksRef.Doc(subtask.ID), subtask); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		respondError(c, problem.Internal("Error creating task from template", err))
+		return
+	}
+
+	publishTaskEvent(c, events.TaskCreated, &parent, nil)
+	for i := range subtasks {
+		publishTaskEvent(c, events.TaskCreated, &subtasks[i], nil)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"task": parent, "subtasks": subtasks})
+}
+
+// templateVariables devuelve las variables predefinidas de las plantillas:
+// {{date}}, {{datetime}}, {{user}} y {{group}}
+func (h *TemplateHandler) templateVariables(ctx context.Context, template *models.TaskTemplate, workspaceID, userID string, start time.Time) (map[string]string, error) {
+	vars := map[string]string{
+		"date":     start.Format("2006-01-02"),
+		"datetime": start.Format(time.RFC3339),
+	}
+
+	profiles, err := services.UserProfiles.Get(ctx, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+	if user, ok := profiles[userID]; ok {
+		vars["user"] = user.Username
+	}
+
+	if template.IsGroupTemplate() {
+		group, err := h.groupService.InWorkspace(workspaceID).GetGroupByID(*template.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		vars["group"] = group.Name
+	}
+
+	return vars, nil
+}
+
+// applyTemplateRequest copia los campos editables de la petición a la plantilla
+func applyTemplateRequest(template *models.TaskTemplate, req TemplateRequest) {
+	template.Name = req.Name
+	template.Title = req.Title
+	template.Description = req.Description
+	template.Category = req.Category
+	template.Priority = req.Priority
+	template.Collaborators = req.Collaborators
+	template.DueOffset = req.DueOffset
+	template.Subtasks = req.Subtasks
+}
@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"task-manager-backend/api/problem"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ManualTimeEntryRequest struct {
+	StartedAt time.Time     `json:"started_at" binding:"required"`
+	EndedAt   *time.Time    `json:"ended_at,omitempty"` // Fin del periodo (o bien duration)
+	Duration  time.Duration `json:"duration,omitempty"` // Duración en nanosegundos (o bien ended_at)
+	Note      string        `json:"note,omitempty"`
+}
+
+type TimeTrackingHandler struct {
+	timeService  *services.TimeTrackingService
+	groupService *services.GroupService
+}
+
+func NewTimeTrackingHandler() *TimeTrackingHandler {
+	return &TimeTrackingHandler{
+		timeService:  services.NewTimeTrackingService(),
+		groupService: services.NewGroupService(),
+	}
+}
+
+// StartTimerHandler inicia un temporizador del usuario sobre una tarea
+func (h *TimeTrackingHandler) StartTimerHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	task, err := loadAccessibleTask(context.Background(), workspaceOf(c), c.Param("id"), userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching task", err))
+		return
+	}
+
+	// Los observadores del grupo no registran tiempo en sus tareas
+	if err := authorizeGroupTask(context.Background(), nil, task, userID.(string), models.PermEditTasks); err != nil {
+		respondError(c, problem.Internal("Error fetching group", err))
+		return
+	}
+
+	entry, err := h.timeService.StartTimer(task, userID.(string))
+	if err != nil {
+		if errors.Is(err, services.ErrTimerRunning) {
+			active, _ := h.timeService.GetActiveTimer(userID.(string))
+			respondError(c, problem.New(problem.CodeConflict, "You already have a running timer").With("timer", active))
+			return
+		}
+		respondError(c, problem.Internal("Error starting timer", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"time_entry": entry})
+}
+
+// StopTimerHandler detiene el temporizador en marcha del usuario
+func (h *TimeTrackingHandler) StopTimerHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	entry, err := h.timeService.StopTimer(userID.(string))
+	if err != nil {
+		if errors.Is(err, services.ErrNoTimerRunning) {
+			respondError(c, problem.New(problem.CodeNotFound, "No timer is running"))
+			return
+		}
+		respondError(c, problem.Internal("Error stopping timer", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"time_entry": entry})
+}
+
+// GetActiveTimerHandler obtiene el temporizador en marcha del usuario, si existe
+func (h *TimeTrackingHandler) GetActiveTimerHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	active, err := h.timeService.GetActiveTimer(userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching active timer", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"timer": active})
+}
+
+// GetTaskTimeEntriesHandler obtiene los registros de tiempo de una tarea
+func (h *TimeTrackingHandler) GetTaskTimeEntriesHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	task, err := loadAccessibleTask(context.Background(), workspaceOf(c), c.Param("id"), userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching task", err))
+		return
+	}
+
+	entries, err := h.timeService.ListTaskEntries(task.ID)
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching time entries", err))
+		return
+	}
+
+	var total time.Duration
+	for _, entry := range entries {
+		total += entry.Duration
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"time_entries":     entries,
+		"total":            total,
+		"estimated_effort": task.EstimatedEffort,
+	})
+}
+
+// CreateTimeEntryHandler registra a mano un periodo de trabajo sobre una tarea
+func (h *TimeTrackingHandler) CreateTimeEntryHandler(c *gin.Context) {
+	var req ManualTimeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	task, err := loadAccessibleTask(context.Background(), workspaceOf(c), c.Param("id"), userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching task", err))
+		return
+	}
+
+	// Los observadores del grupo no registran tiempo en sus tareas
+	if err := authorizeGroupTask(context.Background(), nil, task, userID.(string), models.PermEditTasks); err != nil {
+		respondError(c, problem.Internal("Error fetching group", err))
+		return
+	}
+
+	entry := models.TimeEntry{
+		TaskID:    task.ID,
+		UserID:    userID.(string),
+		GroupID:   task.GroupID,
+		StartedAt: req.StartedAt,
+		Note:      req.Note,
+		Source:    models.TimeEntrySourceManual,
+	}
+
+	// Se acepta el fin del periodo o su duración
+	switch {
+	case req.EndedAt != nil:
+		entry.EndedAt = req.EndedAt
+		entry.Duration = req.EndedAt.Sub(req.StartedAt)
+	case req.Duration > 0:
+		ended := req.StartedAt.Add(req.Duration)
+		entry.EndedAt = &ended
+		entry.Duration = req.Duration
+	default:
+		respondError(c, problem.New(problem.CodeBadRequest, "Either ended_at or duration is required"))
+		return
+	}
+
+	if !entry.Validate() || entry.EndedAt.After(time.Now()) {
+		respondError(c, problem.New(problem.CodeBadRequest, "Invalid time entry data"))
+		return
+	}
+
+	if err := h.timeService.AddManualEntry(&entry); err != nil {
+		respondError(c, problem.Internal("Error creating time entry", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"time_entry": entry})
+}
+
+// DeleteTimeEntryHandler elimina un registro de tiempo. Puede hacerlo quien lo
+// registró o el propietario de la tarea.
+func (h *TimeTrackingHandler) DeleteTimeEntryHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	entry, err := h.timeService.GetEntry(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrTimeEntryNotFound) {
+			respondError(c, problem.New(problem.CodeNotFound, "Time entry not found"))
+			return
+		}
+		respondError(c, problem.Internal("Error fetching time entry", err))
+		return
+	}
+
+	if entry.UserID != userID.(string) {
+		task, err := loadAccessibleTask(context.Background(), workspaceOf(c), entry.TaskID, userID.(string))
+		if err != nil || task.UserID != userID.(string) {
+			respondError(c, problem.New(problem.CodeForbidden, "Unauthorized to delete this time entry"))
+			return
+		}
+	}
+
+	if err := h.timeService.DeleteEntry(entry); err != nil {
+		respondError(c, problem.Internal("Error deleting time entry", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": tr(c, "Time entry deleted successfully")})
+}
+
+// GetTimeSummaryHandler agrega el tiempo registrado por tarea, usuario, grupo o fecha.
+// Sin task_id ni group_id solo se incluye el tiempo del propio usuario.
+//
+//	?group_by=task|user|group|date&from=2024-01-01&to=2024-01-31&tz=Europe/Madrid
+//	&task_id=...&group_id=...&user_id=...
+func (h *TimeTrackingHandler) GetTimeSummaryHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	filter := services.TimeSummaryFilter{
+		TaskID:  c.Query("task_id"),
+		UserID:  c.Query("user_id"),
+		GroupID: c.Query("group_id"),
+		GroupBy: c.DefaultQuery("group_by", "task"),
+	}
+
+	switch filter.GroupBy {
+	case "task", "user", "group", "date":
+	default:
+		respondError(c, problem.New(problem.CodeBadRequest, "group_by must be one of task, user, group or date"))
+		return
+	}
+
+	loc, err := time.LoadLocation(c.DefaultQuery("tz", "UTC"))
+	if err != nil {
+		respondError(c, problem.New(problem.CodeBadRequest, "Invalid time zone"))
+		return
+	}
+	filter.Loc = loc
+
+	if filter.From, err = parseDateParam(c.Query("from"), loc, false); err != nil {
+		respondError(c, problem.New(problem.CodeBadRequest, "Invalid from date"))
+		return
+	}
+	if filter.To, err = parseDateParam(c.Query("to"), loc, true); err != nil {
+		respondError(c, problem.New(problem.CodeBadRequest, "Invalid to date"))
+		return
+	}
+
+	// Verificar el alcance del resumen
+	switch {
+	case filter.TaskID != "":
+		if _, err := loadAccessibleTask(context.Background(), workspaceOf(c), filter.TaskID, userID.(string)); err != nil {
+			respondError(c, problem.Internal("Error fetching task", err))
+			return
+		}
+	case filter.GroupID != "":
+		group, err := h.groupService.InWorkspace(workspaceOf(c)).GetGroupByID(filter.GroupID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		if !group.IsMember(userID.(string)) {
+			respondError(c, problem.New(problem.CodeForbidden, "You are not a member of this group"))
+			return
+		}
+	default:
+		if filter.UserID != "" && filter.UserID != userID.(string) {
+			respondError(c, problem.New(problem.CodeForbidden, "You can only see the time of other users within a task or group"))
+			return
+		}
+		filter.UserID = userID.(string)
+	}
+
+	buckets, total, err := h.timeService.Summarize(filter)
+	if err != nil {
+		respondError(c, problem.Internal("Error summarizing time entries", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"group_by": filter.GroupBy,
+		"buckets":  buckets,
+		"total":    total,
+	})
+}
+
+// parseDateParam interpreta una fecha RFC 3339 o AAAA-MM-DD. Las fechas sin hora
+// usadas como límite superior incluyen el día completo.
+func parseDateParam(value string, loc *time.Location, endOfRange bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfRange {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"task-manager-backend/api/problem"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookRequest struct {
+	URL     string   `json:"url" binding:"required"`
+	Events  []string `json:"events" binding:"required"` // p. ej. task.created, task.completed, group.member_added
+	Active  *bool    `json:"active,omitempty"`          // Activo por defecto
+	GroupID *string  `json:"group_id,omitempty"`        // Grupo del webhook (opcional, solo al crear)
+}
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+	groupService   *services.GroupService
+	dispatcher     *services.WebhookDispatcher
+}
+
+func NewWebhookHandler(dispatcher *services.WebhookDispatcher) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: services.NewWebhookService(),
+		groupService:   services.NewGroupService(),
+		dispatcher:     dispatcher,
+	}
+}
+
+// canManageWebhooks verifica si el usuario puede gestionar los webhooks de un ámbito:
+// los personales su propietario y los de grupo quien pueda editar el grupo
+func (h *WebhookHandler) canManageWebhooks(workspaceID, userID, ownerID string, groupID *string) (bool, error) {
+	if groupID == nil || *groupID == "" {
+		return ownerID == userID, nil
+	}
+	group, err := h.groupService.InWorkspace(workspaceID).GetGroupByID(*groupID)
+	if err != nil {
+		return false, err
+	}
+	return group.Can(userID, models.PermEditGroup), nil
+}
+
+// loadWebhook obtiene el webhook de la ruta y verifica que el usuario pueda gestionarlo
+func (h *WebhookHandler) loadWebhook(c *gin.Context) (*models.Webhook, bool) {
+	userID, _ := c.Get("user_id")
+
+	webhook, err := h.webhookService.GetWebhook(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrWebhookNotFound) {
+			respondError(c, problem.New(problem.CodeNotFound, "Webhook not found"))
+		} else {
+			respondError(c, problem.Internal("Error fetching webhook", err))
+		}
+		return nil, false
+	}
+
+	allowed, err := h.canManageWebhooks(workspaceOf(c), userID.(string), webhook.OwnerID, webhook.GroupID)
+	if err != nil {
+		respondError(c, err)
+		return nil, false
+	}
+	if !allowed {
+		respondError(c, problem.New(problem.CodeForbidden, "Unauthorized to manage this webhook"))
+		return nil, false
+	}
+
+	return webhook, true
+}
+
+// GetWebhooksHandler obtiene los webhooks personales o los de un grupo (?group_id=)
+func (h *WebhookHandler) GetWebhooksHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	var groupID *string
+	if id := c.Query("group_id"); id != "" {
+		groupID = &id
+	}
+
+	allowed, err := h.canManageWebhooks(workspaceOf(c), userID.(string), userID.(string), groupID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !allowed {
+		respondError(c, problem.New(problem.CodeForbidden, "You don't have permission to manage the webhooks of this group"))
+		return
+	}
+
+	webhooks, err := h.webhookService.ListWebhooks(workspaceOf(c), userID.(string), groupID)
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching webhooks", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks, "events": models.WebhookEvents})
+}
+
+// GetWebhookHandler obtiene un webhook
+func (h *WebhookHandler) GetWebhookHandler(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhook": webhook})
+}
+
+// CreateWebhookHandler registra un webhook. La clave de firma solo se devuelve en esta respuesta.
+func (h *WebhookHandler) CreateWebhookHandler(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	webhook := models.Webhook{
+		OwnerID:     userID.(string),
+		GroupID:     req.GroupID,
+		WorkspaceID: workspaceOf(c),
+		URL:         req.URL,
+		Events:      uniqueStrings(req.Events),
+		Active:      req.Active == nil || *req.Active,
+	}
+
+	if !webhook.Validate() {
+		respondError(c, problem.New(problem.CodeBadRequest, "Invalid webhook data"))
+		return
+	}
+
+	allowed, err := h.canManageWebhooks(workspaceOf(c), userID.(string), webhook.OwnerID, webhook.GroupID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !allowed {
+		respondError(c, problem.New(problem.CodeForbidden, "You don't have permission to manage the webhooks of this group"))
+		return
+	}
+
+	if err := h.webhookService.CreateWebhook(&webhook); err != nil {
+		respondError(c, problem.Internal("Error creating webhook", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"webhook": webhook, "secret": webhook.Secret})
+}
+
+// UpdateWebhookHandler cambia la URL, los eventos o el estado de un webhook
+func (h *WebhookHandler) UpdateWebhookHandler(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	webhook.URL = req.URL
+	webhook.Events = uniqueStrings(req.Events)
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if !webhook.Validate() {
+		respondError(c, problem.New(problem.CodeBadRequest, "Invalid webhook data"))
+		return
+	}
+
+	if err := h.webhookService.UpdateWebhook(webhook); err != nil {
+		respondError(c, problem.Internal("Error updating webhook", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhook": webhook})
+}
+
+// DeleteWebhookHandler elimina un webhook y su registro de entregas
+func (h *WebhookHandler) DeleteWebhookHandler(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(webhook.ID); err != nil {
+		respondError(c, problem.Internal("Error deleting webhook", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": tr(c, "Webhook deleted successfully")})
+}
+
+// GetWebhookDeliveriesHandler obtiene el registro de las últimas entregas de un webhook
+func (h *WebhookHandler) GetWebhookDeliveriesHandler(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(webhook.ID)
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching webhook deliveries", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// RedeliverWebhookHandler vuelve a enviar una entrega con el mismo contenido.
+// El resultado se registra como una entrega nueva.
+func (h *WebhookHandler) RedeliverWebhookHandler(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	original, err := h.webhookService.GetDelivery(c.Param("delivery_id"))
+	if err != nil || original.WebhookID != webhook.ID {
+		if err == nil || errors.Is(err, services.ErrWebhookDeliveryNotFound) {
+			respondError(c, problem.New(problem.CodeNotFound, "Webhook delivery not found"))
+			return
+		}
+		respondError(c, problem.Internal("Error fetching webhook delivery", err))
+		return
+	}
+
+	if !webhook.Active {
+		respondError(c, problem.New(problem.CodeConflict, "The webhook is disabled"))
+		return
+	}
+
+	delivery, err := h.dispatcher.Redeliver(original)
+	if err != nil {
+		respondError(c, problem.Internal("Error redelivering webhook", err))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"delivery": delivery})
+}
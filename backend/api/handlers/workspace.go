@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"task-manager-backend/api/problem"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkspaceRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type WorkspaceRoleRequest struct {
+	Role string `json:"role" binding:"required"` // admin o member
+}
+
+type WorkspaceInviteRequest struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role,omitempty"` // member por defecto
+}
+
+type WorkspaceHandler struct {
+	workspaceService *services.WorkspaceService
+}
+
+func NewWorkspaceHandler(workspaceService *services.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceService: workspaceService,
+	}
+}
+
+// workspaceOf devuelve el espacio de trabajo del usuario actual, que viene en su JWT
+func workspaceOf(c *gin.Context) string {
+	workspaceID, _ := c.Get("workspace_id")
+	id, _ := workspaceID.(string)
+	return models.WorkspaceOf(id)
+}
+
+// userWorkspace obtiene el espacio de trabajo de un usuario cuando no hay JWT
+func userWorkspace(ctx context.Context, userID string) (string, error) {
+	workspaces, err := services.UserWorkspaces(ctx, []string{userID})
+	if err != nil {
+		return "", err
+	}
+	workspaceID, ok := workspaces[userID]
+	if !ok {
+		return "", services.ErrUserNotFound
+	}
+	return workspaceID, nil
+}
+
+// requireWorkspaceAdmin verifica que el usuario actual administre su espacio de trabajo
+func (h *WorkspaceHandler) requireWorkspaceAdmin(c *gin.Context) bool {
+	userID, _ := c.Get("user_id")
+
+	role, err := h.workspaceService.MemberRole(workspaceOf(c), userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching workspace role", err))
+		return false
+	}
+	if role != models.WorkspaceRoleAdmin {
+		respondError(c, problem.New(problem.CodeForbidden, "Only workspace admins can do this"))
+		return false
+	}
+	return true
+}
+
+// GetWorkspaceHandler obtiene el espacio de trabajo del usuario y su rol en él
+func (h *WorkspaceHandler) GetWorkspaceHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, problem.New(problem.CodeUnauthorized, "User ID not found in context"))
+		return
+	}
+
+	workspace, err := h.workspaceService.GetWorkspace(workspaceOf(c))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching workspace", err))
+		return
+	}
+
+	role, err := h.workspaceService.MemberRole(workspace.ID, userID.(string))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching workspace role", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"workspace": workspace, "role": role})
+}
+
+// UpdateWorkspaceHandler cambia el nombre del espacio de trabajo
+func (h *WorkspaceHandler) UpdateWorkspaceHandler(c *gin.Context) {
+	var req WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+
+	if !h.requireWorkspaceAdmin(c) {
+		return
+	}
+
+	workspace, err := h.workspaceService.RenameWorkspace(workspaceOf(c), req.Name)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidWorkspace) {
+			respondError(c, problem.New(problem.CodeBadRequest, err.Error()))
+			return
+		}
+		respondError(c, problem.Internal("Error updating workspace", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"workspace": workspace})
+}
+
+// GetWorkspaceMembersHandler obtiene los usuarios del espacio de trabajo
+func (h *WorkspaceHandler) GetWorkspaceMembersHandler(c *gin.Context) {
+	members, err := h.workspaceService.ListMembers(workspaceOf(c))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching workspace members", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+// UpdateWorkspaceMemberRoleHandler cambia el rol de un usuario del espacio de trabajo
+func (h *WorkspaceHandler) UpdateWorkspaceMemberRoleHandler(c *gin.Context) {
+	var req WorkspaceRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+
+	if !h.requireWorkspaceAdmin(c) {
+		return
+	}
+
+	user, err := h.workspaceService.SetMemberRole(workspaceOf(c), c.Param("user_id"), req.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRole):
+			respondError(c, problem.New(problem.CodeBadRequest, err.Error()))
+		case errors.Is(err, services.ErrUserNotFound):
+			respondError(c, problem.New(problem.CodeUserNotFound, "User not found"))
+		case errors.Is(err, services.ErrLastAdmin):
+			respondError(c, problem.New(problem.CodeConflict, err.Error()))
+		default:
+			respondError(c, problem.Internal("Error updating workspace role", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// GetWorkspaceInvitesHandler obtiene las invitaciones al espacio de trabajo
+func (h *WorkspaceHandler) GetWorkspaceInvitesHandler(c *gin.Context) {
+	if !h.requireWorkspaceAdmin(c) {
+		return
+	}
+
+	invites, err := h.workspaceService.ListInvites(workspaceOf(c))
+	if err != nil {
+		respondError(c, problem.Internal("Error fetching workspace invites", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invites": invites})
+}
+
+// CreateWorkspaceInviteHandler invita a una dirección de correo a registrarse en
+// el espacio de trabajo. El token solo se devuelve en esta respuesta y en el correo.
+func (h *WorkspaceHandler) CreateWorkspaceInviteHandler(c *gin.Context) {
+	var req WorkspaceInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, problem.New(problem.CodeValidation, err.Error()))
+		return
+	}
+	if !models.IsValidEmail(req.Email) {
+		respondError(c, problem.New(problem.CodeBadRequest, "Invalid email"))
+		return
+	}
+	if req.Role == "" {
+		req.Role = models.WorkspaceRoleMember
+	}
+
+	if !h.requireWorkspaceAdmin(c) {
+		return
+	}
+	userID, _ := c.Get("user_id")
+
+	token, invite, err := h.workspaceService.CreateInvite(workspaceOf(c), userID.(string), req.Email, req.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRole):
+			respondError(c, problem.New(problem.CodeBadRequest, err.Error()))
+		case errors.Is(err, services.ErrAlreadyInWorkspace), errors.Is(err, services.ErrWorkspaceInviteExists):
+			respondError(c, problem.New(problem.CodeConflict, err.Error()))
+		default:
+			respondError(c, problem.Internal("Error creating workspace invite", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"invite": invite, "token": token})
+}
+
+// RevokeWorkspaceInviteHandler elimina una invitación que aún no se usó
+func (h *WorkspaceHandler) RevokeWorkspaceInviteHandler(c *gin.Context) {
+	if !h.requireWorkspaceAdmin(c) {
+		return
+	}
+
+	if err := h.workspaceService.RevokeInvite(workspaceOf(c), c.Param("id")); err != nil {
+		switch {
+		case errors.Is(err, services.ErrWorkspaceInviteNotFound):
+			respondError(c, problem.New(problem.CodeNotFound, "Workspace invite not found"))
+		case errors.Is(err, services.ErrWorkspaceInviteUnusable):
+			respondError(c, problem.New(problem.CodeConflict, "The invite was already used"))
+		default:
+			respondError(c, problem.Internal("Error revoking workspace invite", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": tr(c, "Workspace invite revoked successfully")})
+}
@@ -1,42 +1,113 @@
 package middleware
 
 import (
-	"net/http"
+	"errors"
+	"net/url"
 	"os"
 	"strings"
+	"task-manager-backend/api/problem"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// ErrInvalidClaims indica que el token es válido pero sus claims no tienen el formato esperado
+var ErrInvalidClaims = errors.New("invalid token claims")
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
-			c.Abort()
+			problem.Respond(c, problem.New(problem.CodeUnauthorized, "Authorization header is required"))
 			return
 		}
 
-		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+		authenticate(c, strings.Replace(authHeader, "Bearer ", "", 1))
+	}
+}
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+// StreamAuthMiddleware valida el mismo JWT que AuthMiddleware, pero también lo acepta
+// en el parámetro access_token, porque EventSource no permite enviar cabeceras.
+// Solo debe usarse en las rutas de streaming.
+func StreamAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := strings.Replace(c.GetHeader("Authorization"), "Bearer ", "", 1)
+		if tokenString == "" {
+			tokenString = c.Query("access_token")
+		}
+		if tokenString == "" {
+			problem.Respond(c, problem.New(problem.CodeUnauthorized, "Authorization header or access_token is required"))
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			c.Abort()
-			return
+		authenticate(c, tokenString)
+	}
+}
+
+// RedactToken oculta el valor de access_token en una ruta con query string, para
+// que el JWT de las peticiones de streaming no quede escrito en los logs. El resto
+// de parámetros se mantiene tal cual.
+func RedactToken(path string) string {
+	base, rawQuery, found := strings.Cut(path, "?")
+	if !found {
+		return path
+	}
+
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		name, _, _ := strings.Cut(param, "=")
+		if key, err := url.QueryUnescape(name); err == nil && key == "access_token" {
+			params[i] = name + "=REDACTED"
+		}
+	}
+	return base + "?" + strings.Join(params, "&")
+}
+
+// ParseToken valida un JWT firmado con JWT_SECRET y devuelve sus claims
+func ParseToken(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenUnverifiable
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidClaims
+	}
+
+	return claims, nil
+}
+
+// authenticate valida el token y guarda el usuario en el contexto
+func authenticate(c *gin.Context, tokenString string) {
+	claims, err := ParseToken(tokenString)
+	if err != nil {
+		if errors.Is(err, ErrInvalidClaims) {
+			problem.Respond(c, problem.New(problem.CodeInvalidToken, "Invalid token claims"))
+		} else {
+			problem.Respond(c, problem.New(problem.CodeInvalidToken, "Invalid token"))
 		}
+		return
+	}
 
-		c.Set("user_id", claims["user_id"])
-		c.Next()
+	c.Set("user_id", claims["user_id"])
+
+	// El idioma que eligió el usuario prevalece sobre el de Accept-Language
+	if userID, ok := claims["user_id"].(string); ok {
+		if locale, ok := services.UserLocale(c.Request.Context(), userID); ok {
+			setLocale(c, locale)
+		}
 	}
+
+	// Los tokens emitidos antes de los espacios de trabajo no llevan el claim
+	workspaceID, _ := claims["workspace_id"].(string)
+	c.Set("workspace_id", models.WorkspaceOf(workspaceID))
+	c.Next()
 }
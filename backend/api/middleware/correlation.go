@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"task-manager-backend/api/problem"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CorrelationIDHeader es la cabecera con el ID de correlación de la petición
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// CorrelationID asigna a cada petición un ID de correlación, que aparece en los
+// logs y en las respuestas de error. Se respeta el que envía el cliente si es
+// razonable; si no, se genera uno.
+func CorrelationID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		correlationID := c.GetHeader(CorrelationIDHeader)
+		if !validCorrelationID(correlationID) {
+			correlationID = uuid.New().String()
+		}
+
+		c.Set(problem.CorrelationIDKey, correlationID)
+		c.Header(CorrelationIDHeader, correlationID)
+		c.Next()
+	}
+}
+
+// validCorrelationID acepta hasta 64 caracteres imprimibles sin espacios, para
+// que el ID no pueda alterar las líneas del log
+func validCorrelationID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
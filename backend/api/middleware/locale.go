@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"task-manager-backend/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale guarda en el contexto el idioma de la petición, el de mayor preferencia
+// de la cabecera Accept-Language entre los admitidos (inglés si no hay ninguno).
+// En las rutas protegidas el idioma que eligió el usuario tiene prioridad (ver
+// authenticate).
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Vary", "Accept-Language")
+		setLocale(c, i18n.Negotiate(c.GetHeader("Accept-Language")))
+		c.Next()
+	}
+}
+
+// setLocale guarda el idioma en el contexto y lo indica en la respuesta
+func setLocale(c *gin.Context, locale i18n.Locale) {
+	c.Set(i18n.ContextKey, string(locale))
+	c.Header("Content-Language", string(locale))
+}
@@ -0,0 +1,222 @@
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"task-manager-backend/api/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+// ServeJSON responde con el documento
+func (s *Spec) ServeJSON(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", s.raw)
+}
+
+// ServeDocs responde con una página que muestra el documento. La página no
+// depende de nada más y carga el documento de openapi.json, a su lado.
+func (s *Spec) ServeDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
+
+// CheckRoutes compara las rutas registradas con el documento y devuelve un
+// error con las rutas sin operación y las operaciones sin ruta
+func (s *Spec) CheckRoutes(routes gin.RoutesInfo) error {
+	registered := make(map[string]bool, len(routes))
+	var undocumented []string
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		if _, ok := s.operations[key]; !ok {
+			undocumented = append(undocumented, key)
+		}
+	}
+
+	var unrouted []string
+	for key := range s.operations {
+		if !registered[key] {
+			unrouted = append(unrouted, key)
+		}
+	}
+
+	if len(undocumented) == 0 && len(unrouted) == 0 {
+		return nil
+	}
+	sort.Strings(undocumented)
+	sort.Strings(unrouted)
+
+	var msg strings.Builder
+	msg.WriteString("the OpenAPI document does not match the routes")
+	if len(undocumented) > 0 {
+		fmt.Fprintf(&msg, "; routes without an operation: %s", strings.Join(undocumented, ", "))
+	}
+	if len(unrouted) > 0 {
+		fmt.Fprintf(&msg, "; operations without a route: %s", strings.Join(unrouted, ", "))
+	}
+	return fmt.Errorf("%s", msg.String())
+}
+
+// Validate comprueba cada petición contra su operación y rechaza con un error
+// validation_failed aquellas cuya query string o cuerpo JSON no coincide. Las
+// respuestas que no coinciden se registran con el ID de correlación, porque el
+// cliente ya las ha recibido. Los streams de eventos no se comprueban.
+//
+// Está pensado para los tests y el desarrollo local: guarda en memoria cada
+// respuesta JSON y rechaza peticiones que los handlers podrían aceptar.
+func (s *Spec) Validate() gin.HandlerFunc {
+	return s.ValidateWith(func(c *gin.Context, err error) {
+		log.Printf("[%s] %s %s: response %d does not match the API specification: %v",
+			c.GetString(problem.CorrelationIDKey), c.Request.Method, c.FullPath(), c.Writer.Status(), err)
+	})
+}
+
+// ValidateWith es Validate informando de otra forma de las respuestas que no
+// coinciden, p. ej. para que falle un test
+func (s *Spec) ValidateWith(report func(c *gin.Context, err error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op, ok := s.operations[c.Request.Method+" "+c.FullPath()]
+		if !ok || op.streaming {
+			c.Next()
+			return
+		}
+
+		if err := s.validateRequest(c, op); err != nil {
+			problem.Respond(c, problem.Newf(problem.CodeValidation, "Request does not match the API specification: %v", err))
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		c.Writer = recorder.ResponseWriter
+
+		if err := s.validateResponse(op, recorder); err != nil {
+			report(c, err)
+		}
+	}
+}
+
+func (s *Spec) validateRequest(c *gin.Context, op *operation) error {
+	query := c.Request.URL.Query()
+	for _, param := range op.query {
+		value, present := query[param.Name]
+		if !present {
+			if param.Required {
+				return &ValidationError{Path: param.Name, Message: "query parameter is required"}
+			}
+			continue
+		}
+		if param.Schema != nil && len(param.Schema.Enum) > 0 && !contains(param.Schema.Enum, value[0]) {
+			return &ValidationError{Path: param.Name, Message: fmt.Sprintf("must be one of %s", strings.Join(param.Schema.Enum, ", "))}
+		}
+	}
+
+	if len(op.body) == 0 || c.Request.Body == nil {
+		return nil
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return &ValidationError{Message: "unreadable request body"}
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	// Los cuerpos de tipos de contenido no declarados se dejan al handler, que
+	// los rechaza con su propio mensaje
+	schema, ok := op.body[mediaType(c.GetHeader("Content-Type"), "application/json")]
+	if !ok || !isJSON(mediaType(c.GetHeader("Content-Type"), "application/json")) {
+		return nil
+	}
+	return s.ValidateJSON(schema, body)
+}
+
+func (s *Spec) validateResponse(op *operation, recorder *responseRecorder) error {
+	status := recorder.Status()
+	if status == http.StatusNotModified {
+		return nil
+	}
+
+	media := mediaType(recorder.Header().Get("Content-Type"), "")
+	if media == problem.ContentType && status >= http.StatusBadRequest {
+		return s.ValidateJSON(s.problem, recorder.body.Bytes())
+	}
+	schemas, ok := op.responses[status]
+	if !ok {
+		return fmt.Errorf("undocumented status")
+	}
+	if recorder.Size() <= 0 {
+		if len(schemas) > 0 {
+			return fmt.Errorf("missing response body")
+		}
+		return nil
+	}
+	schema, ok := schemas[media]
+	if !ok {
+		return fmt.Errorf("undocumented media type %q", media)
+	}
+	if !isJSON(media) {
+		return nil
+	}
+	return s.ValidateJSON(schema, recorder.body.Bytes())
+}
+
+// responseRecorder guarda una copia de las respuestas JSON mientras las escribe
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	if w.recording() {
+		w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	if w.recording() {
+		w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Unwrap da acceso a la conexión a http.ResponseController, p. ej. para quitar
+// el plazo de escritura de los streams de eventos
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseRecorder) recording() bool {
+	return isJSON(mediaType(w.Header().Get("Content-Type"), ""))
+}
+
+// mediaType devuelve el tipo de contenido de una cabecera Content-Type, o
+// fallback si la cabecera está vacía
+func mediaType(contentType, fallback string) string {
+	if contentType == "" {
+		return fallback
+	}
+	media, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return media
+}
+
+// isJSON indica si un tipo de contenido es JSON, como application/json o
+// application/merge-patch+json
+func isJSON(media string) bool {
+	return media == "application/json" || strings.HasSuffix(media, "+json")
+}
@@ -0,0 +1,112 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestValidateReportsResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	spec := New(Info{Title: "Test", Version: "1"}, []Operation{
+		{Method: http.MethodGet, Path: "/items/:id", Response: Object{"id": "", "count": 0}},
+		{Method: http.MethodDelete, Path: "/items/:id", Status: http.StatusNoContent},
+	})
+
+	tests := []struct {
+		name    string
+		method  string
+		handler gin.HandlerFunc
+		want    string // Informe esperado; vacío si la respuesta coincide
+	}{
+		{
+			name:    "matching response",
+			handler: func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"id": "a", "count": 1}) },
+		},
+		{
+			name:    "no content",
+			method:  http.MethodDelete,
+			handler: func(c *gin.Context) { c.Status(http.StatusNoContent) },
+		},
+		{
+			name:    "wrong type",
+			handler: func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"id": 1, "count": 1}) },
+			want:    "id: must be a string",
+		},
+		{
+			name:    "missing required member",
+			handler: func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"id": "a"}) },
+			want:    "count",
+		},
+		{
+			name:    "undocumented status",
+			handler: func(c *gin.Context) { c.JSON(http.StatusCreated, gin.H{"id": "a", "count": 1}) },
+			want:    "undocumented status",
+		},
+		{
+			name:    "undocumented media type",
+			handler: func(c *gin.Context) { c.String(http.StatusOK, "a") },
+			want:    "undocumented media type",
+		},
+		{
+			name:    "missing body",
+			handler: func(c *gin.Context) { c.Status(http.StatusOK) },
+			want:    "missing response body",
+		},
+		{
+			name:    "error without a problem document",
+			handler: func(c *gin.Context) { c.JSON(http.StatusBadRequest, gin.H{"error": "bad"}) },
+			want:    "undocumented status",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reported error
+			r := gin.New()
+			r.Use(spec.ValidateWith(func(c *gin.Context, err error) { reported = err }))
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			r.Handle(method, "/items/:id", tt.handler)
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/items/1", nil))
+
+			switch {
+			case tt.want == "" && reported != nil:
+				t.Errorf("reported %v, want no report", reported)
+			case tt.want != "" && reported == nil:
+				t.Errorf("no report, want %q", tt.want)
+			case tt.want != "" && !strings.Contains(reported.Error(), tt.want):
+				t.Errorf("reported %v, want %q", reported, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSkipsEventStreams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	spec := New(Info{Title: "Test", Version: "1"}, []Operation{
+		{Method: http.MethodGet, Path: "/stream", ResponseMedia: map[string]interface{}{eventStream: String("")}},
+	})
+
+	var reported error
+	r := gin.New()
+	r.Use(spec.ValidateWith(func(c *gin.Context, err error) { reported = err }))
+	r.GET("/stream", func(c *gin.Context) {
+		if _, ok := c.Writer.(*responseRecorder); ok {
+			t.Error("the event stream response is recorded")
+		}
+		// Si se comprobara la respuesta, se informaría del estado no documentado
+		c.JSON(http.StatusAccepted, gin.H{})
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stream", nil))
+	if reported != nil {
+		t.Errorf("reported %v, want no report", reported)
+	}
+}
@@ -0,0 +1,307 @@
+// Package openapi describe la API como un documento OpenAPI 3 y comprueba la
+// API en marcha contra él.
+//
+// Este paquete solo sabe construir y comprobar un documento; no declara
+// operaciones. Las operaciones de esta API están en el paquete main
+// (routes.go), junto a setupRoutes, para que una ruta y su descripción cambien
+// a la vez.
+//
+// Las operaciones se declaran en Go con valores de ejemplo de los tipos de la
+// petición y la respuesta; sus esquemas se generan a partir de la codificación
+// JSON de los tipos, así que renombrar un campo cambia también el documento.
+// Después Spec sirve el documento y su página de documentación, informa de las
+// rutas sin operación (CheckRoutes) y valida peticiones y respuestas (Validate).
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"task-manager-backend/api/problem"
+)
+
+// Version es la versión de OpenAPI del documento generado
+const Version = "3.0.3"
+
+// eventStream es el tipo de contenido de los server-sent events, que Validate no comprueba
+const eventStream = "text/event-stream"
+
+// Operation declara una ruta. Body y Response son valores de ejemplo de los
+// cuerpos JSON de la petición y la respuesta (ver Object para los valores
+// aceptados).
+type Operation struct {
+	Method      string
+	Path        string // Ruta de gin, p. ej. /api/tasks/:id
+	Tag         string
+	Summary     string
+	Description string
+	Public      bool // No requiere un token bearer
+	Query       []Parameter
+
+	Body         interface{}            // Cuerpo JSON de la petición (nil = ninguno)
+	OptionalBody bool                   // El cuerpo de la petición puede omitirse
+	BodyMedia    map[string]interface{} // Cuerpos de la petición en otros tipos de contenido
+
+	Status        int                    // Estado de éxito (200 si es cero)
+	Response      interface{}            // Cuerpo JSON de la respuesta (nil = ninguno)
+	ResponseMedia map[string]interface{} // Cuerpos de la respuesta en otros tipos de contenido
+}
+
+// Parameter es un parámetro de la query string
+type Parameter struct {
+	Name        string
+	Description string
+	Required    bool
+	Schema      *Schema // Un string si es nil
+}
+
+// Info son los metadatos del documento
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Document es el documento OpenAPI
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Tags       []Tag                 `json:"tags,omitempty"`
+	Security   []map[string][]string `json:"security"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+}
+
+// Tag agrupa operaciones en la documentación
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// PathItem contiene las operaciones de una ruta, por método en minúsculas
+type PathItem map[string]*OperationObject
+
+// OperationObject es una operación del documento
+type OperationObject struct {
+	Tags        []string                  `json:"tags,omitempty"`
+	Summary     string                    `json:"summary,omitempty"`
+	Description string                    `json:"description,omitempty"`
+	Security    *[]map[string][]string    `json:"security,omitempty"`
+	Parameters  []ParameterObject         `json:"parameters,omitempty"`
+	RequestBody *RequestBody              `json:"requestBody,omitempty"`
+	Responses   map[string]ResponseObject `json:"responses"`
+}
+
+// ParameterObject es un parámetro de ruta o de query de una operación
+type ParameterObject struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody es el cuerpo de la petición de una operación
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// ResponseObject es una respuesta de una operación
+type ResponseObject struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType es el esquema de un cuerpo en un tipo de contenido
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components contiene los esquemas reutilizables y los esquemas de seguridad
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme es un método de autenticación
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Spec es un documento construido junto con lo necesario para validar contra él
+type Spec struct {
+	Document Document
+
+	raw        []byte
+	operations map[string]*operation // Por "MÉTODO ruta-de-gin"
+	problem    *Schema
+}
+
+// operation es la vista de validación de una Operation
+type operation struct {
+	query     []Parameter
+	body      map[string]*Schema         // Por tipo de contenido
+	responses map[int]map[string]*Schema // Por estado y tipo de contenido
+	streaming bool                       // La respuesta es un stream de eventos
+}
+
+// New construye el documento de las operaciones. Entra en pánico si una
+// operación se declara dos veces o uno de sus tipos no se puede describir, que
+// son errores de programación.
+func New(info Info, operations []Operation) *Spec {
+	g := newGenerator()
+
+	codes := make([]string, 0, len(problem.Codes()))
+	for code := range problem.Codes() {
+		codes = append(codes, string(code))
+	}
+	sort.Strings(codes)
+	g.overrides[reflect.TypeOf(problem.Code(""))] = Enum("Stable error code", codes...)
+	problemSchema := g.schema(reflect.TypeOf(problem.Problem{}), false)
+
+	spec := &Spec{
+		Document: Document{
+			OpenAPI:  Version,
+			Info:     info,
+			Security: []map[string][]string{{"bearerAuth": {}}},
+			Paths:    map[string]PathItem{},
+			Components: Components{
+				Schemas: g.components,
+				SecuritySchemes: map[string]SecurityScheme{
+					"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				},
+			},
+		},
+		operations: map[string]*operation{},
+		problem:    problemSchema,
+	}
+
+	tags := map[string]bool{}
+	for _, op := range operations {
+		key := op.Method + " " + op.Path
+		if _, exists := spec.operations[key]; exists {
+			panic("openapi: duplicate operation " + key)
+		}
+		if op.Tag != "" && !tags[op.Tag] {
+			tags[op.Tag] = true
+			spec.Document.Tags = append(spec.Document.Tags, Tag{Name: op.Tag})
+		}
+
+		object, validation := build(g, op, problemSchema)
+		path, _ := pathTemplate(op.Path)
+		if spec.Document.Paths[path] == nil {
+			spec.Document.Paths[path] = PathItem{}
+		}
+		spec.Document.Paths[path][strings.ToLower(op.Method)] = object
+		spec.operations[key] = validation
+	}
+
+	raw, err := json.MarshalIndent(spec.Document, "", "  ")
+	if err != nil {
+		panic(fmt.Sprintf("openapi: encoding the document: %v", err))
+	}
+	spec.raw = raw
+	return spec
+}
+
+// build devuelve el objeto del documento y la vista de validación de una operación
+func build(g *generator, op Operation, problemSchema *Schema) (*OperationObject, *operation) {
+	object := &OperationObject{
+		Summary:     op.Summary,
+		Description: op.Description,
+		Responses:   map[string]ResponseObject{},
+	}
+	validation := &operation{
+		query:     op.Query,
+		body:      map[string]*Schema{},
+		responses: map[int]map[string]*Schema{},
+	}
+	if op.Tag != "" {
+		object.Tags = []string{op.Tag}
+	}
+	if op.Public {
+		object.Security = &[]map[string][]string{}
+	}
+
+	_, params := pathTemplate(op.Path)
+	for _, name := range params {
+		object.Parameters = append(object.Parameters, ParameterObject{Name: name, In: "path", Required: true, Schema: String("")})
+	}
+	for _, param := range op.Query {
+		schema := param.Schema
+		if schema == nil {
+			schema = String("")
+		}
+		object.Parameters = append(object.Parameters, ParameterObject{
+			Name: param.Name, In: "query", Description: param.Description, Required: param.Required, Schema: schema,
+		})
+	}
+
+	if op.Body != nil {
+		validation.body["application/json"] = g.value(op.Body, true)
+	}
+	for media, body := range op.BodyMedia {
+		validation.body[media] = g.value(body, true)
+	}
+	if len(validation.body) > 0 {
+		object.RequestBody = &RequestBody{Required: !op.OptionalBody, Content: content(validation.body)}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]*Schema{}
+	if op.Response != nil {
+		success["application/json"] = g.value(op.Response, false)
+	}
+	for media, body := range op.ResponseMedia {
+		success[media] = g.value(body, false)
+	}
+	validation.responses[status] = success
+	_, validation.streaming = success[eventStream]
+	object.Responses[strconv.Itoa(status)] = ResponseObject{Description: http.StatusText(status), Content: content(success)}
+	object.Responses["default"] = ResponseObject{
+		Description: "Error",
+		Content:     map[string]MediaType{problem.ContentType: {Schema: problemSchema}},
+	}
+	return object, validation
+}
+
+func content(schemas map[string]*Schema) map[string]MediaType {
+	if len(schemas) == 0 {
+		return nil
+	}
+	media := make(map[string]MediaType, len(schemas))
+	for name, schema := range schemas {
+		media[name] = MediaType{Schema: schema}
+	}
+	return media
+}
+
+// pathTemplate convierte una ruta de gin en una plantilla de ruta de OpenAPI y
+// devuelve los nombres de sus parámetros: /api/tasks/:id pasa a ser
+// /api/tasks/{id}
+func pathTemplate(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			name := segment[1:]
+			params = append(params, name)
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// JSON devuelve el documento codificado en JSON
+func (s *Spec) JSON() []byte {
+	return s.raw
+}
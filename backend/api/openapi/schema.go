@@ -0,0 +1,253 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema es un objeto schema de OpenAPI 3.0. Solo se soportan las palabras
+// clave que usa la API, tanto al generar el documento como al validar.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// String devuelve un esquema de tipo string
+func String(description string) *Schema {
+	return &Schema{Type: "string", Description: description}
+}
+
+// Boolean devuelve un esquema de tipo boolean
+func Boolean(description string) *Schema {
+	return &Schema{Type: "boolean", Description: description}
+}
+
+// Integer devuelve un esquema de tipo integer
+func Integer(description string) *Schema {
+	return &Schema{Type: "integer", Description: description}
+}
+
+// Enum devuelve un esquema de tipo string limitado a values
+func Enum(description string, values ...string) *Schema {
+	return &Schema{Type: "string", Description: description, Enum: values}
+}
+
+// Any devuelve un esquema que acepta cualquier valor JSON
+func Any(description string) *Schema {
+	return &Schema{Description: description}
+}
+
+// Object describe un objeto JSON cuyos miembros se dan con valores de ejemplo:
+// valores de Go (descritos por su tipo), *Schema, otros Object u Optional para
+// los miembros que pueden faltar. El resto de miembros son obligatorios.
+type Object map[string]interface{}
+
+// Optional marca un miembro de un Object que no siempre está presente
+type Optional struct {
+	Value interface{}
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// generator construye esquemas a partir de tipos de Go y reúne los que tienen
+// nombre como componentes. Los structs se describen por su codificación JSON:
+// los esquemas de respuesta exigen todos los miembros sin omitempty, y los de
+// petición ("input") los campos con la etiqueta binding:"required", como gin.
+type generator struct {
+	components map[string]*Schema
+	types      map[string]reflect.Type
+	overrides  map[reflect.Type]*Schema
+}
+
+func newGenerator() *generator {
+	return &generator{
+		components: map[string]*Schema{},
+		types:      map[string]reflect.Type{},
+		overrides:  map[reflect.Type]*Schema{},
+	}
+}
+
+// value devuelve el esquema de un valor de ejemplo
+func (g *generator) value(v interface{}, input bool) *Schema {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case *Schema:
+		return v
+	case Object:
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for name, member := range v {
+			if optional, ok := member.(Optional); ok {
+				schema.Properties[name] = g.value(optional.Value, input)
+				continue
+			}
+			schema.Properties[name] = g.value(member, input)
+			schema.Required = append(schema.Required, name)
+		}
+		sort.Strings(schema.Required)
+		return schema
+	}
+	return g.schema(reflect.TypeOf(v), input)
+}
+
+// schema devuelve el esquema de un tipo de Go
+func (g *generator) schema(t reflect.Type, input bool) *Schema {
+	if override, ok := g.overrides[t]; ok {
+		return override
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "Duration in nanoseconds"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(g.schema(t.Elem(), input))
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t, input)
+		}
+		return &Schema{Ref: "#/components/schemas/" + g.component(t, input)}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem(), input), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem(), input), Nullable: true}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Interface:
+		return &Schema{}
+	}
+	panic(fmt.Sprintf("openapi: unsupported type %s", t))
+}
+
+// component registra un struct con nombre y devuelve su nombre de componente.
+// Los esquemas de entrada de los tipos que no son peticiones llevan el sufijo
+// "Input", porque exigen otros miembros que el esquema de respuesta del mismo
+// tipo.
+func (g *generator) component(t reflect.Type, input bool) string {
+	name := t.Name()
+	if input && !strings.HasSuffix(name, "Request") {
+		name += "Input"
+	}
+	if registered, ok := g.types[name]; ok {
+		if registered != t {
+			panic(fmt.Sprintf("openapi: schema name %s used by %s and %s", name, registered, t))
+		}
+		return name
+	}
+
+	// El nombre se registra antes de describir los campos para que los tipos
+	// recursivos, como GroupNode, se refieran a sí mismos
+	g.types[name] = t
+	g.components[name] = nil
+	g.components[name] = g.structSchema(t, input)
+	return name
+}
+
+func (g *generator) structSchema(t reflect.Type, input bool) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(schema, t, input)
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// addFields añade los miembros JSON de los campos de t con las reglas de
+// encoding/json: "-" omite un campo y los structs embebidos sin etiqueta se
+// aplanan
+func (g *generator) addFields(schema *Schema, t reflect.Type, input bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			g.addFields(schema, field.Type, input)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := g.schema(field.Type, input)
+		binding := strings.Split(field.Tag.Get("binding"), ",")
+		for _, rule := range binding {
+			if value, ok := strings.CutPrefix(rule, "min="); ok && property.Type != "" {
+				if minimum, err := strconv.ParseFloat(value, 64); err == nil {
+					copied := *property
+					copied.Minimum = &minimum
+					property = &copied
+				}
+			}
+		}
+		schema.Properties[name] = property
+
+		required := !strings.Contains(","+options+",", ",omitempty,")
+		if input {
+			required = contains(binding, "required")
+		}
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// nullable devuelve una copia de schema que también acepta null. Las
+// referencias se envuelven en allOf, porque se ignora lo que acompaña a $ref.
+func nullable(schema *Schema) *Schema {
+	if schema.Ref != "" {
+		return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+	}
+	copied := *schema
+	copied.Nullable = true
+	return &copied
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ValidationError es un valor que no coincide con su esquema. Path sitúa el
+// valor dentro del documento, p. ej. "tasks[2].due_date".
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidateJSON comprueba un documento JSON contra schema, resolviendo las
+// referencias con los componentes de la especificación. Informa de la primera
+// diferencia.
+func (s *Spec) ValidateJSON(schema *Schema, document []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return &ValidationError{Message: fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	return s.validate(schema, value, "")
+}
+
+func (s *Spec) validate(schema *Schema, value interface{}, path string) error {
+	if schema == nil {
+		return nil
+	}
+	if schema.Ref != "" {
+		target, ok := s.Document.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+		if !ok {
+			return &ValidationError{Path: path, Message: "unknown schema " + schema.Ref}
+		}
+		return s.validate(target, value, path)
+	}
+	if value == nil {
+		if schema.Nullable || (schema.Type == "" && len(schema.AllOf) == 0) {
+			return nil
+		}
+		return &ValidationError{Path: path, Message: "must not be null"}
+	}
+	for _, sub := range schema.AllOf {
+		if err := s.validate(sub, value, path); err != nil {
+			return err
+		}
+	}
+
+	switch schema.Type {
+	case "":
+		return nil
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return mismatch(path, "a string", value)
+		}
+		if len(schema.Enum) > 0 && !contains(schema.Enum, str) {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("must be one of %s", strings.Join(schema.Enum, ", "))}
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				return &ValidationError{Path: path, Message: "must be an RFC 3339 date-time"}
+			}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatch(path, "a boolean", value)
+		}
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		if !ok {
+			return mismatch(path, "a number", value)
+		}
+		if schema.Type == "integer" {
+			if _, err := number.Int64(); err != nil {
+				return mismatch(path, "an integer", value)
+			}
+		}
+		if schema.Minimum != nil {
+			if f, err := number.Float64(); err == nil && f < *schema.Minimum {
+				return &ValidationError{Path: path, Message: fmt.Sprintf("must be at least %v", *schema.Minimum)}
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return mismatch(path, "an array", value)
+		}
+		for i, item := range items {
+			if err := s.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		members, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch(path, "an object", value)
+		}
+		for _, name := range schema.Required {
+			if _, ok := members[name]; !ok {
+				return &ValidationError{Path: join(path, name), Message: "is required"}
+			}
+		}
+		// Se permiten miembros desconocidos, porque encoding/json los ignora
+		names := make([]string, 0, len(members))
+		for name := range members {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := schema.Properties[name]
+			if !ok {
+				property = schema.AdditionalProperties
+			}
+			if err := s.validate(property, members[name], join(path, name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func mismatch(path, expected string, value interface{}) error {
+	var actual string
+	switch value.(type) {
+	case string:
+		actual = "string"
+	case bool:
+		actual = "boolean"
+	case json.Number:
+		actual = "number"
+	case []interface{}:
+		actual = "array"
+	case map[string]interface{}:
+		actual = "object"
+	}
+	return &ValidationError{Path: path, Message: fmt.Sprintf("must be %s, not %s", expected, actual)}
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,282 @@
+// Package problem implementa el tipo de error de la API y su representación
+// según RFC 7807 (application/problem+json).
+//
+// Cada respuesta de error lleva un código estable que pueden leer los
+// programas. Los errores internos se registran con el ID de correlación de la
+// petición y al cliente solo le llega un mensaje genérico junto con ese ID.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"task-manager-backend/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType es el tipo de contenido de las respuestas de error
+const ContentType = "application/problem+json"
+
+// CorrelationIDKey es la clave del contexto de gin donde se guarda el ID de correlación
+const CorrelationIDKey = "correlation_id"
+
+// Code es un código de error estable pensado para los programas. Los clientes
+// pueden depender de él; el mensaje de detalle puede cambiar.
+type Code string
+
+const (
+	CodeBadRequest         Code = "bad_request"
+	CodeValidation         Code = "validation_failed"
+	CodeUnauthorized       Code = "unauthorized"
+	CodeInvalidToken       Code = "invalid_token"
+	CodeInvalidCredentials Code = "invalid_credentials"
+	CodeForbidden          Code = "forbidden"
+	CodeNotFound           Code = "not_found"
+	CodeConflict           Code = "conflict"
+	CodeGone               Code = "gone"
+	CodePreconditionFailed Code = "precondition_failed"
+	CodeUnsupportedMedia   Code = "unsupported_media_type"
+	CodeUnprocessable      Code = "unprocessable_entity"
+	CodeInternal           Code = "internal_error"
+
+	CodeGroupNotFound     Code = "group_not_found"
+	CodeUserNotFound      Code = "user_not_found"
+	CodeTaskNotFound      Code = "task_not_found"
+	CodeNotMember         Code = "not_member"
+	CodeGroupRole         Code = "group_role_forbidden"
+	CodeWorkspaceRole     Code = "workspace_role_forbidden"
+	CodeInvalidRole       Code = "invalid_role"
+	CodeInvalidGroup      Code = "invalid_group"
+	CodeInvalidWorkflow   Code = "invalid_workflow"
+	CodeInvalidTransition Code = "invalid_status_transition"
+	CodeGroupHierarchy    Code = "invalid_group_hierarchy"
+	CodeAlreadyMember     Code = "already_member"
+	CodeOwnerRole         Code = "owner_role"
+	CodeStatusInUse       Code = "status_in_use"
+	CodeInheritedMember   Code = "inherited_member"
+	CodeHasSubgroups      Code = "has_subgroups"
+	CodeLastAdmin         Code = "last_admin"
+	CodeInviteInvalid     Code = "invite_invalid"
+	CodeInviteClosed      Code = "invite_closed"
+)
+
+// statusByCode es el estado HTTP de cada código
+var statusByCode = map[Code]int{
+	CodeBadRequest:         http.StatusBadRequest,
+	CodeValidation:         http.StatusBadRequest,
+	CodeUnauthorized:       http.StatusUnauthorized,
+	CodeInvalidToken:       http.StatusUnauthorized,
+	CodeInvalidCredentials: http.StatusUnauthorized,
+	CodeForbidden:          http.StatusForbidden,
+	CodeNotFound:           http.StatusNotFound,
+	CodeConflict:           http.StatusConflict,
+	CodeGone:               http.StatusGone,
+	CodePreconditionFailed: http.StatusPreconditionFailed,
+	CodeUnsupportedMedia:   http.StatusUnsupportedMediaType,
+	CodeUnprocessable:      http.StatusUnprocessableEntity,
+	CodeInternal:           http.StatusInternalServerError,
+
+	CodeGroupNotFound:     http.StatusNotFound,
+	CodeUserNotFound:      http.StatusNotFound,
+	CodeTaskNotFound:      http.StatusNotFound,
+	CodeNotMember:         http.StatusNotFound,
+	CodeGroupRole:         http.StatusForbidden,
+	CodeWorkspaceRole:     http.StatusForbidden,
+	CodeInvalidRole:       http.StatusBadRequest,
+	CodeInvalidGroup:      http.StatusBadRequest,
+	CodeInvalidWorkflow:   http.StatusBadRequest,
+	CodeInvalidTransition: http.StatusUnprocessableEntity,
+	CodeGroupHierarchy:    http.StatusBadRequest,
+	CodeAlreadyMember:     http.StatusConflict,
+	CodeOwnerRole:         http.StatusConflict,
+	CodeStatusInUse:       http.StatusConflict,
+	CodeInheritedMember:   http.StatusConflict,
+	CodeHasSubgroups:      http.StatusConflict,
+	CodeLastAdmin:         http.StatusConflict,
+	CodeInviteInvalid:     http.StatusBadRequest,
+	CodeInviteClosed:      http.StatusGone,
+}
+
+// Status devuelve el estado HTTP del código
+func (c Code) Status() int {
+	if status, ok := statusByCode[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Codes devuelve todos los códigos definidos, p. ej. para documentarlos
+func Codes() map[Code]int {
+	codes := make(map[Code]int, len(statusByCode))
+	for code, status := range statusByCode {
+		codes[code] = status
+	}
+	return codes
+}
+
+// Error es un error de la API. Detail se muestra al cliente; Err es la causa
+// interna, que se registra pero nunca se envía.
+type Error struct {
+	Code       Code
+	Detail     string
+	Err        error
+	Extensions map[string]interface{} // Miembros adicionales del cuerpo del error
+
+	format string        // Formato del detalle de los errores creados con Newf, para traducirlos
+	args   []interface{} // Argumentos de format
+}
+
+// New devuelve un error con el código y el detalle para el cliente indicados
+func New(code Code, detail string) *Error {
+	return &Error{Code: code, Detail: detail}
+}
+
+// Newf es New con un detalle con formato. Lo que se traduce es el formato, no
+// el detalle ya formateado (ver Message).
+func Newf(code Code, format string, args ...interface{}) *Error {
+	e := New(code, fmt.Sprintf(format, args...))
+	if len(args) > 0 {
+		e.format = format
+		e.args = args
+	}
+	return e
+}
+
+// Internal devuelve un error interno. detail es un mensaje genérico que se
+// puede mostrar al cliente; err es la causa que se registra.
+func Internal(detail string, err error) *Error {
+	return &Error{Code: CodeInternal, Detail: detail, Err: err}
+}
+
+// With devuelve una copia del error con un miembro más en el cuerpo del error
+func (e *Error) With(key string, value interface{}) *Error {
+	copied := *e
+	copied.Extensions = make(map[string]interface{}, len(e.Extensions)+1)
+	for k, v := range e.Extensions {
+		copied.Extensions[k] = v
+	}
+	copied.Extensions[key] = value
+	return &copied
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Detail, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Detail)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status devuelve el estado HTTP del error
+func (e *Error) Status() int {
+	return e.Code.Status()
+}
+
+// Message devuelve el detalle traducido a locale
+func (e *Error) Message(locale i18n.Locale) string {
+	if e.format != "" {
+		return i18n.T(locale, e.format, e.args...)
+	}
+	return i18n.T(locale, e.Detail)
+}
+
+// Title devuelve el título de code traducido a locale. Los códigos sin entrada
+// en el catálogo usan el texto de su estado HTTP.
+func Title(locale i18n.Locale, code Code) string {
+	if title, ok := i18n.Lookup(locale, "problem."+string(code)); ok {
+		return title
+	}
+	return i18n.T(locale, http.StatusText(code.Status()))
+}
+
+// Problem es el cuerpo RFC 7807 de una respuesta de error. Error repite Detail
+// para los clientes escritos antes de que existieran estas respuestas.
+type Problem struct {
+	Type          string `json:"type"`
+	Title         string `json:"title"`
+	Status        int    `json:"status"`
+	Detail        string `json:"detail,omitempty"`
+	Instance      string `json:"instance,omitempty"`
+	Code          Code   `json:"code"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+	Error         string `json:"error,omitempty"`
+
+	Extensions map[string]interface{} `json:"-"` // Miembros adicionales; no pueden sustituir a los estándar
+}
+
+// MarshalJSON escribe los miembros estándar seguidos de las extensiones
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type standard Problem
+	body, err := json.Marshal(standard(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return body, err
+	}
+
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(body, &members); err != nil {
+		return nil, err
+	}
+	for key, value := range p.Extensions {
+		if _, exists := members[key]; exists {
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		members[key] = raw
+	}
+	return json.Marshal(members)
+}
+
+// TypeURI devuelve la URI del tipo de error de un código
+func TypeURI(code Code) string {
+	return "urn:task-manager:problem:" + string(code)
+}
+
+// From convierte cualquier error en un error de la API. Los que no son *Error
+// pasan a ser errores internos con un detalle genérico.
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return Internal("", err)
+}
+
+// Respond escribe err como respuesta de error y aborta la petición. El título y
+// el detalle se traducen al idioma de la petición (ver i18n.ContextKey). Los
+// errores internos se registran con el ID de correlación y su causa no se envía.
+func Respond(c *gin.Context, err error) {
+	apiErr := From(err)
+	status := apiErr.Status()
+	correlationID := c.GetString(CorrelationIDKey)
+	locale := i18n.Of(c.GetString(i18n.ContextKey))
+
+	detail := apiErr.Message(locale)
+	if status >= http.StatusInternalServerError {
+		log.Printf("[%s] %s %s: %v", correlationID, c.Request.Method, c.Request.URL.Path, apiErr)
+		if apiErr.Detail == "" {
+			detail = i18n.T(locale, "Internal server error")
+		}
+	}
+
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(status, Problem{
+		Type:          TypeURI(apiErr.Code),
+		Title:         Title(locale, apiErr.Code),
+		Status:        status,
+		Detail:        detail,
+		Instance:      c.Request.URL.Path,
+		Code:          apiErr.Code,
+		CorrelationID: correlationID,
+		Error:         detail,
+		Extensions:    apiErr.Extensions,
+	})
+}
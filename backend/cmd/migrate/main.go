@@ -0,0 +1,115 @@
+// Command migrate aplica migraciones de datos sobre Firestore.
+//
+// Uso:
+//
+//	go run ./cmd/migrate [-dry-run] <migración>...
+//
+// Sin argumentos muestra las migraciones disponibles. Todas se pueden ejecutar
+// varias veces sin efectos adicionales.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"task-manager-backend/config"
+	"task-manager-backend/internal/database"
+)
+
+// migration es una migración de datos. run devuelve el número de documentos
+// que modificó o, con dryRun, que modificaría.
+type migration struct {
+	name        string
+	description string
+	run         func(ctx context.Context, dryRun bool) (int, error)
+}
+
+var migrations = []migration{
+	{
+		name:        "prune-user-groups",
+		description: "Remove deleted groups and groups the user no longer belongs to from the groups array of user documents",
+		run:         pruneUserGroups,
+	},
+	{
+		name:        "backfill-workspaces",
+		description: "Create the default workspace and assign it to users, tasks, groups, group invitations and webhooks that have none; webhooks get their owner's workspace",
+		run:         backfillWorkspaces,
+	},
+	{
+		name:        "backfill-users",
+		description: "Fill in missing fields of user documents (id, role, created_at, workspace) and set their schema version",
+		run:         backfillUsers,
+	},
+	{
+		name:        "backfill-ranks",
+		description: "Give a board position to tasks that have none, at the end of their column",
+		run:         backfillRanks,
+	},
+}
+
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	dryRun := flag.Bool("dry-run", false, "report the changes without writing them")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	var selected []migration
+	for _, name := range flag.Args() {
+		m, ok := findMigration(name)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown migration %q\n\n", name)
+			usage()
+			os.Exit(2)
+		}
+		selected = append(selected, m)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := database.InitFirestore(ctx, cfg.Firebase.ProjectID, cfg.Firebase.CredentialsPath); err != nil {
+		log.Fatalf("Failed to initialize Firestore: %v", err)
+	}
+	defer database.Close()
+
+	for _, m := range selected {
+		changed, err := m.run(ctx, *dryRun)
+		if err != nil {
+			log.Fatalf("Migration %s failed: %v", m.name, err)
+		}
+		if *dryRun {
+			log.Printf("%s: %d documents would change", m.name, changed)
+		} else {
+			log.Printf("%s: %d documents changed", m.name, changed)
+		}
+	}
+}
+
+func findMigration(name string) (migration, bool) {
+	for _, m := range migrations {
+		if m.name == name {
+			return m, true
+		}
+	}
+	return migration{}, false
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: migrate [-dry-run] <migration>...\n\nMigrations:\n")
+	for _, m := range migrations {
+		fmt.Fprintf(os.Stderr, "  %-20s %s\n", m.name, m.description)
+	}
+	fmt.Fprintln(os.Stderr)
+	flag.PrintDefaults()
+}
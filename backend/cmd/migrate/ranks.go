@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"log"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/rank"
+
+	"cloud.google.com/go/firestore"
+)
+
+// backfillRanks da posición en el tablero a las tareas que no la tienen: las
+// anteriores al tablero y las que cambiaron de grupo. En cada columna con alguna
+// tarea sin posición se reparten de nuevo las claves con rank.Spread, manteniendo
+// el orden del tablero (las tareas sin posición van al final, por fecha de creación).
+func backfillRanks(ctx context.Context, dryRun bool) (int, error) {
+	docs, err := database.Client.Collection("tasks").Documents(ctx).GetAll()
+	if err != nil {
+		return 0, err
+	}
+
+	// Una columna es un estado de un grupo o, en las tareas personales, de un usuario
+	columns := make(map[string][]models.Task)
+	unranked := make(map[string]bool)
+	for _, doc := range docs {
+		var task models.Task
+		if err := doc.DataTo(&task); err != nil {
+			log.Printf("Skipping task %s: %v", doc.Ref.ID, err)
+			continue
+		}
+		task.ID = doc.Ref.ID
+
+		key := models.WorkspaceOf(task.WorkspaceID) + "/user/" + task.UserID
+		if task.GroupID != nil && *task.GroupID != "" {
+			key = models.WorkspaceOf(task.WorkspaceID) + "/group/" + *task.GroupID
+		}
+		key += "/" + task.Status
+
+		columns[key] = append(columns[key], task)
+		if task.Rank == "" {
+			unranked[key] = true
+		}
+	}
+
+	writer := database.Client.BulkWriter(ctx)
+	var jobs []*firestore.BulkWriterJob
+	changed := 0
+
+	for key := range unranked {
+		tasks := columns[key]
+		models.SortByRank(tasks)
+		keys := rank.Spread(len(tasks))
+
+		for i, task := range tasks {
+			if task.Rank == keys[i] {
+				continue
+			}
+			changed++
+			if dryRun {
+				continue
+			}
+
+			job, err := writer.Update(database.Client.Collection("tasks").Doc(task.ID), []firestore.Update{
+				{Path: "rank", Value: keys[i]},
+			})
+			if err != nil {
+				log.Printf("Error updating task %s: %v", task.ID, err)
+				continue
+			}
+			jobs = append(jobs, job)
+		}
+	}
+	writer.End()
+
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			log.Printf("Error backfilling task rank: %v", err)
+			changed--
+		}
+	}
+
+	return changed, nil
+}
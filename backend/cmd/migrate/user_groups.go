@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"log"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+)
+
+// pruneUserGroups deja en el array groups de cada usuario solo los grupos que
+// existen y de los que sigue siendo miembro. Hasta ahora al eliminar un grupo o
+// salir de él no siempre se actualizaba el usuario.
+func pruneUserGroups(ctx context.Context, dryRun bool) (int, error) {
+	groupDocs, err := database.Client.Collection("groups").Documents(ctx).GetAll()
+	if err != nil {
+		return 0, err
+	}
+
+	members := make(map[string]map[string]bool, len(groupDocs))
+	for _, doc := range groupDocs {
+		var group models.Group
+		if err := doc.DataTo(&group); err != nil {
+			log.Printf("Skipping group %s: %v", doc.Ref.ID, err)
+			continue
+		}
+		members[doc.Ref.ID] = make(map[string]bool, len(group.Members))
+		for _, memberID := range group.Members {
+			members[doc.Ref.ID][memberID] = true
+		}
+	}
+
+	userDocs, err := database.Client.Collection("users").Documents(ctx).GetAll()
+	if err != nil {
+		return 0, err
+	}
+
+	writer := database.Client.BulkWriter(ctx)
+	var jobs []*firestore.BulkWriterJob
+	changed := 0
+
+	for _, doc := range userDocs {
+		groups, ok := doc.Data()["groups"].([]interface{})
+		if !ok {
+			continue
+		}
+
+		var stale []interface{}
+		for _, value := range groups {
+			groupID, _ := value.(string)
+			if !members[groupID][doc.Ref.ID] {
+				stale = append(stale, value)
+			}
+		}
+		if len(stale) == 0 {
+			continue
+		}
+
+		changed++
+		if dryRun {
+			continue
+		}
+
+		job, err := writer.Update(doc.Ref, []firestore.Update{
+			{Path: "groups", Value: firestore.ArrayRemove(stale...)},
+		})
+		if err != nil {
+			log.Printf("Error updating user %s: %v", doc.Ref.ID, err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	writer.End()
+
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			log.Printf("Error updating user groups: %v", err)
+			changed--
+		}
+	}
+
+	return changed, nil
+}
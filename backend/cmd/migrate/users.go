@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"log"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/models"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// backfillUsers completa los campos que faltan en los documentos de usuario y los
+// deja en la versión models.UserSchemaVersion. Los usuarios antiguos pueden no
+// tener id, role ni created_at; como fecha de alta se usa la de creación del
+// documento. Los campos de tipo incorrecto solo se registran, sin modificarlos.
+func backfillUsers(ctx context.Context, dryRun bool) (int, error) {
+	docs, err := database.Client.Collection("users").Documents(ctx).GetAll()
+	if err != nil {
+		return 0, err
+	}
+
+	writer := database.Client.BulkWriter(ctx)
+	var jobs []*firestore.BulkWriterJob
+	changed := 0
+
+	for _, doc := range docs {
+		data := doc.Data()
+		if version, _ := data["schema_version"].(int64); version >= models.UserSchemaVersion {
+			continue
+		}
+
+		var updates []firestore.Update
+		// missing indica si falta un campo de texto o está vacío
+		missing := func(field string) bool {
+			switch value := data[field].(type) {
+			case nil:
+				return true
+			case string:
+				return value == ""
+			default:
+				log.Printf("User %s: field %s has type %T, expected string", doc.Ref.ID, field, value)
+				return false
+			}
+		}
+
+		if missing("id") {
+			updates = append(updates, firestore.Update{Path: "id", Value: doc.Ref.ID})
+		}
+		if missing("role") {
+			updates = append(updates, firestore.Update{Path: "role", Value: models.DefaultUserRole})
+		}
+		if _, ok := data["created_at"].(time.Time); !ok {
+			if data["created_at"] != nil {
+				log.Printf("User %s: field created_at has type %T, replacing it", doc.Ref.ID, data["created_at"])
+			}
+			updates = append(updates, firestore.Update{Path: "created_at", Value: doc.CreateTime})
+		}
+		if missing("workspace_id") {
+			updates = append(updates, firestore.Update{Path: "workspace_id", Value: models.DefaultWorkspaceID})
+		}
+		if missing("workspace_role") {
+			role := models.WorkspaceRoleMember
+			if appRole, _ := data["role"].(string); appRole == "admin" {
+				role = models.WorkspaceRoleAdmin
+			}
+			updates = append(updates, firestore.Update{Path: "workspace_role", Value: role})
+		}
+		updates = append(updates, firestore.Update{Path: "schema_version", Value: models.UserSchemaVersion})
+
+		changed++
+		if dryRun {
+			continue
+		}
+
+		job, err := writer.Update(doc.Ref, updates)
+		if err != nil {
+			log.Printf("Error updating user %s: %v", doc.Ref.ID, err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	writer.End()
+
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			log.Printf("Error backfilling user: %v", err)
+			changed--
+		}
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"log"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/models"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// backfillWorkspaces crea el espacio de trabajo por defecto y se lo asigna a los
+// documentos anteriores a los espacios de trabajo. Las consultas filtran por
+// workspace_id, así que sin esta migración esos documentos no aparecen en ellas:
+// el servidor avisa al arrancar mientras queden (ver database.CheckWorkspaces).
+// Los administradores de la aplicación pasan a administrar el espacio de trabajo.
+// Los webhooks reciben el espacio de trabajo de su propietario, que puede no ser el
+// de por defecto si se crearon después de los espacios de trabajo.
+func backfillWorkspaces(ctx context.Context, dryRun bool) (int, error) {
+	changed := 0
+
+	workspaceRef := database.Client.Collection("workspaces").Doc(models.DefaultWorkspaceID)
+	if _, err := workspaceRef.Get(ctx); err != nil {
+		if status.Code(err) != codes.NotFound {
+			return 0, err
+		}
+		changed++
+		if !dryRun {
+			now := time.Now()
+			if _, err := workspaceRef.Set(ctx, models.Workspace{
+				ID:        models.DefaultWorkspaceID,
+				Name:      "Default",
+				CreatedAt: now,
+				UpdatedAt: now,
+			}); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	writer := database.Client.BulkWriter(ctx)
+	var jobs []*firestore.BulkWriterJob
+	userWorkspaces := map[string]string{}
+
+	for _, collection := range database.WorkspaceCollections {
+		docs, err := database.Client.Collection(collection).Documents(ctx).GetAll()
+		if err != nil {
+			writer.End()
+			return 0, err
+		}
+
+		for _, doc := range docs {
+			data := doc.Data()
+
+			// Los usuarios se recorren antes que los webhooks (ver WorkspaceCollections)
+			workspaceID, _ := data["workspace_id"].(string)
+			if collection == "users" {
+				userWorkspaces[doc.Ref.ID] = models.WorkspaceOf(workspaceID)
+			}
+
+			var updates []firestore.Update
+			if workspaceID == "" {
+				workspaceID = models.DefaultWorkspaceID
+				if ownerID, _ := data["owner_id"].(string); collection == "webhooks" && userWorkspaces[ownerID] != "" {
+					workspaceID = userWorkspaces[ownerID]
+				}
+				updates = append(updates, firestore.Update{Path: "workspace_id", Value: workspaceID})
+			}
+			if collection == "users" {
+				if role, _ := data["workspace_role"].(string); role == "" {
+					role = models.WorkspaceRoleMember
+					if appRole, _ := data["role"].(string); appRole == "admin" {
+						role = models.WorkspaceRoleAdmin
+					}
+					updates = append(updates, firestore.Update{Path: "workspace_role", Value: role})
+				}
+			}
+			if len(updates) == 0 {
+				continue
+			}
+
+			changed++
+			if dryRun {
+				continue
+			}
+
+			job, err := writer.Update(doc.Ref, updates)
+			if err != nil {
+				log.Printf("Error updating %s/%s: %v", collection, doc.Ref.ID, err)
+				continue
+			}
+			jobs = append(jobs, job)
+		}
+	}
+	writer.End()
+
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			log.Printf("Error backfilling workspace: %v", err)
+			changed--
+		}
+	}
+
+	return changed, nil
+}
@@ -17,6 +17,19 @@ type Config struct {
 		Port           string
 		AllowedOrigins []string
 		Environment    string
+		AppURL         string // Dirección del frontend, para los enlaces de los correos
+		ValidateAPI    bool   // Valida peticiones y respuestas contra la especificación OpenAPI (solo en desarrollo)
+	}
+	Webhooks struct {
+		AllowPrivateNetworks bool // Permite entregar webhooks a direcciones de la red local (desarrollo)
+	}
+	Mail struct {
+		Backend  string // log, file o smtp
+		From     string
+		Dir      string // Carpeta de los correos del backend file
+		SMTPAddr string
+		Username string
+		Password string
 	}
 }
 
@@ -48,6 +61,20 @@ func LoadConfig() (*Config, error) {
 	config.Server.Port = getEnvWithDefault("PORT", "8080")
 	config.Server.Environment = getEnvWithDefault("GIN_MODE", "debug")
 	config.Server.AllowedOrigins = []string{"*"}
+	config.Server.AppURL = getEnvWithDefault("APP_URL", "https://taskman-lac.vercel.app")
+	// Solo para desarrollo: desactivada por defecto e ignorada en producción
+	config.Server.ValidateAPI = getEnvWithDefault("OPENAPI_VALIDATE", "false") == "true" && config.Server.Environment != "release"
+
+	// Webhooks configuration
+	config.Webhooks.AllowPrivateNetworks = getEnvWithDefault("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "false") == "true"
+
+	// Mail configuration
+	config.Mail.Backend = getEnvWithDefault("MAILER", "log")
+	config.Mail.From = getEnvWithDefault("MAIL_FROM", "Task Manager <no-reply@taskman.local>")
+	config.Mail.Dir = getEnvWithDefault("MAIL_DIR", "mail")
+	config.Mail.SMTPAddr = os.Getenv("SMTP_ADDR")
+	config.Mail.Username = os.Getenv("SMTP_USERNAME")
+	config.Mail.Password = os.Getenv("SMTP_PASSWORD")
 
 	return config, nil
 }
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxBatchGet is the number of documents requested in each batched read
+	maxBatchGet = 100
+	// fallbackConcurrency bounds the concurrent single-document reads used when a batched read fails
+	fallbackConcurrency = 8
+)
+
+// GetAll reads the documents in batches of maxBatchGet and returns them in the
+// same order as refs. Missing documents are returned with Exists() == false. If
+// a batch fails, its documents are read one by one with at most
+// fallbackConcurrency reads in flight.
+func GetAll(ctx context.Context, refs []*firestore.DocumentRef) ([]*firestore.DocumentSnapshot, error) {
+	docs := make([]*firestore.DocumentSnapshot, 0, len(refs))
+
+	for start := 0; start < len(refs); start += maxBatchGet {
+		end := start + maxBatchGet
+		if end > len(refs) {
+			end = len(refs)
+		}
+
+		batch, err := Client.GetAll(ctx, refs[start:end])
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			log.Printf("Batched read failed, reading %d documents one by one: %v", end-start, err)
+			if batch, err = getEach(ctx, refs[start:end]); err != nil {
+				return nil, err
+			}
+		}
+		docs = append(docs, batch...)
+	}
+
+	return docs, nil
+}
+
+// getEach reads the documents individually and concurrently
+func getEach(ctx context.Context, refs []*firestore.DocumentRef) ([]*firestore.DocumentSnapshot, error) {
+	docs := make([]*firestore.DocumentSnapshot, len(refs))
+	errs := make([]error, len(refs))
+
+	sem := make(chan struct{}, fallbackConcurrency)
+	var wg sync.WaitGroup
+	for i, ref := range refs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, ref *firestore.DocumentRef) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			doc, err := ref.Get(ctx)
+			// A missing document comes back as a NotFound error with a snapshot that does not exist
+			if err != nil && !(status.Code(err) == codes.NotFound && doc != nil) {
+				errs[i] = err
+				return
+			}
+			docs[i] = doc
+		}(i, ref)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
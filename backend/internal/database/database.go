@@ -63,13 +63,16 @@ func testConnection(ctx context.Context) error {
 	return nil
 }
 
+// initDocID is the ID of the temporary document written by InitializeCollections
+const initDocID = "_init"
+
 // InitializeCollections ensures required collections exist
 func InitializeCollections(ctx context.Context) error {
 	collections := []string{"users", "tasks"}
 
 	for _, colName := range collections {
 		// Create a temporary document to initialize the collection
-		tempDoc := Client.Collection(colName).Doc("_init")
+		tempDoc := Client.Collection(colName).Doc(initDocID)
 
 		_, err := tempDoc.Set(ctx, map[string]interface{}{
 			"initialized": true,
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"task-manager-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WorkspaceCollections are the collections whose documents carry a workspace_id.
+// List queries filter on it, so documents without one are missing from them.
+var WorkspaceCollections = []string{"users", "tasks", "groups", "group_invitations", "webhooks"}
+
+// CheckWorkspaces returns an error if a document in WorkspaceCollections has no
+// workspace_id, which means the backfill-workspaces migration has not been run.
+// Single-document reads treat a missing workspace_id as the default workspace,
+// but list queries can only match stored values, so those documents are missing
+// from lists until the data is migrated. The sentinel documents written by
+// InitializeCollections are not counted.
+func CheckWorkspaces(ctx context.Context) error {
+	missing := map[string]int64{}
+	for _, collection := range WorkspaceCollections {
+		// Firestore cannot query a missing field: count all documents and the
+		// ones with a non-empty workspace_id
+		all, err := count(ctx, Client.Collection(collection).Query)
+		if err != nil {
+			return fmt.Errorf("counting %s: %v", collection, err)
+		}
+		scoped, err := count(ctx, Client.Collection(collection).Where("workspace_id", ">", ""))
+		if err != nil {
+			return fmt.Errorf("counting %s: %v", collection, err)
+		}
+		sentinel, err := Client.Collection(collection).Doc(initDocID).Get(ctx)
+		if err == nil {
+			if workspaceID, _ := sentinel.Data()["workspace_id"].(string); workspaceID == "" {
+				all--
+			}
+		} else if status.Code(err) != codes.NotFound {
+			return fmt.Errorf("reading %s/%s: %v", collection, initDocID, err)
+		}
+		if all > scoped {
+			missing[collection] = all - scoped
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+	details := make([]string, 0, len(missing))
+	for collection, n := range missing {
+		details = append(details, fmt.Sprintf("%s: %d", collection, n))
+	}
+	sort.Strings(details)
+	return fmt.Errorf("documents without workspace_id (%s) are treated as the default workspace but missing from lists; run `go run ./cmd/migrate backfill-workspaces`",
+		strings.Join(details, ", "))
+}
+
+// count returns the number of documents matched by a query
+func count(ctx context.Context, query firestore.Query) (int64, error) {
+	result, err := query.NewAggregationQuery().WithCount("count").Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	value, ok := result["count"].(*firestorepb.Value)
+	if !ok {
+		return 0, fmt.Errorf("unexpected count result %T", result["count"])
+	}
+	return value.GetIntegerValue(), nil
+}
+
+// InWorkspace filters a query to the documents of a workspace. An empty ID means
+// the default workspace, as in models.WorkspaceOf, so list queries and
+// single-document checks agree on which workspace a document belongs to. Every
+// query on WorkspaceCollections must go through it.
+func InWorkspace(query firestore.Query, workspaceID string) firestore.Query {
+	return query.Where("workspace_id", "==", models.WorkspaceOf(workspaceID))
+}
@@ -0,0 +1,172 @@
+// Package events es el bus de eventos dentro del proceso con el que se envían
+// los cambios a los clientes conectados y a los procesos en segundo plano.
+//
+// Los handlers publican eventos con la función Publish del paquete, que los
+// pasa al Bus configurado. El bus por defecto es un buffer circular en memoria;
+// SetBus lo sustituye por otra implementación (p. ej. una basada en un broker
+// de mensajes cuando hay varias instancias).
+package events
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"task-manager-backend/internal/models"
+	"time"
+)
+
+// Tipos de evento
+const (
+	TaskCreated        = "task.created"
+	TaskUpdated        = "task.updated"
+	TaskDeleted        = "task.deleted"
+	GroupCreated       = "group.created"
+	GroupUpdated       = "group.updated"
+	GroupDeleted       = "group.deleted"
+	GroupMemberAdded   = "group.member_added"
+	GroupMemberRemoved = "group.member_removed"
+
+	GroupInvitationCreated = "group.invitation_created"
+
+	NotificationCreated = "notification.created"
+)
+
+// ErrHistoryUnavailable lo devuelve Subscribe cuando el evento pedido ya no se
+// conserva, así que el suscriptor no puede continuar sin perder eventos
+var ErrHistoryUnavailable = errors.New("event history unavailable")
+
+// Event es el aviso de un cambio. El bus asigna el ID al publicarlo.
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	Data      interface{} `json:"data"`
+	Audience  []string    `json:"-"` // IDs de los usuarios que pueden recibir el evento
+	ActorID   string      `json:"actor_id,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// TaskPayload son los datos de los eventos de tareas
+type TaskPayload struct {
+	Task           *models.Task `json:"task"`
+	PreviousStatus string       `json:"previous_status,omitempty"` // Estado anterior a un evento task.updated
+	Previous       *models.Task `json:"-"`                         // Tarea anterior a un evento task.updated, para los consumidores del proceso
+}
+
+// GroupPayload son los datos de los eventos de grupos
+type GroupPayload struct {
+	Group    *models.Group `json:"group"`
+	MemberID string        `json:"member_id,omitempty"` // Miembro añadido o eliminado
+}
+
+// InvitationPayload son los datos de los eventos de invitaciones
+type InvitationPayload struct {
+	Invitation *models.GroupInvitation `json:"invitation"`
+}
+
+// VisibleTo indica si el usuario es uno de los destinatarios del evento
+func (e Event) VisibleTo(userID string) bool {
+	for _, id := range e.Audience {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription entrega los eventos que acepta un suscriptor. El canal se cierra
+// al cerrar la suscripción o cuando el suscriptor se queda demasiado atrás; en
+// ese caso puede volver a suscribirse desde el último evento que recibió.
+type Subscription interface {
+	Events() <-chan Event
+	Close()
+}
+
+// Bus publica eventos y los reparte entre los suscriptores
+type Bus interface {
+	// Publish asigna un ID al evento y lo entrega a los suscriptores que lo aceptan
+	Publish(event Event) Event
+	// Subscribe registra un suscriptor. Si lastEventID no está vacío, primero se
+	// entregan los eventos conservados publicados después de él. Con match nil
+	// se reciben todos los eventos.
+	Subscribe(lastEventID string, match func(Event) bool) (Subscription, error)
+}
+
+var (
+	mu  sync.RWMutex
+	bus Bus = NewMemoryBus(DefaultHistorySize)
+)
+
+// SetBus sustituye el bus que usan Publish y Subscribe
+func SetBus(b Bus) {
+	mu.Lock()
+	defer mu.Unlock()
+	bus = b
+}
+
+// Default devuelve el bus configurado
+func Default() Bus {
+	mu.RLock()
+	defer mu.RUnlock()
+	return bus
+}
+
+// Publish publica un evento en el bus configurado
+func Publish(event Event) Event {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	return Default().Publish(event)
+}
+
+// Subscribe se suscribe al bus configurado
+func Subscribe(lastEventID string, match func(Event) bool) (Subscription, error) {
+	return Default().Subscribe(lastEventID, match)
+}
+
+// Consume llama a handle con cada evento aceptado hasta que ctx termina. Los
+// eventos se procesan de uno en uno en la goroutine que llama. Si la suscripción
+// se descarta porque handle se quedó atrás, Consume vuelve a suscribirse desde
+// el último evento procesado; los que ya no se conservan se pierden y se
+// registran.
+func Consume(ctx context.Context, match func(Event) bool, handle func(Event)) {
+	lastEventID := ""
+	for {
+		sub, err := Subscribe(lastEventID, match)
+		if errors.Is(err, ErrHistoryUnavailable) {
+			log.Printf("Events after %s are no longer available; resuming from the latest event", lastEventID)
+			sub, err = Subscribe("", match)
+		}
+		if err != nil {
+			log.Printf("Error subscribing to events: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+				continue
+			}
+		}
+
+		if !drain(ctx, sub, handle, &lastEventID) {
+			return
+		}
+	}
+}
+
+// drain procesa los eventos de una suscripción. Devuelve false cuando ctx
+// termina y true cuando se descartó la suscripción.
+func drain(ctx context.Context, sub Subscription, handle func(Event), lastEventID *string) bool {
+	defer sub.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case event, ok := <-sub.Events():
+			if !ok {
+				return true
+			}
+			handle(event)
+			*lastEventID = event.ID
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package events
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHistorySize es el número de eventos que conserva el bus por defecto para poder continuar
+const DefaultHistorySize = 1024
+
+// subscriberBuffer es el número de eventos sin entregar que puede acumular un suscriptor
+const subscriberBuffer = 64
+
+// MemoryBus es un Bus que guarda los últimos eventos en un buffer circular. Los
+// IDs de los eventos son "<época>-<secuencia>", donde la época identifica la
+// instancia del bus, así que los IDs emitidos antes de un reinicio se detectan y
+// se tratan como no disponibles.
+type MemoryBus struct {
+	mu          sync.Mutex
+	epoch       string
+	seq         uint64
+	history     []Event // Buffer circular
+	next        int     // Posición de la siguiente escritura en history
+	size        int     // Número de eventos conservados
+	subscribers map[*memorySubscription]struct{}
+}
+
+// NewMemoryBus crea un bus que conserva hasta historySize eventos
+func NewMemoryBus(historySize int) *MemoryBus {
+	if historySize < 1 {
+		historySize = 1
+	}
+	return &MemoryBus{
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		history:     make([]Event, historySize),
+		subscribers: make(map[*memorySubscription]struct{}),
+	}
+}
+
+// Publish implementa Bus
+func (b *MemoryBus) Publish(event Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	event.ID = b.epoch + "-" + strconv.FormatUint(b.seq, 10)
+
+	b.history[b.next] = event
+	b.next = (b.next + 1) % len(b.history)
+	if b.size < len(b.history) {
+		b.size++
+	}
+
+	for sub := range b.subscribers {
+		sub.deliver(event)
+	}
+
+	return event
+}
+
+// Subscribe implementa Bus
+func (b *MemoryBus) Subscribe(lastEventID string, match func(Event) bool) (Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replay []Event
+	if lastEventID != "" {
+		seq, ok := b.parseID(lastEventID)
+		oldest := b.seq - uint64(b.size) // Secuencia justo anterior al evento conservado más antiguo
+		if !ok || seq > b.seq || seq < oldest {
+			return nil, ErrHistoryUnavailable
+		}
+		for i := b.size - int(b.seq-seq); i < b.size; i++ {
+			event := b.history[(b.next-b.size+i+len(b.history))%len(b.history)]
+			if match == nil || match(event) {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	sub := &memorySubscription{
+		bus:    b,
+		match:  match,
+		events: make(chan Event, subscriberBuffer+len(replay)),
+	}
+	for _, event := range replay {
+		sub.events <- event
+	}
+	b.subscribers[sub] = struct{}{}
+
+	return sub, nil
+}
+
+// parseID devuelve la secuencia de un ID emitido por este bus
+func (b *MemoryBus) parseID(id string) (uint64, bool) {
+	epoch, seq, ok := strings.Cut(id, "-")
+	if !ok || epoch != b.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	return n, err == nil
+}
+
+func (b *MemoryBus) remove(sub *memorySubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sub.closeLocked()
+}
+
+type memorySubscription struct {
+	bus    *MemoryBus
+	match  func(Event) bool
+	events chan Event
+	closed bool
+}
+
+func (s *memorySubscription) Events() <-chan Event {
+	return s.events
+}
+
+func (s *memorySubscription) Close() {
+	s.bus.remove(s)
+}
+
+// deliver encola un evento sin bloquear a quien publica. Un suscriptor con la
+// cola llena se descarta; continúa desde su último evento al volver a
+// suscribirse. Se llama con el bloqueo del bus adquirido.
+func (s *memorySubscription) deliver(event Event) {
+	if s.match != nil && !s.match(event) {
+		return
+	}
+	select {
+	case s.events <- event:
+	default:
+		s.closeLocked()
+	}
+}
+
+// closeLocked cierra la suscripción. Se llama con el bloqueo del bus adquirido.
+func (s *memorySubscription) closeLocked() {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	delete(s.bus.subscribers, s)
+	close(s.events)
+}
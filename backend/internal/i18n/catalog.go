@@ -0,0 +1,98 @@
+package i18n
+
+import "strings"
+
+// catalog guarda todas las traducciones, indexadas por clave e idioma. Se
+// construye a partir de keyed (mensajes con clave estable, en todos los idiomas)
+// y spanish (mensajes cuya clave es su texto en inglés).
+var catalog = buildCatalog()
+
+func buildCatalog() map[string]map[Locale]string {
+	messages := make(map[string]map[Locale]string, len(keyed)+len(spanish))
+	for key, translations := range keyed {
+		messages[key] = translations
+	}
+	for english, translation := range spanish {
+		if _, ok := messages[english]; !ok {
+			messages[english] = map[Locale]string{English: english}
+		}
+		messages[english][Spanish] = translation
+	}
+	return messages
+}
+
+// keyed son los mensajes identificados por una clave estable: los títulos de
+// los códigos de error ("problem.<code>"), los tipos de notificación
+// ("notification.<type>"), los asuntos de los emails ("email.<name>.subject"),
+// las frecuencias del resumen ("digest.<frequency>") y los motivos de los
+// mensajes del stream de eventos ("stream.<event>").
+var keyed = map[string]map[Locale]string{
+	// Títulos de los códigos de error
+	"problem.bad_request":               {English: "Bad Request", Spanish: "Solicitud incorrecta"},
+	"problem.validation_failed":         {English: "Validation Failed", Spanish: "Datos no válidos"},
+	"problem.unauthorized":              {English: "Unauthorized", Spanish: "No autenticado"},
+	"problem.invalid_token":             {English: "Invalid Token", Spanish: "Token no válido"},
+	"problem.invalid_credentials":       {English: "Invalid Credentials", Spanish: "Credenciales no válidas"},
+	"problem.forbidden":                 {English: "Forbidden", Spanish: "Acceso denegado"},
+	"problem.not_found":                 {English: "Not Found", Spanish: "No encontrado"},
+	"problem.conflict":                  {English: "Conflict", Spanish: "Conflicto"},
+	"problem.gone":                      {English: "Gone", Spanish: "Ya no disponible"},
+	"problem.precondition_failed":       {English: "Precondition Failed", Spanish: "Versión desactualizada"},
+	"problem.unsupported_media_type":    {English: "Unsupported Media Type", Spanish: "Formato no admitido"},
+	"problem.unprocessable_entity":      {English: "Unprocessable Entity", Spanish: "Entidad no procesable"},
+	"problem.internal_error":            {English: "Internal Server Error", Spanish: "Error interno del servidor"},
+	"problem.group_not_found":           {English: "Group Not Found", Spanish: "Grupo no encontrado"},
+	"problem.user_not_found":            {English: "User Not Found", Spanish: "Usuario no encontrado"},
+	"problem.task_not_found":            {English: "Task Not Found", Spanish: "Tarea no encontrada"},
+	"problem.not_member":                {English: "Not a Member", Spanish: "No es miembro"},
+	"problem.group_role_forbidden":      {English: "Group Role Forbidden", Spanish: "Rol insuficiente en el grupo"},
+	"problem.workspace_role_forbidden":  {English: "Workspace Role Forbidden", Spanish: "Rol insuficiente en el espacio de trabajo"},
+	"problem.invalid_role":              {English: "Invalid Role", Spanish: "Rol no válido"},
+	"problem.invalid_group":             {English: "Invalid Group", Spanish: "Grupo no válido"},
+	"problem.invalid_workflow":          {English: "Invalid Workflow", Spanish: "Flujo de trabajo no válido"},
+	"problem.invalid_status_transition": {English: "Invalid Status Transition", Spanish: "Cambio de estado no permitido"},
+	"problem.invalid_group_hierarchy":   {English: "Invalid Group Hierarchy", Spanish: "Jerarquía de grupos no válida"},
+	"problem.already_member":            {English: "Already a Member", Spanish: "Ya es miembro"},
+	"problem.owner_role":                {English: "Owner Role", Spanish: "Rol de propietario"},
+	"problem.status_in_use":             {English: "Status In Use", Spanish: "Estado en uso"},
+	"problem.inherited_member":          {English: "Inherited Member", Spanish: "Miembro heredado"},
+	"problem.has_subgroups":             {English: "Group Has Subgroups", Spanish: "El grupo tiene subgrupos"},
+	"problem.last_admin":                {English: "Last Admin", Spanish: "Último administrador"},
+	"problem.invite_invalid":            {English: "Invalid Invite", Spanish: "Invitación no válida"},
+	"problem.invite_closed":             {English: "Invite Closed", Spanish: "Invitación cerrada"},
+
+	// Títulos de las notificaciones, por tipo
+	"notification.assignment":   {English: "You were assigned to \"%s\"", Spanish: "Se te ha asignado \"%s\""},
+	"notification.mention":      {English: "You were mentioned in \"%s\"", Spanish: "Te han mencionado en \"%s\""},
+	"notification.group_invite": {English: "You were invited to the group \"%s\"", Spanish: "Te han invitado al grupo \"%s\""},
+	"notification.due_soon":     {English: "\"%s\" is due %s", Spanish: "\"%s\" vence el %s"},
+	"notification.comment":      {English: "New comment on \"%s\"", Spanish: "Nuevo comentario en \"%s\""},
+
+	// Asuntos de los emails
+	"email.invitation.subject":       {English: "%s invited you to %s", Spanish: "%s te ha invitado a %s"},
+	"email.workspace_invite.subject": {English: "%s invited you to the %s workspace", Spanish: "%s te ha invitado al espacio de trabajo %s"},
+	"email.digest.subject":           {English: "Your %s task summary for %s", Spanish: "Tu resumen %s de tareas del %s"},
+
+	// Frecuencias del resumen
+	"digest.daily":  {English: "daily", Spanish: "diario"},
+	"digest.weekly": {English: "weekly", Spanish: "semanal"},
+
+	// Mensajes del stream de eventos
+	"stream.reset": {English: "Missed events are no longer available", Spanish: "Los eventos perdidos ya no están disponibles"},
+}
+
+// timeNames sustituye los nombres en inglés de los meses y los días de una
+// fecha con formato. Los nombres completos van primero para que tengan
+// prioridad sobre sus abreviaturas.
+var timeNames = map[Locale]*strings.Replacer{
+	Spanish: strings.NewReplacer(
+		"January", "enero", "February", "febrero", "March", "marzo", "April", "abril",
+		"May", "mayo", "June", "junio", "July", "julio", "August", "agosto",
+		"September", "septiembre", "October", "octubre", "November", "noviembre", "December", "diciembre",
+		"Monday", "lunes", "Tuesday", "martes", "Wednesday", "miércoles", "Thursday", "jueves",
+		"Friday", "viernes", "Saturday", "sábado", "Sunday", "domingo",
+		"Jan", "ene", "Feb", "feb", "Mar", "mar", "Apr", "abr", "Jun", "jun", "Jul", "jul",
+		"Aug", "ago", "Sep", "sept", "Oct", "oct", "Nov", "nov", "Dec", "dic",
+		"Mon", "lun", "Tue", "mar", "Wed", "mié", "Thu", "jue", "Fri", "vie", "Sat", "sáb", "Sun", "dom",
+	),
+}
@@ -0,0 +1,138 @@
+// Package i18n traduce los mensajes que el backend envía a los usuarios: las
+// respuestas de la API, las notificaciones y los emails.
+//
+// Los mensajes se buscan en un catálogo. Los que tienen clave (códigos de error,
+// tipos de notificación, asuntos de email) usan una clave estable como
+// "notification.assignment"; el resto usa su texto en inglés como clave, así que
+// solo necesita una entrada para los demás idiomas. Las traducciones que faltan
+// se sustituyen por el inglés.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale es un idioma soportado, identificado por su código ISO 639-1
+type Locale string
+
+const (
+	English Locale = "en"
+	Spanish Locale = "es"
+)
+
+// Default es el idioma que se usa cuando no se soporta ninguno de los pedidos
+const Default = English
+
+// ContextKey es la clave del contexto de gin donde se guarda el idioma de la petición
+const ContextKey = "locale"
+
+// Supported devuelve los idiomas soportados
+func Supported() []Locale {
+	return []Locale{English, Spanish}
+}
+
+// Parse devuelve el idioma soportado de una etiqueta como "es", "es-MX" o
+// "en_US". Solo se tiene en cuenta la subetiqueta del idioma principal.
+func Parse(tag string) (Locale, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	for _, locale := range Supported() {
+		if Locale(tag) == locale {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// Of devuelve el idioma soportado de tag, o Default si no se soporta
+func Of(tag string) Locale {
+	if locale, ok := Parse(tag); ok {
+		return locale
+	}
+	return Default
+}
+
+// Negotiate elige el idioma soportado con mayor calidad de una cabecera
+// Accept-Language. En caso de empate se respeta el orden de la cabecera; "*" y
+// los idiomas no soportados se ignoran.
+func Negotiate(acceptLanguage string) Locale {
+	type candidate struct {
+		locale  Locale
+		quality float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		params := strings.Split(part, ";")
+		locale, ok := Parse(params[0])
+		if !ok {
+			continue
+		}
+		quality := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{locale, quality})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	if len(candidates) == 0 {
+		return Default
+	}
+	return candidates[0].locale
+}
+
+// Lookup devuelve la traducción de key en locale, o la inglesa si no la hay
+func Lookup(locale Locale, key string) (string, bool) {
+	translations, ok := catalog[key]
+	if !ok {
+		return "", false
+	}
+	if message, ok := translations[locale]; ok {
+		return message, true
+	}
+	message, ok := translations[English]
+	return message, ok
+}
+
+// T traduce key a locale. Si hay args la traducción se usa como formato de fmt.
+// Las claves sin traducción se devuelven tal cual, así que los mensajes en
+// inglés pueden usarse como su propia clave.
+func T(locale Locale, key string, args ...interface{}) string {
+	message, ok := Lookup(locale, key)
+	if !ok {
+		message = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// FormatTime da formato a t con un layout en inglés traducido a locale. El
+// layout es una clave del catálogo, así que cada idioma puede reordenar sus
+// partes, y los nombres de los meses y de los días se sustituyen por los del
+// idioma.
+func FormatTime(locale Locale, t time.Time, layout string) string {
+	formatted := t.Format(T(locale, layout))
+	names, ok := timeNames[locale]
+	if !ok {
+		return formatted
+	}
+	return names.Replace(formatted)
+}
@@ -0,0 +1,287 @@
+package i18n
+
+// spanish traduce los mensajes cuya clave es su texto en inglés: los detalles
+// de error y los mensajes de la API, los errores de los servicios y los layouts
+// de fecha que usa FormatTime. Los formatos mantienen los verbos del mensaje en
+// inglés en el mismo orden.
+var spanish = map[string]string{
+	// Formatos de fecha
+	"Monday, January 2":             "Monday, 2 de January",
+	"January 2":                     "2 de January",
+	"January 2, 2006":               "2 de January de 2006",
+	"Jan 2 15:04 MST":               "2 Jan 15:04 MST",
+	"Mon, 02 Jan 2006 15:04:05 MST": "Mon 02 Jan 2006 15:04:05 MST",
+
+	// Errores genéricos y autenticación
+	"Internal server error":                            "Error interno del servidor",
+	"Resource not found":                               "Recurso no encontrado",
+	"Database error":                                   "Error de base de datos",
+	"Authorization header is required":                 "La cabecera Authorization es obligatoria",
+	"Authorization header or access_token is required": "La cabecera Authorization o el parámetro access_token es obligatorio",
+	"Invalid token":                                    "Token no válido",
+	"Invalid token claims":                             "El token no tiene el formato esperado",
+	"Invalid credentials":                              "Credenciales no válidas",
+	"User ID not found in context":                     "No se encontró el usuario de la sesión",
+	"The resource was modified by someone else; reload it and try again": "Otra persona modificó el recurso; vuelve a cargarlo e inténtalo de nuevo",
+	"Error reading request body":                                         "Error al leer el cuerpo de la petición",
+	"Error generating token":                                             "Error al generar el token",
+	"Error hashing password":                                             "Error al cifrar la contraseña",
+	"Request does not match the API specification: %v":                   "La petición no cumple la especificación de la API: %v",
+
+	// Usuarios y espacios de trabajo
+	"A Task Manager user":                                 "Un usuario de Task Manager",
+	"Username already exists":                             "El nombre de usuario ya existe",
+	"User not found":                                      "Usuario no encontrado",
+	"User created successfully":                           "Usuario creado correctamente",
+	"No users found":                                      "No se encontraron usuarios",
+	"Email is required":                                   "El correo electrónico es obligatorio",
+	"Invalid email":                                       "Correo electrónico no válido",
+	"Invalid email address":                               "Dirección de correo no válida",
+	"Unsupported locale":                                  "Idioma no admitido",
+	"Language updated successfully":                       "Idioma actualizado correctamente",
+	"Use either invite_token or workspace_name, not both": "Indica invite_token o workspace_name, no ambos",
+	"Invalid or expired invite":                           "Invitación no válida o caducada",
+	"The invite was already used":                         "La invitación ya se utilizó",
+	"Only workspace admins can do this":                   "Solo los administradores del espacio de trabajo pueden hacer esto",
+	"Workspace invite not found":                          "Invitación al espacio de trabajo no encontrada",
+	"Workspace invite revoked successfully":               "Invitación al espacio de trabajo revocada correctamente",
+	"All members must belong to the workspace":            "Todos los miembros deben pertenecer al espacio de trabajo",
+	"Error creating user":                                 "Error al crear el usuario",
+	"Error fetching user":                                 "Error al obtener el usuario",
+	"Error fetching users":                                "Error al obtener los usuarios",
+	"Error searching user":                                "Error al buscar el usuario",
+	"Error updating user":                                 "Error al actualizar el usuario",
+	"Error fetching workspace":                            "Error al obtener el espacio de trabajo",
+	"Error updating workspace":                            "Error al actualizar el espacio de trabajo",
+	"Error fetching workspace members":                    "Error al obtener los miembros del espacio de trabajo",
+	"Error fetching workspace role":                       "Error al obtener el rol en el espacio de trabajo",
+	"Error updating workspace role":                       "Error al actualizar el rol en el espacio de trabajo",
+	"Error fetching workspace invites":                    "Error al obtener las invitaciones al espacio de trabajo",
+	"Error creating workspace invite":                     "Error al crear la invitación al espacio de trabajo",
+	"Error revoking workspace invite":                     "Error al revocar la invitación al espacio de trabajo",
+
+	// Grupos, miembros e invitaciones
+	"Group not found":                                               "Grupo no encontrado",
+	"Invalid group data":                                            "Datos del grupo no válidos",
+	"Invalid workflow":                                              "Flujo de trabajo no válido",
+	"You are not a member of this group":                            "No eres miembro de este grupo",
+	"Your role in this group does not allow this action":            "Tu rol en este grupo no permite esta acción",
+	"Provide either user_id or email":                               "Indica user_id o email",
+	"Member removed successfully":                                   "Miembro eliminado correctamente",
+	"Group deleted successfully":                                    "Grupo eliminado correctamente",
+	"You left the group":                                            "Has abandonado el grupo",
+	"Joined group successfully":                                     "Te has unido al grupo correctamente",
+	"Invitation not found":                                          "Invitación no encontrada",
+	"Invitation revoked successfully":                               "Invitación revocada correctamente",
+	"Invite link not found":                                         "Enlace de invitación no encontrado",
+	"Invite link revoked successfully":                              "Enlace de invitación revocado correctamente",
+	"You don't have permission to invite members to this group":     "No tienes permiso para invitar miembros a este grupo",
+	"You don't have permission to manage the members of this group": "No tienes permiso para gestionar los miembros de este grupo",
+	"You don't have permission to revoke this invitation":           "No tienes permiso para revocar esta invitación",
+	"You don't have permission to revoke this invite link":          "No tienes permiso para revocar este enlace de invitación",
+	"Error fetching group":                                          "Error al obtener el grupo",
+	"Error fetching group tasks":                                    "Error al obtener las tareas del grupo",
+	"Error joining group":                                           "Error al unirse al grupo",
+	"Error creating invitation":                                     "Error al crear la invitación",
+	"Error fetching invitation":                                     "Error al obtener la invitación",
+	"Error fetching invitations":                                    "Error al obtener las invitaciones",
+	"Error responding to invitation":                                "Error al responder a la invitación",
+	"Error revoking invitation":                                     "Error al revocar la invitación",
+	"Error creating invite link":                                    "Error al crear el enlace de invitación",
+	"Error fetching invite link":                                    "Error al obtener el enlace de invitación",
+	"Error fetching invite links":                                   "Error al obtener los enlaces de invitación",
+	"Error revoking invite link":                                    "Error al revocar el enlace de invitación",
+
+	// Tareas, tablero y operaciones en bloque
+	"Task not found":                                                   "Tarea no encontrada",
+	"Invalid task data":                                                "Datos de la tarea no válidos",
+	"Task updated successfully":                                        "Tarea actualizada correctamente",
+	"Task deleted successfully":                                        "Tarea eliminada correctamente",
+	"Task moved successfully":                                          "Tarea movida correctamente",
+	"User is not authorized to access this task":                       "No tienes acceso a esta tarea",
+	"Unauthorized to modify this task":                                 "No tienes permiso para modificar esta tarea",
+	"Unauthorized to delete this task":                                 "No tienes permiso para eliminar esta tarea",
+	"Only the owner can reassign this task":                            "Solo el propietario puede reasignar esta tarea",
+	"Collaborators must be members of the group":                       "Los colaboradores deben ser miembros del grupo",
+	"The assigned user is not a member of the group":                   "El usuario asignado no es miembro del grupo",
+	"The assigned user and collaborators must belong to the workspace": "El usuario asignado y los colaboradores deben pertenecer al espacio de trabajo",
+	"Transition from %s to %s is not allowed":                          "No se permite pasar del estado %s a %s",
+	"Field %s cannot be modified":                                      "El campo %s no se puede modificar",
+	"Collaborators cannot modify field %s":                             "Los colaboradores no pueden modificar el campo %s",
+	"Invalid value in patched task: %v":                                "Valor no válido en la tarea modificada: %v",
+	"Unsupported patch format %s":                                      "Formato de parche no admitido: %s",
+	"Invalid patch document":                                           "Documento de parche no válido",
+	"Invalid JSON Patch document":                                      "Documento JSON Patch no válido",
+	"Invalid JSON Merge Patch document":                                "Documento JSON Merge Patch no válido",
+	"Merge patch must be a JSON object":                                "El parche debe ser un objeto JSON",
+	"Patched task must be a JSON object":                               "La tarea modificada debe ser un objeto JSON",
+	"A task cannot be placed next to itself":                           "Una tarea no puede colocarse junto a sí misma",
+	"Neighbor task not found":                                          "Tarea vecina no encontrada",
+	"Neighbor task is not in the target column":                        "La tarea vecina no está en la columna de destino",
+	"Neighbor task has no position yet; reload the board":              "La tarea vecina aún no tiene posición; vuelve a cargar el tablero",
+	"The board changed; reload it and try again":                       "El tablero cambió; vuelve a cargarlo e inténtalo de nuevo",
+	"invalid priority filter: %s":                                      "filtro de prioridad no válido: %s",
+	"invalid sort field: %s":                                           "campo de orden no válido: %s",
+	"action must be one of status, labels, assign or delete":           "action debe ser status, labels, assign o delete",
+	"status is required for the status action":                         "status es obligatorio para la acción status",
+	"add_labels or remove_labels is required for the labels action":    "add_labels o remove_labels es obligatorio para la acción labels",
+	"task_ids must contain between 1 and 500 IDs":                      "task_ids debe contener entre 1 y 500 IDs",
+	"tasks must be archive, reassign or delete":                        "tasks debe ser archive, reassign o delete",
+	"Error creating task":                                              "Error al crear la tarea",
+	"Error fetching task":                                              "Error al obtener la tarea",
+	"Error fetching task by ID":                                        "Error al obtener la tarea",
+	"Error fetching tasks":                                             "Error al obtener las tareas",
+	"Error fetching parent task":                                       "Error al obtener la tarea principal",
+	"Error fetching task workflow":                                     "Error al obtener el flujo de trabajo de la tarea",
+	"Error updating task":                                              "Error al actualizar la tarea",
+	"Error deleting task":                                              "Error al eliminar la tarea",
+	"Error moving task":                                                "Error al mover la tarea",
+
+	// Importación y exportación
+	"format must be json or csv":                        "format debe ser json o csv",
+	"The import must contain between 1 and 1000 tasks":  "La importación debe contener entre 1 y 1000 tareas",
+	"Content-Type must be application/json or text/csv": "Content-Type debe ser application/json o text/csv",
+	"invalid JSON document: %v":                         "documento JSON no válido: %v",
+	"invalid CSV document: %v":                          "documento CSV no válido: %v",
+	"invalid CSV document: missing header":              "documento CSV no válido: falta la cabecera",
+	"invalid CSV document: missing title column":        "documento CSV no válido: falta la columna title",
+	"invalid remind_me on line %d":                      "remind_me no válido en la línea %d",
+	"invalid due_date on line %d":                       "due_date no válido en la línea %d",
+	"title is required":                                 "el título es obligatorio",
+	"duplicate id: %s":                                  "id duplicado: %s",
+	"unknown status: %s":                                "estado desconocido: %s",
+	"invalid priority: %s":                              "prioridad no válida: %s",
+	"invalid recurrence: %s":                            "recurrencia no válida: %s",
+	"invalid estimated_effort: %s":                      "estimated_effort no válido: %s",
+	"invalid time_until_finish: %s":                     "time_until_finish no válido: %s",
+	"unknown user: %s":                                  "usuario desconocido: %s",
+	"unknown label: %s":                                 "etiqueta desconocida: %s",
+	"parent task not found in import: %s":               "tarea principal no encontrada en la importación: %s",
+
+	// Plantillas
+	"Template not found":                                          "Plantilla no encontrada",
+	"Invalid template data":                                       "Datos de la plantilla no válidos",
+	"Template deleted successfully":                               "Plantilla eliminada correctamente",
+	"User is not authorized to access this template":              "No tienes acceso a esta plantilla",
+	"User is not authorized to use this template":                 "No tienes permiso para usar esta plantilla",
+	"Unauthorized to modify this template":                        "No tienes permiso para modificar esta plantilla",
+	"Unauthorized to delete this template":                        "No tienes permiso para eliminar esta plantilla",
+	"You don't have permission to create templates in this group": "No tienes permiso para crear plantillas en este grupo",
+	"Error creating template":                                     "Error al crear la plantilla",
+	"Error fetching template":                                     "Error al obtener la plantilla",
+	"Error fetching templates":                                    "Error al obtener las plantillas",
+	"Error updating template":                                     "Error al actualizar la plantilla",
+	"Error deleting template":                                     "Error al eliminar la plantilla",
+	"Error creating task from template":                           "Error al crear la tarea a partir de la plantilla",
+	"Error preparing template variables":                          "Error al preparar las variables de la plantilla",
+
+	// Etiquetas
+	"Label not found":            "Etiqueta no encontrada",
+	"Invalid label data":         "Datos de la etiqueta no válidos",
+	"Label deleted successfully": "Etiqueta eliminada correctamente",
+	"You don't have permission to create labels in this group": "No tienes permiso para crear etiquetas en este grupo",
+	"You don't have permission to modify this label":           "No tienes permiso para modificar esta etiqueta",
+	"You don't have permission to delete this label":           "No tienes permiso para eliminar esta etiqueta",
+	"Error fetching labels":                                    "Error al obtener las etiquetas",
+	"Error managing labels":                                    "Error al gestionar las etiquetas",
+
+	// Registro de tiempo
+	"Time entry not found":                                            "Registro de tiempo no encontrado",
+	"Invalid time entry data":                                         "Datos del registro de tiempo no válidos",
+	"Time entry deleted successfully":                                 "Registro de tiempo eliminado correctamente",
+	"Unauthorized to delete this time entry":                          "No tienes permiso para eliminar este registro de tiempo",
+	"Either ended_at or duration is required":                         "Indica ended_at o duration",
+	"You already have a running timer":                                "Ya tienes un temporizador en marcha",
+	"No timer is running":                                             "No hay ningún temporizador en marcha",
+	"You can only see the time of other users within a task or group": "Solo puedes ver el tiempo de otros usuarios dentro de una tarea o un grupo",
+	"group_by must be one of task, user, group or date":               "group_by debe ser task, user, group o date",
+	"Invalid from date":                                               "Fecha from no válida",
+	"Invalid to date":                                                 "Fecha to no válida",
+	"Error creating time entry":                                       "Error al crear el registro de tiempo",
+	"Error fetching time entry":                                       "Error al obtener el registro de tiempo",
+	"Error fetching time entries":                                     "Error al obtener los registros de tiempo",
+	"Error deleting time entry":                                       "Error al eliminar el registro de tiempo",
+	"Error summarizing time entries":                                  "Error al resumir los registros de tiempo",
+	"Error fetching active timer":                                     "Error al obtener el temporizador activo",
+	"Error starting timer":                                            "Error al iniciar el temporizador",
+	"Error stopping timer":                                            "Error al detener el temporizador",
+
+	// Notificaciones, resumen y calendario
+	"Notification not found":                  "Notificación no encontrada",
+	"Unknown notification type: %s":           "Tipo de notificación desconocido: %s",
+	"Invalid digest settings":                 "Configuración del resumen no válida",
+	"Invalid time zone":                       "Zona horaria no válida",
+	"type must be todo or event":              "type debe ser todo o event",
+	"Calendar feed not found":                 "Calendario no encontrado",
+	"Calendar feed revoked successfully":      "Calendario revocado correctamente",
+	"No calendar feed is active":              "No hay ningún calendario activo",
+	"Error fetching notifications":            "Error al obtener las notificaciones",
+	"Error counting unread notifications":     "Error al contar las notificaciones sin leer",
+	"Error updating notification":             "Error al actualizar la notificación",
+	"Error updating notifications":            "Error al actualizar las notificaciones",
+	"Error fetching notification preferences": "Error al obtener las preferencias de notificación",
+	"Error saving notification preferences":   "Error al guardar las preferencias de notificación",
+	"Error fetching digest settings":          "Error al obtener la configuración del resumen",
+	"Error saving digest settings":            "Error al guardar la configuración del resumen",
+	"Error building digest":                   "Error al generar el resumen",
+	"Error rendering digest":                  "Error al componer el resumen",
+	"Error fetching calendar feed":            "Error al obtener el calendario",
+	"Error generating calendar feed":          "Error al generar el calendario",
+	"Error revoking calendar feed":            "Error al revocar el calendario",
+	"Error opening event stream":              "Error al abrir el flujo de eventos",
+
+	// Webhooks
+	"Webhook not found":                                              "Webhook no encontrado",
+	"Webhook delivery not found":                                     "Entrega del webhook no encontrada",
+	"Invalid webhook data":                                           "Datos del webhook no válidos",
+	"Webhook deleted successfully":                                   "Webhook eliminado correctamente",
+	"The webhook is disabled":                                        "El webhook está desactivado",
+	"Unauthorized to manage this webhook":                            "No tienes permiso para gestionar este webhook",
+	"You don't have permission to manage the webhooks of this group": "No tienes permiso para gestionar los webhooks de este grupo",
+	"Error creating webhook":                                         "Error al crear el webhook",
+	"Error fetching webhook":                                         "Error al obtener el webhook",
+	"Error fetching webhooks":                                        "Error al obtener los webhooks",
+	"Error updating webhook":                                         "Error al actualizar el webhook",
+	"Error deleting webhook":                                         "Error al eliminar el webhook",
+	"Error fetching webhook deliveries":                              "Error al obtener las entregas del webhook",
+	"Error fetching webhook delivery":                                "Error al obtener la entrega del webhook",
+	"Error redelivering webhook":                                     "Error al reenviar el webhook",
+
+	// Errores de los servicios
+	"group not found":                                                            "grupo no encontrado",
+	"user not found":                                                             "usuario no encontrado",
+	"invalid group data":                                                         "datos del grupo no válidos",
+	"invalid role":                                                               "rol no válido",
+	"invalid workspace data":                                                     "datos del espacio de trabajo no válidos",
+	"user is not a member of this group":                                         "el usuario no es miembro de este grupo",
+	"user is already a member of this group":                                     "el usuario ya es miembro de este grupo",
+	"you don't have permission to do this in this group":                         "no tienes permiso para hacer esto en este grupo",
+	"a group cannot be moved into itself or one of its subgroups":                "un grupo no puede moverse dentro de sí mismo ni de uno de sus subgrupos",
+	"the group hierarchy would be too deep":                                      "la jerarquía de grupos sería demasiado profunda",
+	"the group has subgroups; move or delete them first":                         "el grupo tiene subgrupos; muévelos o elimínalos primero",
+	"the owner cannot be removed or change role; transfer ownership first":       "el propietario no puede salir ni cambiar de rol; transfiere antes la propiedad",
+	"the user is a member of a parent group; remove them there":                  "el usuario es miembro de un grupo superior; elimínalo allí",
+	"some tasks of this group use a status that is not part of the new workflow": "algunas tareas del grupo usan un estado que no forma parte del nuevo flujo de trabajo",
+	"the workspace must keep at least one admin":                                 "el espacio de trabajo debe conservar al menos un administrador",
+	"a user with this email already belongs to the workspace":                    "ya hay un usuario con este correo en el espacio de trabajo",
+	"this email already has a pending invite to the workspace":                   "este correo ya tiene una invitación pendiente al espacio de trabajo",
+	"workspace invite not found":                                                 "invitación al espacio de trabajo no encontrada",
+	"workspace invite is no longer valid":                                        "la invitación al espacio de trabajo ya no es válida",
+	"invitation not found":                                                       "invitación no encontrada",
+	"invitation is no longer pending":                                            "la invitación ya no está pendiente",
+	"user already has a pending invitation to this group":                        "el usuario ya tiene una invitación pendiente a este grupo",
+	"invite link not found":                                                      "enlace de invitación no encontrado",
+	"invite link is no longer valid":                                             "el enlace de invitación ya no es válido",
+	"notification not found":                                                     "notificación no encontrada",
+	"calendar feed not found":                                                    "calendario no encontrado",
+	"time entry not found":                                                       "registro de tiempo no encontrado",
+	"a timer is already running":                                                 "ya hay un temporizador en marcha",
+	"no timer is running":                                                        "no hay ningún temporizador en marcha",
+	"template not found":                                                         "plantilla no encontrada",
+	"label not found":                                                            "etiqueta no encontrada",
+	"a label with this name already exists":                                      "ya existe una etiqueta con este nombre",
+	"label cannot be used on this task":                                          "la etiqueta no se puede usar en esta tarea",
+	"webhook not found":                                                          "webhook no encontrado",
+	"webhook delivery not found":                                                 "entrega del webhook no encontrada",
+	"webhook endpoint resolves to a private or loopback address":                 "el destino del webhook apunta a una dirección privada o local",
+}
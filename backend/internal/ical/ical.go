@@ -0,0 +1,122 @@
+// Package ical escribe documentos iCalendar (RFC 5545).
+//
+// Solo se implementa la escritura: los componentes se abren y se cierran de
+// forma explícita y las propiedades se escriben en orden. Writer se encarga de
+// escapar el texto, dar formato UTC a las fechas y plegar las líneas.
+package ical
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// dateTimeFormat es la forma UTC de DATE-TIME, p. ej. 20240131T090000Z
+const dateTimeFormat = "20060102T150405Z"
+
+// maxLineOctets es la longitud máxima de una línea de contenido, sin contar el CRLF
+const maxLineOctets = 75
+
+// Writer escribe las líneas de contenido de un documento iCalendar. Se guarda
+// el primer error de escritura, que devuelve Err; las escrituras posteriores se
+// ignoran.
+type Writer struct {
+	w   io.Writer
+	err error
+}
+
+// NewWriter devuelve un Writer que escribe en w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Begin abre un componente, p. ej. VCALENDAR o VTODO
+func (w *Writer) Begin(component string) {
+	w.line("BEGIN:" + component)
+}
+
+// End cierra un componente
+func (w *Writer) End(component string) {
+	w.line("END:" + component)
+}
+
+// Property escribe una propiedad cuyo valor ya está en formato iCalendar. name
+// puede incluir parámetros, p. ej. "REFRESH-INTERVAL;VALUE=DURATION".
+func (w *Writer) Property(name, value string) {
+	w.line(name + ":" + value)
+}
+
+// Text escribe una propiedad TEXT, escapando su valor
+func (w *Writer) Text(name, value string) {
+	w.Property(name, EscapeText(value))
+}
+
+// TextList escribe una propiedad con una lista de valores TEXT separados por comas
+func (w *Writer) TextList(name string, values []string) {
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = EscapeText(v)
+	}
+	w.Property(name, strings.Join(escaped, ","))
+}
+
+// DateTime escribe una propiedad DATE-TIME en UTC
+func (w *Writer) DateTime(name string, t time.Time) {
+	w.Property(name, FormatDateTime(t))
+}
+
+// Err devuelve el primer error que se produjo al escribir
+func (w *Writer) Err() error {
+	return w.err
+}
+
+func (w *Writer) line(content string) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = io.WriteString(w.w, fold(content)+"\r\n")
+}
+
+// FormatDateTime da formato a t como un valor DATE-TIME en UTC
+func FormatDateTime(t time.Time) string {
+	return t.UTC().Format(dateTimeFormat)
+}
+
+// EscapeText escapa un valor TEXT: barras invertidas, puntos y comas, comas y saltos de línea
+func EscapeText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// fold divide una línea de contenido de más de 75 octetos en varias líneas, y
+// cada continuación empieza con un espacio. Las líneas nunca se cortan en medio
+// de una secuencia UTF-8.
+func fold(line string) string {
+	if len(line) <= maxLineOctets {
+		return line
+	}
+
+	var b strings.Builder
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		// Retroceder hasta el inicio de una secuencia UTF-8
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Las líneas de continuación llevan el espacio inicial
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+
+	return b.String()
+}
@@ -0,0 +1,412 @@
+// Package jsonpatch implementa JSON Merge Patch (RFC 7396) y JSON Patch (RFC 6902)
+// sobre documentos JSON genéricos (map[string]interface{}, []interface{}, etc.).
+// Los números se manejan como json.Number (ver Unmarshal) para no perder precisión
+// al pasar por float64, p. ej. en los time.Duration en nanosegundos.
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Operation es una operación de un documento JSON Patch
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"` // vacío si la operación no trae "value"; null se conserva
+}
+
+// ErrTestFailed se devuelve cuando una operación "test" no se cumple
+var ErrTestFailed = errors.New("test operation failed")
+
+// Unmarshal decodifica un documento JSON como json.Unmarshal, pero conserva los
+// números como json.Number en lugar de convertirlos a float64
+func Unmarshal(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("invalid data after top-level value")
+	}
+	return nil
+}
+
+// MergePatch aplica un JSON Merge Patch a un documento.
+// Un valor null en el patch elimina el miembro correspondiente.
+func MergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	} else {
+		targetObj = copyObject(targetObj)
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = MergePatch(targetObj[key], value)
+	}
+
+	return targetObj
+}
+
+// Apply aplica una lista de operaciones JSON Patch a un documento.
+// Las operaciones se aplican en orden y si alguna falla no se devuelve ningún cambio.
+func Apply(doc interface{}, ops []Operation) (interface{}, error) {
+	doc = deepCopy(doc)
+
+	for i, op := range ops {
+		var err error
+		doc, err = applyOperation(doc, op)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	return doc, nil
+}
+
+func applyOperation(doc interface{}, op Operation) (interface{}, error) {
+	switch op.Op {
+	case "add":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, op.Path, value)
+	case "remove":
+		doc, _, err := remove(doc, op.Path)
+		return doc, err
+	case "replace":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		doc, _, err = remove(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, op.Path, value)
+	case "move":
+		if op.Path != op.From && strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, errors.New("cannot move a value into one of its children")
+		}
+		doc, value, err := remove(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, op.Path, value)
+	case "copy":
+		value, err := get(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, op.Path, deepCopy(value))
+	case "test":
+		expected, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		actual, err := get(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !Equal(actual, expected) {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// value decodifica el valor de la operación; "value" es obligatorio en add, replace y test
+func (op Operation) value() (interface{}, error) {
+	if len(op.Value) == 0 {
+		return nil, errors.New("missing value")
+	}
+	var value interface{}
+	if err := Unmarshal(op.Value, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// parsePointer divide un JSON Pointer (RFC 6901) en sus segmentos
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	parts := strings.Split(pointer[1:], "/")
+	for i, part := range parts {
+		part = strings.ReplaceAll(part, "~1", "/")
+		parts[i] = strings.ReplaceAll(part, "~0", "~")
+	}
+	return parts, nil
+}
+
+func get(doc interface{}, pointer string) (interface{}, error) {
+	parts, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	current := doc
+	for _, part := range parts {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[part]
+			if !ok {
+				return nil, fmt.Errorf("path %q does not exist", pointer)
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(part, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("path %q does not exist", pointer)
+		}
+	}
+
+	return current, nil
+}
+
+// add inserta un valor en la ruta indicada y devuelve el documento resultante
+func add(doc interface{}, pointer string, value interface{}) (interface{}, error) {
+	parts, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return value, nil
+	}
+	return addAt(doc, parts, value)
+}
+
+func addAt(node interface{}, parts []string, value interface{}) (interface{}, error) {
+	key := parts[0]
+	last := len(parts) == 1
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if last {
+			n[key] = value
+			return n, nil
+		}
+		child, ok := n[key]
+		if !ok {
+			return nil, fmt.Errorf("path segment %q does not exist", key)
+		}
+		updated, err := addAt(child, parts[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[key] = updated
+		return n, nil
+	case []interface{}:
+		if last {
+			index, err := arrayIndex(key, len(n), true)
+			if err != nil {
+				return nil, err
+			}
+			n = append(n, nil)
+			copy(n[index+1:], n[index:])
+			n[index] = value
+			return n, nil
+		}
+		index, err := arrayIndex(key, len(n), false)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := addAt(n[index], parts[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[index] = updated
+		return n, nil
+	default:
+		return nil, fmt.Errorf("path segment %q does not exist", key)
+	}
+}
+
+// remove elimina el valor en la ruta indicada y devuelve el documento resultante y el valor eliminado
+func remove(doc interface{}, pointer string) (interface{}, interface{}, error) {
+	parts, err := parsePointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(parts) == 0 {
+		return nil, doc, nil
+	}
+	return removeAt(doc, parts)
+}
+
+func removeAt(node interface{}, parts []string) (interface{}, interface{}, error) {
+	key := parts[0]
+	last := len(parts) == 1
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[key]
+		if !ok {
+			return nil, nil, fmt.Errorf("path segment %q does not exist", key)
+		}
+		if last {
+			delete(n, key)
+			return n, child, nil
+		}
+		updated, removed, err := removeAt(child, parts[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		n[key] = updated
+		return n, removed, nil
+	case []interface{}:
+		index, err := arrayIndex(key, len(n), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if last {
+			removed := n[index]
+			return append(n[:index], n[index+1:]...), removed, nil
+		}
+		updated, removed, err := removeAt(n[index], parts[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		n[index] = updated
+		return n, removed, nil
+	default:
+		return nil, nil, fmt.Errorf("path segment %q does not exist", key)
+	}
+}
+
+// arrayIndex interpreta un segmento como índice de arreglo; "-" solo es válido al agregar
+func arrayIndex(segment string, length int, forAdd bool) (int, error) {
+	if segment == "-" && forAdd {
+		return length, nil
+	}
+	index, err := strconv.Atoi(segment)
+	if err != nil || index < 0 || (segment != "0" && strings.HasPrefix(segment, "0")) {
+		return 0, fmt.Errorf("invalid array index %q", segment)
+	}
+	max := length - 1
+	if forAdd {
+		max = length
+	}
+	if index > max {
+		return 0, fmt.Errorf("array index %d out of bounds", index)
+	}
+	return index, nil
+}
+
+// Equal compara dos valores JSON genéricos. Los números se comparan por su valor,
+// de modo que json.Number("1.0") y float64(1) son iguales.
+func Equal(a, b interface{}) bool {
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for key, value := range x {
+			other, ok := y[key]
+			if !ok || !Equal(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !Equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number, float64:
+		return equalNumbers(a, b)
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// equalNumbers compara dos números. Entre json.Number se compara el valor decimal
+// exacto; si alguno es float64, la comparación se hace en float64.
+func equalNumbers(a, b interface{}) bool {
+	x, xNumber := a.(json.Number)
+	y, yNumber := b.(json.Number)
+	if xNumber && yNumber {
+		xr, xok := new(big.Rat).SetString(string(x))
+		yr, yok := new(big.Rat).SetString(string(y))
+		return xok && yok && xr.Cmp(yr) == 0
+	}
+
+	xf, xerr := toFloat(a)
+	yf, yerr := toFloat(b)
+	return xerr == nil && yerr == nil && xf == yf
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case json.Number:
+		return n.Float64()
+	default:
+		return 0, errors.New("not a number")
+	}
+}
+
+func copyObject(obj map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(obj))
+	for key, value := range obj {
+		out[key] = value
+	}
+	return out
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			out[key] = deepCopy(child)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			out[i] = deepCopy(child)
+		}
+		return out
+	default:
+		return v
+	}
+}
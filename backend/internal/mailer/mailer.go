@@ -0,0 +1,227 @@
+// Package mailer envía los emails a través de un backend intercambiable.
+//
+// El backend se elige al arrancar: LogMailer solo registra los mensajes,
+// FileMailer escribe cada uno como un fichero .eml (útil en desarrollo, donde
+// los ficheros se pueden abrir con cualquier cliente de correo) y SMTPMailer los
+// entrega a un servidor SMTP o a un sustituto local como MailHog.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Backends que acepta New
+const (
+	BackendLog  = "log"
+	BackendFile = "file"
+	BackendSMTP = "smtp"
+)
+
+// Message es un email con un cuerpo en texto plano y una alternativa HTML opcional
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer envía emails
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config elige y configura el backend
+type Config struct {
+	Backend  string // log, file o smtp
+	From     string // Dirección del remitente, p. ej. "Task Manager <no-reply@example.com>"
+	Dir      string // Directorio de salida del backend file
+	SMTPAddr string // host:puerto del servidor SMTP
+	Username string // Credenciales SMTP (opcionales)
+	Password string
+}
+
+// New devuelve el mailer configurado en cfg
+func New(cfg Config) (Mailer, error) {
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %v", cfg.From, err)
+	}
+
+	switch cfg.Backend {
+	case "", BackendLog:
+		return &LogMailer{From: cfg.From}, nil
+	case BackendFile:
+		if cfg.Dir == "" {
+			return nil, fmt.Errorf("the file mailer needs an output directory")
+		}
+		return &FileMailer{From: cfg.From, Dir: cfg.Dir}, nil
+	case BackendSMTP:
+		if cfg.SMTPAddr == "" {
+			return nil, fmt.Errorf("the smtp mailer needs a server address")
+		}
+		return &SMTPMailer{From: cfg.From, Addr: cfg.SMTPAddr, Username: cfg.Username, Password: cfg.Password}, nil
+	default:
+		return nil, fmt.Errorf("unknown mailer backend %q", cfg.Backend)
+	}
+}
+
+// LogMailer registra los destinatarios y el asunto de cada mensaje en lugar de enviarlo
+type LogMailer struct {
+	From string
+}
+
+// Send registra el mensaje
+func (m *LogMailer) Send(_ context.Context, msg Message) error {
+	log.Printf("Mail to %s: %s", strings.Join(msg.To, ", "), msg.Subject)
+	return nil
+}
+
+// FileMailer escribe cada mensaje como un fichero .eml en Dir
+type FileMailer struct {
+	From string
+	Dir  string
+}
+
+// Send escribe el mensaje en un fichero nuevo
+func (m *FileMailer) Send(_ context.Context, msg Message) error {
+	data, err := Compose(m.From, msg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), randomHex(4))
+	return os.WriteFile(filepath.Join(m.Dir, name), data, 0o644)
+}
+
+// SMTPMailer envía los mensajes a través de un servidor SMTP. Las credenciales
+// son opcionales; si se indican se usa la autenticación PLAIN (net/smtp solo la
+// permite con TLS o hacia localhost).
+type SMTPMailer struct {
+	From     string
+	Addr     string
+	Username string
+	Password string
+}
+
+// Send entrega el mensaje al servidor SMTP
+func (m *SMTPMailer) Send(_ context.Context, msg Message) error {
+	data, err := Compose(m.From, msg)
+	if err != nil {
+		return err
+	}
+
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return err
+	}
+	to := make([]string, len(msg.To))
+	for i, recipient := range msg.To {
+		addr, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %v", recipient, err)
+		}
+		to[i] = addr.Address
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		host := m.Addr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+
+	return smtp.SendMail(m.Addr, auth, from.Address, to, data)
+}
+
+// Compose genera el mensaje en formato RFC 5322. Los mensajes con cuerpo HTML
+// se envían como multipart/alternative con la parte de texto plano primero.
+func Compose(from string, msg Message) ([]byte, error) {
+	if len(msg.To) == 0 {
+		return nil, fmt.Errorf("message has no recipients")
+	}
+	for _, value := range append([]string{from, msg.Subject}, msg.To...) {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("header values cannot contain line breaks")
+		}
+	}
+
+	var buf bytes.Buffer
+	writeHeader := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+	writeHeader("From", from)
+	writeHeader("To", strings.Join(msg.To, ", "))
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	writeHeader("Message-ID", fmt.Sprintf("<%s@task-manager>", randomHex(16)))
+	writeHeader("MIME-Version", "1.0")
+
+	if msg.HTML == "" {
+		writeHeader("Content-Type", `text/plain; charset="utf-8"`)
+		writeHeader("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, msg.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&buf)
+	writeHeader("Content-Type", `multipart/alternative; boundary="`+parts.Boundary()+`"`)
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, body string }{
+		{`text/plain; charset="utf-8"`, msg.Text},
+		{`text/html; charset="utf-8"`, msg.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
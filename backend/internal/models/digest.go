@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+	_ "time/tzdata" // Zonas horarias incluidas en el binario para los contenedores sin tzdata
+)
+
+// Frecuencias del resumen por correo
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// DigestSettings es la configuración del resumen por correo de un usuario
+type DigestSettings struct {
+	UserID     string     `json:"user_id" firestore:"user_id"`
+	Enabled    bool       `json:"enabled" firestore:"enabled"`
+	Frequency  string     `json:"frequency" firestore:"frequency"` // daily o weekly
+	Hour       int        `json:"hour" firestore:"hour"`           // Hora local de envío (0-23)
+	Weekday    int        `json:"weekday" firestore:"weekday"`     // Día de envío de los semanales (0 = domingo)
+	Timezone   string     `json:"timezone" firestore:"timezone"`   // Zona horaria IANA, p. ej. "Europe/Madrid"
+	LastSentAt *time.Time `json:"last_sent_at,omitempty" firestore:"last_sent_at,omitempty"`
+	NextSendAt *time.Time `json:"next_send_at,omitempty" firestore:"next_send_at,omitempty"` // nil si está desactivado
+	UpdatedAt  time.Time  `json:"updated_at" firestore:"updated_at"`
+}
+
+// DefaultDigestSettings devuelve la configuración de un usuario que aún no la ha cambiado
+func DefaultDigestSettings(userID string) *DigestSettings {
+	return &DigestSettings{
+		UserID:    userID,
+		Frequency: DigestDaily,
+		Hour:      8,
+		Weekday:   int(time.Monday),
+		Timezone:  "UTC",
+	}
+}
+
+// Validate verifica si la configuración es válida
+func (s *DigestSettings) Validate() bool {
+	if s.Frequency != DigestDaily && s.Frequency != DigestWeekly {
+		return false
+	}
+	if s.Hour < 0 || s.Hour > 23 || s.Weekday < 0 || s.Weekday > 6 {
+		return false
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return false
+	}
+	return true
+}
+
+// Location devuelve la zona horaria del usuario (UTC si no es válida)
+func (s *DigestSettings) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Period devuelve el intervalo que cubre cada resumen
+func (s *DigestSettings) Period() time.Duration {
+	if s.Frequency == DigestWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// NextSend devuelve el primer momento de envío posterior a after, en la hora
+// local del usuario
+func (s *DigestSettings) NextSend(after time.Time) time.Time {
+	local := after.In(s.Location())
+	next := time.Date(local.Year(), local.Month(), local.Day(), s.Hour, 0, 0, 0, local.Location())
+	for !next.After(after) || (s.Frequency == DigestWeekly && int(next.Weekday()) != s.Weekday) {
+		next = time.Date(next.Year(), next.Month(), next.Day()+1, s.Hour, 0, 0, 0, next.Location())
+	}
+	return next
+}
@@ -5,15 +5,31 @@ import (
 )
 
 type Group struct {
-	ID          string    `json:"id" firestore:"id"`
-	CreatorID   string    `json:"creator_id" firestore:"creator_id"`
-	Name        string    `json:"name" firestore:"name"`
-	Description string    `json:"description" firestore:"description"`
-	Members     []string  `json:"members" firestore:"members"`
-	CreatedAt   time.Time `json:"created_at" firestore:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" firestore:"updated_at"`
+	ID          string            `json:"id" firestore:"id"`
+	WorkspaceID string            `json:"workspace_id" firestore:"workspace_id"` // Espacio de trabajo del grupo
+	CreatorID   string            `json:"creator_id" firestore:"creator_id"`
+	Name        string            `json:"name" firestore:"name"`
+	Description string            `json:"description" firestore:"description"`
+	Members     []string          `json:"members" firestore:"members"`
+	Roles       map[string]string `json:"roles,omitempty" firestore:"roles,omitempty"` // Rol de cada miembro (ver RoleOf)
+	CreatedAt   time.Time         `json:"created_at" firestore:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at" firestore:"updated_at"`
+	Version     int64             `json:"version" firestore:"version"`                       // Versión para control de concurrencia optimista
+	Workflow    *Workflow         `json:"workflow,omitempty" firestore:"workflow,omitempty"` // Estados propios del grupo (nil = flujo por defecto)
+
+	// Jerarquía: ParentID es el grupo padre (nil en los grupos raíz) y Path los IDs
+	// de todos los antecesores, desde la raíz hasta el padre
+	ParentID *string  `json:"parent_id,omitempty" firestore:"parent_id,omitempty"`
+	Path     []string `json:"path" firestore:"path"`
+
+	// Inherited son los roles que los miembros de los grupos antecesores tienen en
+	// este grupo. No se guarda: lo calcula GroupService al cargar el grupo.
+	Inherited map[string]string `json:"inherited_roles,omitempty" firestore:"-"`
 }
 
+// MaxGroupDepth es el número máximo de niveles de una jerarquía de grupos
+const MaxGroupDepth = 5
+
 // Validate verifica si los datos del grupo son válidos
 func (g *Group) Validate() bool {
 	if g.CreatorID == "" || g.Name == "" || len(g.Members) == 0 {
@@ -22,9 +38,28 @@ func (g *Group) Validate() bool {
 	if len(g.Name) > 100 || len(g.Description) > 500 {
 		return false
 	}
+	if len(g.Path) >= MaxGroupDepth {
+		return false
+	}
+	if g.Workflow != nil && !g.Workflow.Validate() {
+		return false
+	}
+	for _, role := range g.Roles {
+		if !IsGroupRole(role) {
+			return false
+		}
+	}
 	return true
 }
 
+// TaskWorkflow devuelve el flujo de trabajo que aplica a las tareas del grupo
+func (g *Group) TaskWorkflow() *Workflow {
+	if g.Workflow == nil {
+		return DefaultWorkflow()
+	}
+	return g.Workflow
+}
+
 // AddMember agrega un nuevo miembro al grupo
 func (g *Group) AddMember(userID string) {
 	for _, member := range g.Members {
@@ -44,3 +79,140 @@ func (g *Group) RemoveMember(userID string) {
 		}
 	}
 }
+
+// IsDirectMember indica si el usuario es miembro del propio grupo y no solo de un antecesor
+func (g *Group) IsDirectMember(userID string) bool {
+	for _, member := range g.Members {
+		if member == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleOf devuelve el rol del usuario en el grupo, o "" si no es miembro ni hereda
+// la pertenencia de un antecesor. El rol guardado en el grupo tiene prioridad
+// sobre el heredado. Los grupos creados antes de existir los roles no los guardan:
+// su creador es el propietario y el resto de miembros tienen el rol member.
+func (g *Group) RoleOf(userID string) string {
+	isMember := g.IsDirectMember(userID)
+	inherited, isInherited := g.Inherited[userID]
+	if !isMember && !isInherited {
+		return ""
+	}
+
+	if role, ok := g.Roles[userID]; ok {
+		return role
+	}
+	if !isMember {
+		return inherited
+	}
+	if userID == g.CreatorID {
+		return GroupRoleOwner
+	}
+	return GroupRoleMember
+}
+
+// IsMember indica si el usuario pertenece al grupo, directamente o por herencia
+func (g *Group) IsMember(userID string) bool {
+	return g.RoleOf(userID) != ""
+}
+
+// AllMembers devuelve los miembros del grupo y los heredados de sus antecesores
+func (g *Group) AllMembers() []string {
+	members := append([]string(nil), g.Members...)
+	for userID := range g.Inherited {
+		if !g.IsDirectMember(userID) {
+			members = append(members, userID)
+		}
+	}
+	return members
+}
+
+// ChildRoles devuelve los roles que heredan los subgrupos: los de todos los
+// miembros, salvo el de propietario, que en los subgrupos es administrador
+func (g *Group) ChildRoles() map[string]string {
+	roles := g.MemberRoles()
+	for userID, role := range roles {
+		if role == GroupRoleOwner {
+			roles[userID] = GroupRoleAdmin
+		}
+	}
+	return roles
+}
+
+// IsRoot indica si el grupo no tiene grupo padre
+func (g *Group) IsRoot() bool {
+	return g.ParentID == nil || *g.ParentID == ""
+}
+
+// OwnerID devuelve el ID del propietario del grupo
+func (g *Group) OwnerID() string {
+	for userID, role := range g.Roles {
+		if role == GroupRoleOwner {
+			return userID
+		}
+	}
+	return g.CreatorID
+}
+
+// MemberRoles devuelve el rol de cada miembro del grupo, incluidos los que no lo
+// tienen guardado y los heredados de los antecesores
+func (g *Group) MemberRoles() map[string]string {
+	members := g.AllMembers()
+	roles := make(map[string]string, len(members))
+	for _, member := range members {
+		roles[member] = g.RoleOf(member)
+	}
+	return roles
+}
+
+// Can indica si el usuario tiene el permiso en el grupo
+func (g *Group) Can(userID string, permission GroupPermission) bool {
+	return RoleAllows(g.RoleOf(userID), permission)
+}
+
+// Outranks indica si el usuario puede gestionar a un miembro con el rol dado: el
+// propietario puede con todos y el resto solo con los roles inferiores al suyo
+func (g *Group) Outranks(userID, role string) bool {
+	actorRole := g.RoleOf(userID)
+	if actorRole == GroupRoleOwner {
+		return true
+	}
+	return groupRoleRank[actorRole] > groupRoleRank[role]
+}
+
+// GroupNode es un grupo con sus subgrupos
+type GroupNode struct {
+	Group
+	Children []*GroupNode `json:"children"`
+}
+
+// GroupTree organiza los grupos en árboles. Los grupos cuyo padre no está en la
+// lista son raíces del resultado. Conserva el orden de la lista.
+func GroupTree(groups []Group) []*GroupNode {
+	nodes := make(map[string]*GroupNode, len(groups))
+	for _, group := range groups {
+		nodes[group.ID] = &GroupNode{Group: group, Children: []*GroupNode{}}
+	}
+
+	roots := []*GroupNode{}
+	for _, group := range groups {
+		node := nodes[group.ID]
+		if !group.IsRoot() {
+			if parent, ok := nodes[*group.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// ArchivedGroup es la copia de un grupo eliminado cuyas tareas se archivaron
+type ArchivedGroup struct {
+	Group      Group     `json:"group" firestore:"group"`
+	ArchivedAt time.Time `json:"archived_at" firestore:"archived_at"`
+	ArchivedBy string    `json:"archived_by" firestore:"archived_by"` // Usuario que eliminó el grupo
+}
@@ -0,0 +1,66 @@
+package models
+
+// Roles de los miembros de un grupo, de mayor a menor
+const (
+	GroupRoleOwner  = "owner"  // Único por grupo; puede todo, incluido transferir la propiedad
+	GroupRoleAdmin  = "admin"  // Gestiona el grupo y sus miembros
+	GroupRoleMember = "member" // Crea y trabaja en las tareas del grupo
+	GroupRoleViewer = "viewer" // Solo lectura
+)
+
+// GroupPermission es una acción sobre un grupo o sus tareas que depende del rol
+type GroupPermission string
+
+const (
+	PermViewGroup         GroupPermission = "view_group"         // Ver el grupo, su tablero y sus tareas
+	PermEditGroup         GroupPermission = "edit_group"         // Cambiar los datos, el flujo de trabajo y los webhooks del grupo
+	PermDeleteGroup       GroupPermission = "delete_group"       // Eliminar el grupo
+	PermManageMembers     GroupPermission = "manage_members"     // Invitar y eliminar miembros y gestionar los enlaces de invitación
+	PermManageRoles       GroupPermission = "manage_roles"       // Cambiar el rol de los miembros
+	PermTransferOwnership GroupPermission = "transfer_ownership" // Ceder la propiedad del grupo
+	PermCreateTasks       GroupPermission = "create_tasks"       // Crear tareas, etiquetas y plantillas en el grupo
+	PermEditTasks         GroupPermission = "edit_tasks"         // Colaborar en las tareas del grupo y modificar por completo las propias
+	PermManageTasks       GroupPermission = "manage_tasks"       // Modificar por completo cualquier tarea del grupo
+	PermDeleteOwnTasks    GroupPermission = "delete_own_tasks"   // Eliminar sus propias tareas del grupo
+	PermDeleteAnyTask     GroupPermission = "delete_any_task"    // Eliminar cualquier tarea del grupo
+)
+
+// groupPermissions es la matriz de permisos de cada rol
+var groupPermissions = map[string]map[GroupPermission]bool{
+	GroupRoleOwner: {
+		PermViewGroup: true, PermEditGroup: true, PermDeleteGroup: true,
+		PermManageMembers: true, PermManageRoles: true, PermTransferOwnership: true,
+		PermCreateTasks: true, PermEditTasks: true, PermManageTasks: true, PermDeleteOwnTasks: true, PermDeleteAnyTask: true,
+	},
+	GroupRoleAdmin: {
+		PermViewGroup: true, PermEditGroup: true,
+		PermManageMembers: true, PermManageRoles: true,
+		PermCreateTasks: true, PermEditTasks: true, PermManageTasks: true, PermDeleteOwnTasks: true, PermDeleteAnyTask: true,
+	},
+	GroupRoleMember: {
+		PermViewGroup:   true,
+		PermCreateTasks: true, PermEditTasks: true, PermDeleteOwnTasks: true,
+	},
+	GroupRoleViewer: {
+		PermViewGroup: true,
+	},
+}
+
+// groupRoleRank ordena los roles para decidir quién puede gestionar a quién
+var groupRoleRank = map[string]int{
+	GroupRoleViewer: 1,
+	GroupRoleMember: 2,
+	GroupRoleAdmin:  3,
+	GroupRoleOwner:  4,
+}
+
+// IsGroupRole indica si el rol existe
+func IsGroupRole(role string) bool {
+	_, ok := groupPermissions[role]
+	return ok
+}
+
+// RoleAllows indica si el rol tiene el permiso. Un rol vacío (no miembro) no tiene ninguno.
+func RoleAllows(role string, permission GroupPermission) bool {
+	return groupPermissions[role][permission]
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Estados de una invitación
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+	InvitationExpired  = "expired"
+	InvitationRevoked  = "revoked" // Cancelada por quien invitó
+)
+
+// GroupInvitation es la invitación de un usuario, o de una dirección de correo
+// que aún no está registrada, a un grupo
+type GroupInvitation struct {
+	ID          string     `json:"id" firestore:"id"`
+	GroupID     string     `json:"group_id" firestore:"group_id"`
+	GroupName   string     `json:"group_name" firestore:"group_name"`
+	WorkspaceID string     `json:"workspace_id" firestore:"workspace_id"` // Espacio de trabajo del grupo
+	InviterID   string     `json:"inviter_id" firestore:"inviter_id"`
+	InviteeID   *string    `json:"invitee_id,omitempty" firestore:"invitee_id,omitempty"` // nil si se invitó a un correo sin cuenta
+	Email       string     `json:"email,omitempty" firestore:"email,omitempty"`           // En minúsculas
+	Status      string     `json:"status" firestore:"status"`
+	CreatedAt   time.Time  `json:"created_at" firestore:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at" firestore:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty" firestore:"responded_at,omitempty"`
+}
+
+// Refresh marca como caducada una invitación pendiente cuyo plazo ya pasó.
+// Devuelve si cambió el estado.
+func (i *GroupInvitation) Refresh(now time.Time) bool {
+	if i.Status == InvitationPending && !now.Before(i.ExpiresAt) {
+		i.Status = InvitationExpired
+		return true
+	}
+	return false
+}
+
+// IsFor indica si la invitación es para el usuario
+func (i *GroupInvitation) IsFor(userID, email string) bool {
+	if i.InviteeID != nil && *i.InviteeID == userID {
+		return true
+	}
+	return i.Email != "" && strings.EqualFold(i.Email, email)
+}
+
+// InviteLink es un enlace para unirse a un grupo. El token solo se muestra al
+// crearlo; se guarda su hash, que es también el ID del documento.
+type InviteLink struct {
+	ID        string     `json:"id" firestore:"id"`
+	GroupID   string     `json:"group_id" firestore:"group_id"`
+	CreatorID string     `json:"creator_id" firestore:"creator_id"`
+	MaxUses   int        `json:"max_uses" firestore:"max_uses"` // 0 = sin límite
+	Uses      int        `json:"uses" firestore:"uses"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" firestore:"expires_at,omitempty"`
+	Revoked   bool       `json:"revoked" firestore:"revoked"`
+	CreatedAt time.Time  `json:"created_at" firestore:"created_at"`
+}
+
+// Usable indica si el enlace todavía permite unirse al grupo
+func (l *InviteLink) Usable(now time.Time) bool {
+	if l.Revoked {
+		return false
+	}
+	if l.ExpiresAt != nil && !now.Before(*l.ExpiresAt) {
+		return false
+	}
+	return l.MaxUses == 0 || l.Uses < l.MaxUses
+}
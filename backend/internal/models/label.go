@@ -0,0 +1,44 @@
+package models
+
+import (
+	"regexp"
+	"time"
+)
+
+// Label es una etiqueta reutilizable, personal (de un usuario) o compartida por un grupo
+type Label struct {
+	ID        string    `json:"id" firestore:"id"`
+	Name      string    `json:"name" firestore:"name"`
+	Color     string    `json:"color" firestore:"color"`                           // Color en formato #rrggbb
+	OwnerID   string    `json:"owner_id" firestore:"owner_id"`                     // Usuario que creó la etiqueta
+	GroupID   *string   `json:"group_id,omitempty" firestore:"group_id,omitempty"` // Grupo de la etiqueta (nil = etiqueta personal)
+	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" firestore:"updated_at"`
+}
+
+// TaskLabel es la copia de una etiqueta que se guarda dentro de cada tarea
+type TaskLabel struct {
+	ID    string `json:"id" firestore:"id"`
+	Name  string `json:"name" firestore:"name"`
+	Color string `json:"color" firestore:"color"`
+}
+
+var labelColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Validate verifica si los datos de la etiqueta son válidos
+func (l *Label) Validate() bool {
+	if l.Name == "" || len(l.Name) > 50 || l.OwnerID == "" {
+		return false
+	}
+	return labelColorRegex.MatchString(l.Color)
+}
+
+// IsGroupLabel indica si la etiqueta pertenece a un grupo
+func (l *Label) IsGroupLabel() bool {
+	return l.GroupID != nil && *l.GroupID != ""
+}
+
+// ToTaskLabel devuelve la copia de la etiqueta que se guarda en las tareas
+func (l *Label) ToTaskLabel() TaskLabel {
+	return TaskLabel{ID: l.ID, Name: l.Name, Color: l.Color}
+}
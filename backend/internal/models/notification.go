@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// Tipos de notificación
+const (
+	NotificationAssignment  = "assignment"   // Se asignó una tarea al usuario
+	NotificationMention     = "mention"      // Se mencionó al usuario (@username) en una tarea
+	NotificationGroupInvite = "group_invite" // Se añadió al usuario a un grupo
+	NotificationDueSoon     = "due_soon"     // Una tarea del usuario vence pronto
+	NotificationComment     = "comment"      // Nuevo comentario en una tarea del usuario (aún no se genera: las tareas no tienen comentarios)
+)
+
+// NotificationTypes son todos los tipos de notificación. Los usuarios pueden
+// configurar también los que aún no se generan, como NotificationComment.
+var NotificationTypes = []string{
+	NotificationAssignment,
+	NotificationMention,
+	NotificationGroupInvite,
+	NotificationDueSoon,
+	NotificationComment,
+}
+
+// Notification es un aviso de la bandeja de entrada de un usuario
+type Notification struct {
+	ID        string     `json:"id" firestore:"id"`
+	UserID    string     `json:"user_id" firestore:"user_id"` // Destinatario
+	Type      string     `json:"type" firestore:"type"`
+	Title     string     `json:"title" firestore:"title"`
+	Body      string     `json:"body,omitempty" firestore:"body,omitempty"`
+	ActorID   string     `json:"actor_id,omitempty" firestore:"actor_id,omitempty"` // Usuario que provocó la notificación
+	TaskID    *string    `json:"task_id,omitempty" firestore:"task_id,omitempty"`
+	GroupID   *string    `json:"group_id,omitempty" firestore:"group_id,omitempty"`
+	Read      bool       `json:"read" firestore:"read"`
+	ReadAt    *time.Time `json:"read_at,omitempty" firestore:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" firestore:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at" firestore:"expires_at"` // Se elimina automáticamente a partir de esta fecha
+}
+
+// NotificationPreferences indica qué tipos de notificación recibe un usuario.
+// Los tipos que no aparecen en Types están activados.
+type NotificationPreferences struct {
+	UserID    string          `json:"user_id" firestore:"user_id"`
+	Types     map[string]bool `json:"types" firestore:"types"`
+	UpdatedAt time.Time       `json:"updated_at" firestore:"updated_at"`
+}
+
+// Enabled indica si el usuario recibe las notificaciones del tipo
+func (p *NotificationPreferences) Enabled(notificationType string) bool {
+	enabled, ok := p.Types[notificationType]
+	return !ok || enabled
+}
+
+// IsNotificationType indica si el tipo es uno de los admitidos
+func IsNotificationType(notificationType string) bool {
+	for _, t := range NotificationTypes {
+		if t == notificationType {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired indica si la notificación ya caducó
+func (n *Notification) Expired(now time.Time) bool {
+	return !n.ExpiresAt.IsZero() && !now.Before(n.ExpiresAt)
+}
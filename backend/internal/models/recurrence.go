@@ -0,0 +1,100 @@
+package models
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	byDayRegex = regexp.MustCompile(`^([+-]?[1-5]?)(MO|TU|WE|TH|FR|SA|SU)$`)
+	weekdays   = map[string]bool{"MO": true, "TU": true, "WE": true, "TH": true, "FR": true, "SA": true, "SU": true}
+)
+
+// ValidRecurrence checks a recurrence rule written as an RFC 5545 RRULE value
+// (without the "RRULE:" prefix). Only the parts calendar clients commonly
+// support are accepted: FREQ, INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY,
+// BYMONTH and WKST.
+func ValidRecurrence(rule string) bool {
+	parts := make(map[string]string)
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return false
+		}
+		if _, dup := parts[key]; dup {
+			return false
+		}
+		parts[key] = value
+	}
+
+	switch parts["FREQ"] {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return false
+	}
+
+	// COUNT and UNTIL are mutually exclusive
+	if _, hasCount := parts["COUNT"]; hasCount {
+		if _, hasUntil := parts["UNTIL"]; hasUntil {
+			return false
+		}
+	}
+
+	for key, value := range parts {
+		switch key {
+		case "FREQ":
+		case "INTERVAL", "COUNT":
+			if n, err := strconv.Atoi(value); err != nil || n < 1 {
+				return false
+			}
+		case "UNTIL":
+			if !validRRuleDate(value) {
+				return false
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				if !byDayRegex.MatchString(day) {
+					return false
+				}
+			}
+		case "BYMONTHDAY":
+			if !validIntList(value, -31, 31) {
+				return false
+			}
+		case "BYMONTH":
+			if !validIntList(value, 1, 12) {
+				return false
+			}
+		case "WKST":
+			if !weekdays[value] {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// validRRuleDate checks an UNTIL value: a date (20240131) or a UTC date-time (20240131T090000Z)
+func validRRuleDate(value string) bool {
+	if _, err := time.Parse("20060102", value); err == nil {
+		return true
+	}
+	_, err := time.Parse("20060102T150405Z", value)
+	return err == nil
+}
+
+// validIntList checks a comma-separated list of non-zero integers within [min, max]
+func validIntList(value string, min, max int) bool {
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n == 0 || n < min || n > max {
+			return false
+		}
+	}
+	return true
+}
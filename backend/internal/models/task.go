@@ -1,6 +1,7 @@
 package models
 
 import (
+	"sort"
 	"time"
 )
 
@@ -8,6 +9,7 @@ type Task struct {
 	ID               string        `json:"id" firestore:"id"`
 	UserID           string        `json:"user_id" firestore:"user_id"`                       // ID del usuario que creó la tarea
 	GroupID          *string       `json:"group_id,omitempty" firestore:"group_id,omitempty"` // ID del grupo (puede ser nil)
+	WorkspaceID      string        `json:"workspace_id" firestore:"workspace_id"`             // Espacio de trabajo de la tarea
 	Title            string        `json:"title" firestore:"title"`
 	Description      string        `json:"description" firestore:"description"`
 	TimeUntilFinish  time.Duration `json:"time_until_finish" firestore:"time_until_finish"`
@@ -19,6 +21,16 @@ type Task struct {
 	CreatedBy        string        `json:"created_by" firestore:"created_by"`                                   // ID del usuario que creó la tarea
 	AssignedTo       *string       `json:"assigned_to,omitempty" firestore:"assigned_to,omitempty"`             // ID del usuario asignado (puede ser nil)
 	ArrCollaborators []string      `json:"arr_collaborators,omitempty" firestore:"arr_collaborators,omitempty"` // IDs de colaboradores
+	Version          int64         `json:"version" firestore:"version"`                                         // Versión para control de concurrencia optimista
+	Rank             string        `json:"rank,omitempty" firestore:"rank,omitempty"`                           // Posición dentro de su columna del tablero
+	Priority         string        `json:"priority,omitempty" firestore:"priority,omitempty"`
+	LabelIDs         []string      `json:"label_ids,omitempty" firestore:"label_ids,omitempty"` // IDs de las etiquetas, para filtrar
+	Labels           []TaskLabel   `json:"labels,omitempty" firestore:"labels,omitempty"`       // Copia de nombre y color de cada etiqueta
+	EstimatedEffort  time.Duration `json:"estimated_effort,omitempty" firestore:"estimated_effort,omitempty"`
+	DueDate          *time.Time    `json:"due_date,omitempty" firestore:"due_date,omitempty"`
+	ParentID         *string       `json:"parent_id,omitempty" firestore:"parent_id,omitempty"`     // Tarea padre si es una subtarea
+	Recurrence       string        `json:"recurrence,omitempty" firestore:"recurrence,omitempty"`   // Regla RRULE de RFC 5545, p. ej. "FREQ=WEEKLY;BYDAY=MO"
+	AssignedAt       *time.Time    `json:"assigned_at,omitempty" firestore:"assigned_at,omitempty"` // Cuándo se asignó al usuario actual
 }
 
 // Define valid status constants
@@ -28,18 +40,57 @@ const (
 	TaskStatusCompleted  = "completed"
 )
 
-// Validate checks if the task data is valid
-func (t *Task) Validate() bool {
+// Define valid priority constants
+const (
+	TaskPriorityLow    = "low"
+	TaskPriorityMedium = "medium"
+	TaskPriorityHigh   = "high"
+	TaskPriorityUrgent = "urgent"
+)
+
+// PriorityLevel returns the sort weight of a priority (0 when the task has none)
+func PriorityLevel(priority string) int {
+	switch priority {
+	case TaskPriorityLow:
+		return 1
+	case TaskPriorityMedium:
+		return 2
+	case TaskPriorityHigh:
+		return 3
+	case TaskPriorityUrgent:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// Validate checks if the task data is valid against the workflow that applies to it.
+// A nil workflow means the default workflow used by personal tasks.
+func (t *Task) Validate(workflow *Workflow) bool {
 	// Check required fields
 	if t.Title == "" || t.Description == "" || t.CreatedBy == "" {
 		return false
 	}
 
 	// Validate status
-	switch t.Status {
-	case TaskStatusPending, TaskStatusInProgress, TaskStatusCompleted:
-		// Status is valid
-	default:
+	if workflow == nil {
+		workflow = DefaultWorkflow()
+	}
+	if !workflow.HasStatus(t.Status) {
+		return false
+	}
+
+	// Validate priority (optional)
+	if t.Priority != "" && PriorityLevel(t.Priority) == 0 {
+		return false
+	}
+
+	if t.EstimatedEffort < 0 {
+		return false
+	}
+
+	// Validate recurrence rule (optional)
+	if t.Recurrence != "" && !ValidRecurrence(t.Recurrence) {
 		return false
 	}
 
@@ -51,6 +102,30 @@ func (t *Task) Validate() bool {
 	return true
 }
 
+// DueAt returns when the task is due: the explicit due date or, for tasks that
+// only carry time_until_finish, the creation time plus that duration
+func (t *Task) DueAt() *time.Time {
+	if t.DueDate != nil {
+		return t.DueDate
+	}
+	if t.TimeUntilFinish > 0 && !t.CreatedAt.IsZero() {
+		due := t.CreatedAt.Add(t.TimeUntilFinish)
+		return &due
+	}
+	return nil
+}
+
+// TrackAssignment updates AssignedAt after AssignedTo changes from previous
+func (t *Task) TrackAssignment(previous *string, now time.Time) {
+	if t.AssignedTo == nil || *t.AssignedTo == "" {
+		t.AssignedAt = nil
+		return
+	}
+	if previous == nil || *previous != *t.AssignedTo {
+		t.AssignedAt = &now
+	}
+}
+
 // AddCollaborator adds a new collaborator to the task
 func (t *Task) AddCollaborator(userID string) {
 	for _, collaborator := range t.ArrCollaborators {
@@ -70,3 +145,18 @@ func (t *Task) RemoveCollaborator(userID string) {
 		}
 	}
 }
+
+// SortByRank ordena las tareas por su posición en el tablero; las tareas sin
+// posición van al final, por fecha de creación
+func SortByRank(tasks []Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if (a.Rank == "") != (b.Rank == "") {
+			return a.Rank != ""
+		}
+		if a.Rank != b.Rank {
+			return a.Rank < b.Rank
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+}
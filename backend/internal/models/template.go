@@ -0,0 +1,71 @@
+package models
+
+import (
+	"regexp"
+	"time"
+)
+
+// TaskTemplate es una estructura de tareas reutilizable (p. ej. una checklist de onboarding)
+type TaskTemplate struct {
+	ID            string            `json:"id" firestore:"id"`
+	OwnerID       string            `json:"owner_id" firestore:"owner_id"`                     // Usuario que creó la plantilla
+	GroupID       *string           `json:"group_id,omitempty" firestore:"group_id,omitempty"` // Grupo con el que se comparte (nil = personal)
+	Name          string            `json:"name" firestore:"name"`
+	Title         string            `json:"title" firestore:"title"` // Admite variables como {{date}}
+	Description   string            `json:"description" firestore:"description"`
+	Category      string            `json:"category" firestore:"category"`
+	Priority      string            `json:"priority,omitempty" firestore:"priority,omitempty"`
+	Collaborators []string          `json:"collaborators,omitempty" firestore:"collaborators,omitempty"`
+	DueOffset     time.Duration     `json:"due_offset,omitempty" firestore:"due_offset,omitempty"` // Vencimiento relativo a la creación (0 = sin fecha)
+	Subtasks      []TemplateSubtask `json:"subtasks,omitempty" firestore:"subtasks,omitempty"`
+	CreatedAt     time.Time         `json:"created_at" firestore:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at" firestore:"updated_at"`
+}
+
+// TemplateSubtask es una subtarea que se crea junto con la tarea de la plantilla
+type TemplateSubtask struct {
+	Title       string        `json:"title" firestore:"title"`
+	Description string        `json:"description" firestore:"description"`
+	DueOffset   time.Duration `json:"due_offset,omitempty" firestore:"due_offset,omitempty"`
+}
+
+// Validate verifica si los datos de la plantilla son válidos
+func (t *TaskTemplate) Validate() bool {
+	if t.OwnerID == "" || t.Name == "" || len(t.Name) > 100 {
+		return false
+	}
+	if t.Title == "" || t.Description == "" {
+		return false
+	}
+	if t.Priority != "" && PriorityLevel(t.Priority) == 0 {
+		return false
+	}
+	if t.DueOffset < 0 || len(t.Subtasks) > 50 {
+		return false
+	}
+	for _, subtask := range t.Subtasks {
+		if subtask.Title == "" || subtask.Description == "" || subtask.DueOffset < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsGroupTemplate indica si la plantilla se comparte con un grupo
+func (t *TaskTemplate) IsGroupTemplate() bool {
+	return t.GroupID != nil && *t.GroupID != ""
+}
+
+var templateVariableRegex = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_]+)\s*\}\}`)
+
+// ExpandVariables sustituye las variables {{nombre}} de un texto.
+// Las variables desconocidas se dejan tal cual.
+func ExpandVariables(text string, vars map[string]string) string {
+	return templateVariableRegex.ReplaceAllStringFunc(text, func(match string) string {
+		name := templateVariableRegex.FindStringSubmatch(match)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return match
+	})
+}
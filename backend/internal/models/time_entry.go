@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+)
+
+// TimeEntry es un periodo de trabajo registrado por un usuario sobre una tarea
+type TimeEntry struct {
+	ID        string        `json:"id" firestore:"id"`
+	TaskID    string        `json:"task_id" firestore:"task_id"`
+	UserID    string        `json:"user_id" firestore:"user_id"`                       // Usuario que registró el tiempo
+	GroupID   *string       `json:"group_id,omitempty" firestore:"group_id,omitempty"` // Grupo de la tarea (para agregados por grupo)
+	StartedAt time.Time     `json:"started_at" firestore:"started_at"`
+	EndedAt   *time.Time    `json:"ended_at,omitempty" firestore:"ended_at,omitempty"` // nil mientras el temporizador está en marcha
+	Duration  time.Duration `json:"duration" firestore:"duration"`
+	Note      string        `json:"note,omitempty" firestore:"note,omitempty"`
+	Source    string        `json:"source" firestore:"source"`
+	CreatedAt time.Time     `json:"created_at" firestore:"created_at"`
+}
+
+// Define valid time entry sources
+const (
+	TimeEntrySourceTimer  = "timer"
+	TimeEntrySourceManual = "manual"
+)
+
+// Validate verifica si los datos del registro de tiempo son válidos
+func (e *TimeEntry) Validate() bool {
+	if e.TaskID == "" || e.UserID == "" || e.StartedAt.IsZero() || len(e.Note) > 500 {
+		return false
+	}
+	if e.Source != TimeEntrySourceTimer && e.Source != TimeEntrySourceManual {
+		return false
+	}
+	if e.EndedAt != nil && (!e.EndedAt.After(e.StartedAt) || e.Duration <= 0) {
+		return false
+	}
+	return true
+}
+
+// IsRunning indica si el registro corresponde a un temporizador en marcha
+func (e *TimeEntry) IsRunning() bool {
+	return e.EndedAt == nil
+}
@@ -9,6 +9,15 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// UserSchemaVersion es la versión actual del documento de usuario. Los documentos
+// sin versión (0) son anteriores a los espacios de trabajo y pueden no tener role,
+// id ni created_at; se completan al leerlos (ver Normalize) y en Firestore con la
+// migración backfill-users.
+const UserSchemaVersion = 2
+
+// DefaultUserRole es el rol de los usuarios que no lo tienen guardado
+const DefaultUserRole = "user"
+
 type User struct {
 	ID        string    `json:"id" firestore:"id"`
 	Username  string    `json:"username" firestore:"username"`
@@ -16,6 +25,28 @@ type User struct {
 	Password  string    `json:"-" firestore:"password"` // "-" omits from JSON responses
 	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
 	Role      string    `json:"role" firestore:"role"`
+
+	WorkspaceID   string `json:"workspace_id" firestore:"workspace_id"`
+	WorkspaceRole string `json:"workspace_role" firestore:"workspace_role"` // Rol en el espacio de trabajo (admin o member)
+
+	Locale string `json:"locale,omitempty" firestore:"locale,omitempty"` // Idioma preferido (ver i18n.Locale); vacío si no lo eligió
+
+	SchemaVersion int `json:"-" firestore:"schema_version"` // Versión del documento (ver UserSchemaVersion)
+}
+
+// Normalize completa los campos que faltan en los documentos antiguos. docID es
+// el ID del documento, que prevalece sobre el campo id si no está vacío.
+func (u *User) Normalize(docID string) {
+	if docID != "" {
+		u.ID = docID
+	}
+	if u.Role == "" {
+		u.Role = DefaultUserRole
+	}
+	u.WorkspaceID = WorkspaceOf(u.WorkspaceID)
+	if u.WorkspaceRole == "" {
+		u.WorkspaceRole = WorkspaceRoleMember
+	}
 }
 
 // HashPassword encrypts the user's password using bcrypt
@@ -41,7 +72,7 @@ func (u *User) Validate() bool {
 	}
 
 	// Email format validation
-	if !isValidEmail(u.Email) {
+	if !IsValidEmail(u.Email) {
 		return false
 	}
 
@@ -61,8 +92,8 @@ func (u *User) Validate() bool {
 	return true
 }
 
-// isValidEmail checks if the email has a valid format
-func isValidEmail(email string) bool {
+// IsValidEmail checks if the email has a valid format
+func IsValidEmail(email string) bool {
 	// This is a simple regex for basic email validation
 	// For production, consider using a more comprehensive regex or a library
 	emailRegex := `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
@@ -116,6 +147,11 @@ func (u *User) ToMap() map[string]interface{} {
 		"password":   u.Password,
 		"created_at": u.CreatedAt,
 		"role":       u.Role,
+
+		"workspace_id":   u.WorkspaceID,
+		"workspace_role": u.WorkspaceRole,
+		"locale":         u.Locale,
+		"schema_version": UserSchemaVersion,
 	}
 }
 
@@ -139,4 +175,17 @@ func (u *User) FromMap(data map[string]interface{}) {
 	if role, ok := data["role"].(string); ok {
 		u.Role = role
 	}
+	if workspaceID, ok := data["workspace_id"].(string); ok {
+		u.WorkspaceID = workspaceID
+	}
+	if workspaceRole, ok := data["workspace_role"].(string); ok {
+		u.WorkspaceRole = workspaceRole
+	}
+	if locale, ok := data["locale"].(string); ok {
+		u.Locale = locale
+	}
+	if version, ok := data["schema_version"].(int64); ok {
+		u.SchemaVersion = int(version)
+	}
+	u.Normalize(u.ID)
 }
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"net/url"
+	"time"
+)
+
+// Eventos a los que se puede suscribir un webhook
+const (
+	WebhookEventTaskCreated        = "task.created"
+	WebhookEventTaskUpdated        = "task.updated"
+	WebhookEventTaskDeleted        = "task.deleted"
+	WebhookEventTaskCompleted      = "task.completed" // La tarea pasó a un estado final de su flujo
+	WebhookEventGroupUpdated       = "group.updated"
+	WebhookEventGroupDeleted       = "group.deleted"
+	WebhookEventGroupMemberAdded   = "group.member_added"
+	WebhookEventGroupMemberRemoved = "group.member_removed"
+)
+
+// WebhookEvents son todos los eventos admitidos
+var WebhookEvents = []string{
+	WebhookEventTaskCreated,
+	WebhookEventTaskUpdated,
+	WebhookEventTaskDeleted,
+	WebhookEventTaskCompleted,
+	WebhookEventGroupUpdated,
+	WebhookEventGroupDeleted,
+	WebhookEventGroupMemberAdded,
+	WebhookEventGroupMemberRemoved,
+}
+
+// Estados de una entrega
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook es un endpoint externo que recibe los eventos de un usuario o de un grupo
+type Webhook struct {
+	ID          string    `json:"id" firestore:"id"`
+	OwnerID     string    `json:"owner_id" firestore:"owner_id"`                     // Usuario que registró el webhook
+	GroupID     *string   `json:"group_id,omitempty" firestore:"group_id,omitempty"` // Grupo del webhook (nil = eventos del usuario)
+	WorkspaceID string    `json:"workspace_id" firestore:"workspace_id"`             // Espacio de trabajo del propietario
+	URL         string    `json:"url" firestore:"url"`
+	Events      []string  `json:"events" firestore:"events"`
+	Secret      string    `json:"-" firestore:"secret"` // Clave HMAC; solo se muestra al crear el webhook
+	Active      bool      `json:"active" firestore:"active"`
+	CreatedAt   time.Time `json:"created_at" firestore:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" firestore:"updated_at"`
+}
+
+// WebhookDelivery es el registro de una entrega de un evento a un webhook
+type WebhookDelivery struct {
+	ID             string     `json:"id" firestore:"id"`
+	WebhookID      string     `json:"webhook_id" firestore:"webhook_id"`
+	EventID        string     `json:"event_id" firestore:"event_id"`
+	EventType      string     `json:"event_type" firestore:"event_type"`
+	Payload        string     `json:"payload" firestore:"payload"` // Cuerpo JSON enviado
+	Status         string     `json:"status" firestore:"status"`
+	Attempts       int        `json:"attempts" firestore:"attempts"`
+	ResponseStatus int        `json:"response_status,omitempty" firestore:"response_status,omitempty"`
+	Error          string     `json:"error,omitempty" firestore:"error,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty" firestore:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty" firestore:"last_attempt_at,omitempty"`
+	RedeliveryOf   string     `json:"redelivery_of,omitempty" firestore:"redelivery_of,omitempty"` // Entrega original si es un reenvío manual
+	CreatedAt      time.Time  `json:"created_at" firestore:"created_at"`
+}
+
+// Validate verifica si los datos del webhook son válidos
+func (w *Webhook) Validate() bool {
+	if w.OwnerID == "" || len(w.Events) == 0 {
+		return false
+	}
+
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return false
+	}
+
+	for _, event := range w.Events {
+		if !IsWebhookEvent(event) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsGroupWebhook indica si el webhook pertenece a un grupo
+func (w *Webhook) IsGroupWebhook() bool {
+	return w.GroupID != nil && *w.GroupID != ""
+}
+
+// Subscribed indica si el webhook recibe el evento
+func (w *Webhook) Subscribed(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWebhookEvent indica si el evento es uno de los admitidos
+func IsWebhookEvent(event string) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
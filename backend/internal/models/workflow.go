@@ -0,0 +1,101 @@
+package models
+
+// WorkflowStatus es un estado dentro del flujo de trabajo de las tareas
+type WorkflowStatus struct {
+	Key   string `json:"key" firestore:"key"`
+	Name  string `json:"name" firestore:"name"`
+	Final bool   `json:"final" firestore:"final"` // La tarea se considera terminada en este estado
+}
+
+// Workflow define los estados que puede tener una tarea y las transiciones permitidas
+type Workflow struct {
+	Statuses []WorkflowStatus `json:"statuses" firestore:"statuses"`
+	// Estado origen -> estados destino permitidos. Si es nil se permite cualquier transición.
+	Transitions map[string][]string `json:"transitions,omitempty" firestore:"transitions,omitempty"`
+}
+
+// DefaultWorkflow devuelve el flujo de trabajo de las tareas personales
+func DefaultWorkflow() *Workflow {
+	return &Workflow{
+		Statuses: []WorkflowStatus{
+			{Key: TaskStatusPending, Name: "Pending"},
+			{Key: TaskStatusInProgress, Name: "In progress"},
+			{Key: TaskStatusCompleted, Name: "Completed", Final: true},
+		},
+	}
+}
+
+// HasStatus indica si el estado existe en el flujo de trabajo
+func (w *Workflow) HasStatus(key string) bool {
+	for _, s := range w.Statuses {
+		if s.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal indica si el estado marca la tarea como terminada
+func (w *Workflow) IsFinal(key string) bool {
+	for _, s := range w.Statuses {
+		if s.Key == key {
+			return s.Final
+		}
+	}
+	return false
+}
+
+// StatusName devuelve el nombre visible de un estado (la clave si no existe)
+func (w *Workflow) StatusName(key string) string {
+	for _, s := range w.Statuses {
+		if s.Key == key {
+			return s.Name
+		}
+	}
+	return key
+}
+
+// CanTransition indica si una tarea puede pasar del estado from al estado to
+func (w *Workflow) CanTransition(from, to string) bool {
+	if !w.HasStatus(to) {
+		return false
+	}
+	if from == to || w.Transitions == nil {
+		return true
+	}
+	for _, allowed := range w.Transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate verifica si el flujo de trabajo es coherente
+func (w *Workflow) Validate() bool {
+	if len(w.Statuses) == 0 || len(w.Statuses) > 20 {
+		return false
+	}
+
+	seen := make(map[string]bool)
+	for _, s := range w.Statuses {
+		if s.Key == "" || len(s.Key) > 50 || s.Name == "" || len(s.Name) > 100 || seen[s.Key] {
+			return false
+		}
+		seen[s.Key] = true
+	}
+
+	// Las transiciones solo pueden referirse a estados definidos
+	for from, targets := range w.Transitions {
+		if !seen[from] {
+			return false
+		}
+		for _, to := range targets {
+			if !seen[to] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
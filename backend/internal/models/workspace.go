@@ -0,0 +1,71 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultWorkspaceID es el espacio de trabajo de los usuarios que se registran sin
+// invitación y de los datos anteriores a los espacios de trabajo
+const DefaultWorkspaceID = "default"
+
+// Roles de un usuario en su espacio de trabajo
+const (
+	WorkspaceRoleAdmin  = "admin"  // Gestiona el espacio de trabajo, sus miembros y sus invitaciones
+	WorkspaceRoleMember = "member" // Usa el espacio de trabajo
+)
+
+// Workspace es un espacio de trabajo (tenant). Sus usuarios, tareas y grupos están
+// separados de los del resto de espacios de trabajo.
+type Workspace struct {
+	ID        string    `json:"id" firestore:"id"`
+	Name      string    `json:"name" firestore:"name"`
+	CreatedBy string    `json:"created_by" firestore:"created_by"`
+	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" firestore:"updated_at"`
+}
+
+// Validate verifica si los datos del espacio de trabajo son válidos
+func (w *Workspace) Validate() bool {
+	name := strings.TrimSpace(w.Name)
+	return name != "" && len(name) <= 100
+}
+
+// IsWorkspaceRole indica si role es un rol de espacio de trabajo válido
+func IsWorkspaceRole(role string) bool {
+	return role == WorkspaceRoleAdmin || role == WorkspaceRoleMember
+}
+
+// WorkspaceOf devuelve el espacio de trabajo de un documento; los que no tienen
+// ninguno son anteriores a los espacios de trabajo y pertenecen al de por defecto
+func WorkspaceOf(workspaceID string) string {
+	if workspaceID == "" {
+		return DefaultWorkspaceID
+	}
+	return workspaceID
+}
+
+// SameWorkspace indica si dos documentos pertenecen al mismo espacio de trabajo
+func SameWorkspace(a, b string) bool {
+	return WorkspaceOf(a) == WorkspaceOf(b)
+}
+
+// WorkspaceInvite es la invitación de una dirección de correo a un espacio de
+// trabajo. El token solo se envía por correo; se guarda su hash, que es también
+// el ID del documento.
+type WorkspaceInvite struct {
+	ID          string     `json:"id" firestore:"id"`
+	WorkspaceID string     `json:"workspace_id" firestore:"workspace_id"`
+	Email       string     `json:"email" firestore:"email"` // En minúsculas
+	Role        string     `json:"role" firestore:"role"`
+	InviterID   string     `json:"inviter_id" firestore:"inviter_id"`
+	CreatedAt   time.Time  `json:"created_at" firestore:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at" firestore:"expires_at"`
+	AcceptedBy  *string    `json:"accepted_by,omitempty" firestore:"accepted_by,omitempty"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty" firestore:"accepted_at,omitempty"`
+}
+
+// Usable indica si la invitación todavía permite registrarse en el espacio de trabajo
+func (i *WorkspaceInvite) Usable(now time.Time) bool {
+	return i.AcceptedBy == nil && now.Before(i.ExpiresAt)
+}
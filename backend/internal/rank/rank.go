@@ -0,0 +1,164 @@
+// Package rank genera claves de orden lexicográfico (fractional indexing).
+// Entre dos claves siempre existe otra, de modo que mover un elemento solo
+// requiere reescribir la clave de ese elemento.
+//
+// Las claves crecen al insertar repetidamente en el mismo hueco; cuando alguna
+// supera MaxLength conviene redistribuir la lista completa con Spread.
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+// digits es el alfabeto de las claves, en orden ASCII creciente
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// MaxLength es la longitud de clave a partir de la cual conviene redistribuir la
+// lista con Spread
+const MaxLength = 12
+
+// ErrInvalidRange se devuelve cuando prev no es menor que next o alguna clave no es válida
+var ErrInvalidRange = errors.New("rank: invalid range")
+
+// Between devuelve una clave estrictamente mayor que prev y menor que next.
+// Un prev vacío significa "antes de todo" y un next vacío "después de todo".
+func Between(prev, next string) (string, error) {
+	if !Valid(prev) || !Valid(next) {
+		return "", ErrInvalidRange
+	}
+	if next != "" && prev >= next {
+		return "", ErrInvalidRange
+	}
+
+	switch {
+	case prev == "" && next == "":
+		return digits[len(digits)/2 : len(digits)/2+1], nil
+	case next == "":
+		return after(prev), nil
+	case prev == "":
+		return before(next), nil
+	default:
+		return midpoint(prev, next, true), nil
+	}
+}
+
+// Spread devuelve n claves crecientes repartidas de manera uniforme, todas con la
+// longitud mínima que deja al menos una clave libre entre cada par
+func Spread(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+
+	length, space := 1, len(digits)
+	for space < 2*(n+1) {
+		length++
+		space *= len(digits)
+	}
+
+	step := space / (n + 1)
+	keys := make([]string, n)
+	for i := range keys {
+		value := (i + 1) * step
+		if value%len(digits) == 0 {
+			value++ // Las claves no pueden terminar en el dígito mínimo
+		}
+		key := make([]byte, length)
+		for j := length - 1; j >= 0; j-- {
+			key[j] = digits[value%len(digits)]
+			value /= len(digits)
+		}
+		keys[i] = string(key)
+	}
+	return keys
+}
+
+// Valid indica si una clave puede usarse como límite: solo contiene dígitos del
+// alfabeto y no termina en el dígito mínimo (lo que impediría insertar antes de ella)
+func Valid(key string) bool {
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return false
+		}
+	}
+	return key == "" || key[len(key)-1] != digits[0]
+}
+
+// after devuelve una clave mayor que key avanzando un solo dígito. Al añadir
+// siempre al final las claves crecen un carácter cada len(digits) inserciones,
+// en lugar de uno cada pocas como al tomar el punto medio.
+func after(key string) string {
+	if key == "" {
+		return digits[1:2]
+	}
+	i := strings.IndexByte(digits, key[0])
+	if i < len(digits)-1 {
+		return digits[i+1 : i+2]
+	}
+	return key[:1] + after(key[1:])
+}
+
+// before es el equivalente de after al insertar siempre al principio
+func before(key string) string {
+	i := strings.IndexByte(digits, key[0])
+	switch {
+	case i > 1:
+		return digits[i-1 : i]
+	case i == 1 && len(key) > 1:
+		return key[:1]
+	case i == 1:
+		return digits[:1] + digits[len(digits)-1:]
+	default:
+		// key empieza por el dígito mínimo, así que no termina ahí (ver Valid)
+		return key[:1] + before(key[1:])
+	}
+}
+
+// midpoint calcula la clave intermedia; hasNext es false cuando next es +infinito
+func midpoint(prev, next string, hasNext bool) string {
+	if hasNext {
+		// Conservar el prefijo común
+		n := 0
+		for n < len(next) && digitAt(prev, n) == next[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(prev) {
+				rest = prev[n:]
+			}
+			return next[:n] + midpoint(rest, next[n:], true)
+		}
+	}
+
+	digitPrev := 0
+	if prev != "" {
+		digitPrev = strings.IndexByte(digits, prev[0])
+	}
+	digitNext := len(digits)
+	if hasNext {
+		digitNext = strings.IndexByte(digits, next[0])
+	}
+
+	if digitNext-digitPrev > 1 {
+		return string(digits[(digitPrev+digitNext+1)/2])
+	}
+
+	// Los primeros dígitos son consecutivos
+	if hasNext && len(next) > 1 {
+		return next[:1]
+	}
+	rest := ""
+	if prev != "" {
+		rest = prev[1:]
+	}
+	return string(digits[digitPrev]) + midpoint(rest, "", false)
+}
+
+// digitAt devuelve el dígito i de la clave, rellenando con el dígito mínimo
+func digitAt(key string, i int) byte {
+	if i < len(key) {
+		return key[i]
+	}
+	return digits[0]
+}
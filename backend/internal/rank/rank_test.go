@@ -0,0 +1,88 @@
+package rank
+
+import "testing"
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		prev, next string
+	}{
+		{"", ""},
+		{"i", ""},
+		{"z", ""},
+		{"", "i"},
+		{"", "1"},
+		{"", "01"},
+		{"a", "b"},
+		{"a", "a1"},
+		{"az", "b"},
+		{"1", "2"},
+	}
+
+	for _, tt := range tests {
+		key, err := Between(tt.prev, tt.next)
+		if err != nil {
+			t.Errorf("Between(%q, %q) error = %v", tt.prev, tt.next, err)
+			continue
+		}
+		if !Valid(key) || key <= tt.prev || (tt.next != "" && key >= tt.next) {
+			t.Errorf("Between(%q, %q) = %q", tt.prev, tt.next, key)
+		}
+	}
+
+	for _, tt := range []struct{ prev, next string }{{"b", "a"}, {"a", "a"}, {"a0", ""}, {"", "A"}} {
+		if _, err := Between(tt.prev, tt.next); err != ErrInvalidRange {
+			t.Errorf("Between(%q, %q) error = %v, want ErrInvalidRange", tt.prev, tt.next, err)
+		}
+	}
+}
+
+// Añadir siempre al final o al principio alarga las claves despacio, y Spread
+// las vuelve a acortar
+func TestRepeatedInserts(t *testing.T) {
+	last := ""
+	for i := 0; i < 1000; i++ {
+		key, err := Between(last, "")
+		if err != nil || key <= last {
+			t.Fatalf("Between(%q, \"\") = %q, %v", last, key, err)
+		}
+		last = key
+	}
+	if len(last) > 40 {
+		t.Errorf("1000 appends produced a key of length %d", len(last))
+	}
+
+	first := "i"
+	for i := 0; i < 1000; i++ {
+		key, err := Between("", first)
+		if err != nil || key >= first {
+			t.Fatalf("Between(\"\", %q) = %q, %v", first, key, err)
+		}
+		first = key
+	}
+	if len(first) > 40 {
+		t.Errorf("1000 prepends produced a key of length %d", len(first))
+	}
+}
+
+func TestSpread(t *testing.T) {
+	for _, n := range []int{0, 1, 17, 18, 1000, 50000} {
+		keys := Spread(n)
+		if len(keys) != n {
+			t.Fatalf("Spread(%d) returned %d keys", n, len(keys))
+		}
+		for i, key := range keys {
+			if !Valid(key) || len(key) > MaxLength {
+				t.Fatalf("Spread(%d)[%d] = %q", n, i, key)
+			}
+			if i == 0 {
+				continue
+			}
+			if key <= keys[i-1] {
+				t.Fatalf("Spread(%d) is not increasing at %d: %q, %q", n, i, keys[i-1], key)
+			}
+			if len(key) != len(keys[0]) {
+				t.Fatalf("Spread(%d) keys have different lengths: %q, %q", n, keys[0], key)
+			}
+		}
+	}
+}
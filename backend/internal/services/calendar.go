@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"task-manager-backend/internal/database"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrCalendarFeedNotFound indica que el token del feed no existe o fue revocado
+var ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+
+// CalendarFeed es el feed iCalendar de un usuario. El token solo se muestra al
+// generarlo; se guarda su hash, que es también el ID del documento.
+type CalendarFeed struct {
+	UserID         string     `json:"-" firestore:"user_id"`
+	CreatedAt      time.Time  `json:"created_at" firestore:"created_at"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty" firestore:"last_accessed_at,omitempty"`
+}
+
+// CalendarService proporciona métodos para gestionar los feeds de calendario
+type CalendarService struct{}
+
+// NewCalendarService crea una nueva instancia de CalendarService
+func NewCalendarService() *CalendarService {
+	return &CalendarService{}
+}
+
+// GetFeed obtiene el feed activo de un usuario, o nil si no tiene ninguno
+func (s *CalendarService) GetFeed(userID string) (*CalendarFeed, error) {
+	ctx := context.Background()
+
+	docs, err := database.Client.Collection("calendar_feeds").Where("user_id", "==", userID).Limit(1).Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error fetching calendar feed: %v", err)
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	var feed CalendarFeed
+	if err := docs[0].DataTo(&feed); err != nil {
+		return nil, err
+	}
+
+	return &feed, nil
+}
+
+// RegenerateToken crea un token nuevo para el feed del usuario y revoca el anterior.
+// Devuelve el token en claro, que no se puede recuperar después.
+func (s *CalendarService) RegenerateToken(userID string) (string, *CalendarFeed, error) {
+	ctx := context.Background()
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	feed := &CalendarFeed{
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+
+	feedsRef := database.Client.Collection("calendar_feeds")
+	err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(feedsRef.Where("user_id", "==", userID)).GetAll()
+		if err != nil {
+			return err
+		}
+		for _, doc := range docs {
+			if err := tx.Delete(doc.Ref); err != nil {
+				return err
+			}
+		}
+		return tx.Create(feedsRef.Doc(hashToken(token)), feed)
+	})
+	if err != nil {
+		log.Printf("Error regenerating calendar token: %v", err)
+		return "", nil, err
+	}
+
+	return token, feed, nil
+}
+
+// RevokeToken elimina el feed del usuario; su URL deja de funcionar
+func (s *CalendarService) RevokeToken(userID string) error {
+	ctx := context.Background()
+
+	docs, err := database.Client.Collection("calendar_feeds").Where("user_id", "==", userID).Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error fetching calendar feeds: %v", err)
+		return err
+	}
+	if len(docs) == 0 {
+		return ErrCalendarFeedNotFound
+	}
+
+	for _, doc := range docs {
+		if _, err := doc.Ref.Delete(ctx); err != nil {
+			log.Printf("Error revoking calendar feed: %v", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UserIDForToken obtiene el usuario propietario de un token y registra el acceso
+func (s *CalendarService) UserIDForToken(token string) (string, error) {
+	ctx := context.Background()
+
+	if token == "" {
+		return "", ErrCalendarFeedNotFound
+	}
+
+	ref := database.Client.Collection("calendar_feeds").Doc(hashToken(token))
+	doc, err := ref.Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return "", ErrCalendarFeedNotFound
+		}
+		log.Printf("Error fetching calendar feed: %v", err)
+		return "", err
+	}
+
+	var feed CalendarFeed
+	if err := doc.DataTo(&feed); err != nil {
+		return "", err
+	}
+
+	// El registro del último acceso no debe impedir servir el feed
+	if _, err := ref.Update(ctx, []firestore.Update{{Path: "last_accessed_at", Value: time.Now()}}); err != nil {
+		log.Printf("Error updating calendar feed access time: %v", err)
+	}
+
+	return feed.UserID, nil
+}
+
+// hashToken devuelve el hash con el que se guarda un token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
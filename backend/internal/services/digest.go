@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/i18n"
+	"task-manager-backend/internal/models"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxDigestGroupTasks es el número de tareas recientes que se muestran por grupo
+const maxDigestGroupTasks = 10
+
+var digestTemplate = mustParseMailTemplate("digest")
+
+// Digest es el contenido del resumen por correo de un usuario. Las fechas están
+// en su zona horaria.
+type Digest struct {
+	Subject     string
+	Username    string
+	Email       string
+	Locale      i18n.Locale // Idioma del usuario (ver UserLocale)
+	Frequency   string
+	Since       time.Time // Inicio del periodo que cubre el resumen
+	GeneratedAt time.Time
+	Overdue     []DigestTask
+	DueToday    []DigestTask
+	Assigned    []DigestTask // Asignadas al usuario durante el periodo
+	Groups      []DigestGroup
+}
+
+// DigestTask es una tarea tal como aparece en el resumen
+type DigestTask struct {
+	ID       string
+	Title    string
+	Status   string
+	Priority string
+	Group    string
+	DueAt    *time.Time
+}
+
+// DigestGroup es la actividad reciente de un grupo del usuario
+type DigestGroup struct {
+	Name  string
+	Tasks []DigestTask
+}
+
+// Empty indica si el resumen no tiene nada que contar
+func (d *Digest) Empty() bool {
+	return len(d.Overdue) == 0 && len(d.DueToday) == 0 && len(d.Assigned) == 0 && len(d.Groups) == 0
+}
+
+// Render genera el cuerpo en texto plano y en HTML en el idioma del usuario
+func (d *Digest) Render() (string, string, error) {
+	return digestTemplate.Render(d.Locale, d)
+}
+
+// DigestService proporciona métodos para configurar y generar el resumen por correo
+type DigestService struct {
+	groupService *GroupService
+}
+
+// NewDigestService crea una nueva instancia de DigestService
+func NewDigestService() *DigestService {
+	return &DigestService{groupService: NewGroupService()}
+}
+
+// GetSettings obtiene la configuración del resumen de un usuario (la de por defecto
+// si nunca la ha cambiado)
+func (s *DigestService) GetSettings(userID string) (*models.DigestSettings, error) {
+	ctx := context.Background()
+
+	doc, err := database.Client.Collection("digest_settings").Doc(userID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return models.DefaultDigestSettings(userID), nil
+		}
+		log.Printf("Error getting digest settings: %v", err)
+		return nil, err
+	}
+
+	var settings models.DigestSettings
+	if err := doc.DataTo(&settings); err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// UpdateSettings guarda la configuración y programa el próximo envío
+func (s *DigestService) UpdateSettings(settings *models.DigestSettings) error {
+	ctx := context.Background()
+
+	now := time.Now()
+	settings.UpdatedAt = now
+	settings.NextSendAt = nil
+	if settings.Enabled {
+		next := settings.NextSend(now)
+		settings.NextSendAt = &next
+	}
+
+	if _, err := database.Client.Collection("digest_settings").Doc(settings.UserID).Set(ctx, settings); err != nil {
+		log.Printf("Error saving digest settings: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// DueSettings obtiene las configuraciones cuyo envío ya toca
+func (s *DigestService) DueSettings(now time.Time) ([]models.DigestSettings, error) {
+	ctx := context.Background()
+
+	docs, err := database.Client.Collection("digest_settings").Where("next_send_at", "<=", now).Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error fetching due digests: %v", err)
+		return nil, err
+	}
+
+	var due []models.DigestSettings
+	for _, doc := range docs {
+		var settings models.DigestSettings
+		if err := doc.DataTo(&settings); err != nil {
+			log.Printf("Error converting document to DigestSettings: %v", err)
+			continue
+		}
+		if settings.Enabled {
+			due = append(due, settings)
+		}
+	}
+
+	return due, nil
+}
+
+// ClaimDigest reserva el envío pendiente de un usuario: programa el siguiente y
+// devuelve la configuración anterior. Devuelve nil si ya no hay envío pendiente
+// (p. ej. porque otra instancia lo reservó antes).
+func (s *DigestService) ClaimDigest(userID string, now time.Time) (*models.DigestSettings, error) {
+	ctx := context.Background()
+	settingsRef := database.Client.Collection("digest_settings").Doc(userID)
+
+	var claimed *models.DigestSettings
+	err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		claimed = nil
+
+		doc, err := tx.Get(settingsRef)
+		if err != nil {
+			return err
+		}
+
+		var settings models.DigestSettings
+		if err := doc.DataTo(&settings); err != nil {
+			return err
+		}
+		if !settings.Enabled || settings.NextSendAt == nil || settings.NextSendAt.After(now) {
+			return nil
+		}
+
+		previous := settings
+		next := settings.NextSend(now)
+		claimed = &previous
+		return tx.Update(settingsRef, []firestore.Update{
+			{Path: "last_sent_at", Value: now},
+			{Path: "next_send_at", Value: next},
+		})
+	})
+	if err != nil {
+		log.Printf("Error claiming digest: %v", err)
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// BuildDigest genera el resumen de un usuario. Cubre desde el último envío o, si
+// no lo hubo, el periodo de la frecuencia elegida.
+func (s *DigestService) BuildDigest(settings *models.DigestSettings, now time.Time) (*Digest, error) {
+	ctx := context.Background()
+	loc := settings.Location()
+
+	userDoc, err := database.Client.Collection("users").Doc(settings.UserID).Get(ctx)
+	if err != nil {
+		log.Printf("Error fetching digest user: %v", err)
+		return nil, err
+	}
+	user, err := DecodeUser(userDoc)
+	if err != nil {
+		return nil, err
+	}
+	if user.Email == "" {
+		return nil, errors.New("user has no email address")
+	}
+
+	since := now.Add(-settings.Period())
+	if settings.LastSentAt != nil && settings.LastSentAt.After(since) {
+		since = *settings.LastSentAt
+	}
+
+	local := now.In(loc)
+	endOfDay := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+
+	locale := i18n.Of(user.Locale)
+	digest := &Digest{
+		Subject: i18n.T(locale, "email.digest.subject",
+			i18n.T(locale, "digest."+settings.Frequency), i18n.FormatTime(locale, local, "January 2")),
+		Username:    user.Username,
+		Email:       user.Email,
+		Locale:      locale,
+		Frequency:   settings.Frequency,
+		Since:       since.In(loc),
+		GeneratedAt: local,
+	}
+
+	workspaceID := models.WorkspaceOf(user.WorkspaceID)
+	groups, err := s.groupService.InWorkspace(workspaceID).GetUserGroups(settings.UserID)
+	if err != nil {
+		return nil, err
+	}
+	groupNames := make(map[string]string, len(groups))
+	workflows := make(map[string]*models.Workflow, len(groups))
+	for _, group := range groups {
+		groupNames[group.ID] = group.Name
+		workflows[group.ID] = group.TaskWorkflow()
+	}
+
+	view := func(task *models.Task) (DigestTask, bool) {
+		workflow := models.DefaultWorkflow()
+		item := DigestTask{ID: task.ID, Title: task.Title, Priority: task.Priority}
+		if task.GroupID != nil && *task.GroupID != "" {
+			item.Group = groupNames[*task.GroupID]
+			if w, ok := workflows[*task.GroupID]; ok {
+				workflow = w
+			}
+		}
+		item.Status = workflow.StatusName(task.Status)
+		if due := task.DueAt(); due != nil {
+			dueLocal := due.In(loc)
+			item.DueAt = &dueLocal
+		}
+		return item, workflow.IsFinal(task.Status)
+	}
+
+	tasks, err := s.userTasks(ctx, workspaceID, settings.UserID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		task := &tasks[i]
+		item, done := view(task)
+
+		if task.AssignedTo != nil && *task.AssignedTo == settings.UserID &&
+			task.AssignedAt != nil && !task.AssignedAt.Before(since) && task.CreatedBy != settings.UserID {
+			digest.Assigned = append(digest.Assigned, item)
+		}
+		if done || item.DueAt == nil {
+			continue
+		}
+		switch {
+		case item.DueAt.Before(now):
+			digest.Overdue = append(digest.Overdue, item)
+		case item.DueAt.Before(endOfDay):
+			digest.DueToday = append(digest.DueToday, item)
+		}
+	}
+	sortDigestTasks(digest.Overdue)
+	sortDigestTasks(digest.DueToday)
+
+	for _, group := range groups {
+		docs, err := database.InWorkspace(database.Client.Collection("tasks").Query, workspaceID).
+			Where("group_id", "==", group.ID).
+			Documents(ctx).GetAll()
+		if err != nil {
+			log.Printf("Error fetching group tasks for digest: %v", err)
+			return nil, err
+		}
+
+		var recent []models.Task
+		for _, doc := range docs {
+			var task models.Task
+			if err := doc.DataTo(&task); err != nil {
+				continue
+			}
+			if !task.UpdatedAt.Before(since) {
+				recent = append(recent, task)
+			}
+		}
+		if len(recent) == 0 {
+			continue
+		}
+
+		sort.Slice(recent, func(i, j int) bool { return recent[i].UpdatedAt.After(recent[j].UpdatedAt) })
+		if len(recent) > maxDigestGroupTasks {
+			recent = recent[:maxDigestGroupTasks]
+		}
+
+		activity := DigestGroup{Name: group.Name}
+		for i := range recent {
+			item, _ := view(&recent[i])
+			activity.Tasks = append(activity.Tasks, item)
+		}
+		digest.Groups = append(digest.Groups, activity)
+	}
+
+	return digest, nil
+}
+
+// userTasks obtiene las tareas que el usuario creó, en las que colabora o que tiene asignadas
+func (s *DigestService) userTasks(ctx context.Context, workspaceID, userID string) ([]models.Task, error) {
+	tasksRef := database.InWorkspace(database.Client.Collection("tasks").Query, workspaceID)
+	queries := []firestore.Query{
+		tasksRef.Where("user_id", "==", userID),
+		tasksRef.Where("arr_collaborators", "array-contains", userID),
+		tasksRef.Where("assigned_to", "==", userID),
+	}
+
+	seen := map[string]bool{}
+	var tasks []models.Task
+	for _, q := range queries {
+		docs, err := q.Documents(ctx).GetAll()
+		if err != nil {
+			log.Printf("Error fetching tasks for digest: %v", err)
+			return nil, err
+		}
+		for _, doc := range docs {
+			if seen[doc.Ref.ID] {
+				continue
+			}
+			var task models.Task
+			if err := doc.DataTo(&task); err != nil {
+				continue
+			}
+			seen[doc.Ref.ID] = true
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks, nil
+}
+
+// sortDigestTasks ordena las tareas por fecha de vencimiento
+func sortDigestTasks(tasks []DigestTask) {
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].DueAt.Before(*tasks[j].DueAt) })
+}
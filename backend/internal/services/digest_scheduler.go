@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"task-manager-backend/internal/mailer"
+	"task-manager-backend/internal/models"
+	"time"
+)
+
+// digestInterval es cada cuánto se buscan resúmenes pendientes de envío
+const digestInterval = 5 * time.Minute
+
+// DigestScheduler envía los resúmenes por correo cuando llega su hora
+type DigestScheduler struct {
+	digestService *DigestService
+	mailer        mailer.Mailer
+	ctx           context.Context
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+}
+
+// NewDigestScheduler crea un DigestScheduler que envía los correos con m
+func NewDigestScheduler(m mailer.Mailer) *DigestScheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &DigestScheduler{
+		digestService: NewDigestService(),
+		mailer:        m,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// Start arranca la revisión periódica de los resúmenes pendientes
+func (d *DigestScheduler) Start() {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.run()
+
+		ticker := time.NewTicker(digestInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.ctx.Done():
+				return
+			case <-ticker.C:
+				d.run()
+			}
+		}
+	}()
+}
+
+// Stop detiene el scheduler después del envío en curso
+func (d *DigestScheduler) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// run envía los resúmenes cuya hora ya pasó. Cada envío se reserva antes de
+// enviarlo, de modo que con varias instancias un resumen no se envía dos veces;
+// si el envío falla, se pierde ese resumen y se envía el siguiente.
+func (d *DigestScheduler) run() {
+	now := time.Now()
+
+	due, err := d.digestService.DueSettings(now)
+	if err != nil {
+		return
+	}
+
+	for _, settings := range due {
+		if d.ctx.Err() != nil {
+			return
+		}
+
+		claimed, err := d.digestService.ClaimDigest(settings.UserID, now)
+		if err != nil || claimed == nil {
+			continue
+		}
+
+		if err := d.send(claimed, now); err != nil {
+			log.Printf("Error sending digest to user %s: %v", settings.UserID, err)
+		}
+	}
+}
+
+func (d *DigestScheduler) send(settings *models.DigestSettings, now time.Time) error {
+	digest, err := d.digestService.BuildDigest(settings, now)
+	if err != nil {
+		return err
+	}
+	// No se envían resúmenes vacíos
+	if digest.Empty() {
+		return nil
+	}
+
+	text, html, err := digest.Render()
+	if err != nil {
+		return err
+	}
+
+	return d.mailer.Send(d.ctx, mailer.Message{
+		To:      []string{digest.Email},
+		Subject: digest.Subject,
+		Text:    text,
+		HTML:    html,
+	})
+}
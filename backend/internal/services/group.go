@@ -11,17 +11,111 @@ import (
 	"cloud.google.com/go/firestore"
 	"github.com/google/uuid"
 	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-// GroupService proporciona métodos para gestionar grupos
-type GroupService struct{}
+// ErrVersionConflict indica que el documento cambió desde que el cliente lo leyó
+var ErrVersionConflict = errors.New("version conflict")
+
+// ErrStatusInUse indica que hay tareas del grupo en un estado que el nuevo flujo de trabajo no incluye
+var ErrStatusInUse = errors.New("some tasks of this group use a status that is not part of the new workflow")
+
+// ErrAlreadyMember indica que el usuario ya pertenece al grupo
+var ErrAlreadyMember = errors.New("user is already a member of this group")
+
+// ErrUserNotFound indica que el usuario no existe
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrGroupNotFound indica que el grupo no existe en el espacio de trabajo
+var ErrGroupNotFound = errors.New("group not found")
+
+// ErrGroupForbidden indica que el rol del usuario en el grupo no permite la acción
+var ErrGroupForbidden = errors.New("you don't have permission to do this in this group")
+
+// ErrNotMember indica que el usuario no pertenece al grupo
+var ErrNotMember = errors.New("user is not a member of this group")
+
+// ErrInvalidRole indica que el rol no existe o no se puede asignar directamente
+var ErrInvalidRole = errors.New("invalid role")
+
+// ErrInvalidGroup indica que los datos del grupo no son válidos
+var ErrInvalidGroup = errors.New("invalid group data")
+
+// ErrOwnerRole indica que la acción no se puede aplicar al propietario del grupo,
+// que antes debe transferir la propiedad
+var ErrOwnerRole = errors.New("the owner cannot be removed or change role; transfer ownership first")
+
+// ErrInheritedMember indica que el usuario hereda la pertenencia de un grupo
+// antecesor y solo se puede eliminar allí
+var ErrInheritedMember = errors.New("the user is a member of a parent group; remove them there")
+
+// ErrGroupCycle indica que el grupo no se puede mover dentro de sí mismo o de uno de sus subgrupos
+var ErrGroupCycle = errors.New("a group cannot be moved into itself or one of its subgroups")
+
+// ErrGroupTooDeep indica que la jerarquía superaría models.MaxGroupDepth niveles
+var ErrGroupTooDeep = errors.New("the group hierarchy would be too deep")
+
+// ErrHasSubgroups indica que el grupo tiene subgrupos, que hay que mover o eliminar antes
+var ErrHasSubgroups = errors.New("the group has subgroups; move or delete them first")
+
+// VersionCheck decide si una escritura puede aplicarse sobre la versión actual
+// de un documento. Un VersionCheck nil no impone ninguna condición.
+type VersionCheck func(current int64) bool
+
+// GroupService proporciona métodos para gestionar grupos. Si tiene un espacio de
+// trabajo (ver InWorkspace) solo ve los grupos, usuarios y tareas de ese espacio;
+// si no, ve los de todos, como necesitan los procesos en segundo plano.
+type GroupService struct {
+	workspaceID string
+}
 
 // NewGroupService crea una nueva instancia de GroupService
 func NewGroupService() *GroupService {
 	return &GroupService{}
 }
 
-// CreateGroup crea un nuevo grupo
+// InWorkspace devuelve un GroupService limitado al espacio de trabajo
+func (s *GroupService) InWorkspace(workspaceID string) *GroupService {
+	return &GroupService{workspaceID: workspaceID}
+}
+
+// scoped limita la consulta al espacio de trabajo del servicio (ver database.InWorkspace)
+func (s *GroupService) scoped(query firestore.Query) firestore.Query {
+	if s.workspaceID == "" {
+		return query
+	}
+	return database.InWorkspace(query, s.workspaceID)
+}
+
+// inScope indica si un documento de ese espacio de trabajo es visible para el servicio
+func (s *GroupService) inScope(workspaceID string) bool {
+	return s.workspaceID == "" || models.SameWorkspace(workspaceID, s.workspaceID)
+}
+
+// decodeGroup convierte el documento en un grupo. Los grupos de otro espacio de
+// trabajo se tratan como si no existieran.
+func (s *GroupService) decodeGroup(doc *firestore.DocumentSnapshot, group *models.Group) error {
+	if err := doc.DataTo(group); err != nil {
+		return err
+	}
+	if !s.inScope(group.WorkspaceID) {
+		return ErrGroupNotFound
+	}
+	return nil
+}
+
+// loadGroup convierte el documento en un grupo y calcula los roles que hereda de
+// sus antecesores. tx puede ser nil.
+func (s *GroupService) loadGroup(ctx context.Context, tx *firestore.Transaction, doc *firestore.DocumentSnapshot, group *models.Group) error {
+	if err := s.decodeGroup(doc, group); err != nil {
+		return err
+	}
+	return InheritRoles(ctx, tx, group)
+}
+
+// CreateGroup crea un nuevo grupo. Un subgrupo (ParentID) solo lo puede crear
+// quien puede editar el grupo padre, y sus miembros heredan la pertenencia.
 func (s *GroupService) CreateGroup(group *models.Group) error {
 	ctx := context.Background()
 
@@ -30,8 +124,34 @@ func (s *GroupService) CreateGroup(group *models.Group) error {
 		group.ID = uuid.New().String()
 	}
 
+	if s.workspaceID != "" {
+		group.WorkspaceID = s.workspaceID
+	}
+
+	// La ruta se calcula a partir del padre, nunca se toma del cliente
+	group.Path = []string{}
+	group.Inherited = nil
+	if group.IsRoot() {
+		group.ParentID = nil
+	} else {
+		parent, err := s.GetGroupByID(*group.ParentID)
+		if err != nil {
+			return err
+		}
+		if !parent.Can(group.CreatorID, models.PermEditGroup) {
+			return ErrGroupForbidden
+		}
+		if len(parent.Path)+1 >= models.MaxGroupDepth {
+			return ErrGroupTooDeep
+		}
+		group.Path = append(append(group.Path, parent.Path...), parent.ID)
+		group.WorkspaceID = parent.WorkspaceID
+	}
+
 	// Establecer timestamp de creación
 	group.CreatedAt = time.Now()
+	group.UpdatedAt = group.CreatedAt
+	group.Version = 1
 
 	// Guardar el grupo en Firestore
 	_, err := database.Client.Collection("groups").Doc(group.ID).Set(ctx, group)
@@ -40,7 +160,7 @@ func (s *GroupService) CreateGroup(group *models.Group) error {
 		return err
 	}
 
-	return nil
+	return InheritRoles(ctx, nil, group)
 }
 
 // GetGroupByID obtiene un grupo por su ID
@@ -49,25 +169,81 @@ func (s *GroupService) GetGroupByID(groupID string) (*models.Group, error) {
 
 	doc, err := database.Client.Collection("groups").Doc(groupID).Get(ctx)
 	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrGroupNotFound
+		}
 		log.Printf("Error getting group from Firestore: %v", err)
 		return nil, err
 	}
 
 	var group models.Group
-	if err := doc.DataTo(&group); err != nil {
-		log.Printf("Error converting Firestore document to Group: %v", err)
+	if err := s.loadGroup(ctx, nil, doc, &group); err != nil {
+		if err != ErrGroupNotFound {
+			log.Printf("Error converting Firestore document to Group: %v", err)
+		}
 		return nil, err
 	}
 
 	return &group, nil
 }
 
-// GetUserGroups obtiene todos los grupos a los que pertenece un usuario
+// GetUserGroups obtiene los grupos a los que pertenece un usuario, directamente o
+// por herencia (los subgrupos de sus grupos), con los roles heredados calculados.
+// Los grupos se ordenan por jerarquía: cada grupo va seguido de sus subgrupos (ver
+// models.GroupTree).
 func (s *GroupService) GetUserGroups(userID string) ([]models.Group, error) {
 	ctx := context.Background()
+	groupsRef := database.Client.Collection("groups")
 
 	// Buscar grupos donde el usuario es miembro
-	iter := database.Client.Collection("groups").Where("members", "array-contains", userID).Documents(ctx)
+	groups, err := s.queryGroups(ctx, s.scoped(groupsRef.Where("members", "array-contains", userID)))
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(groups))
+	directIDs := make([]string, 0, len(groups))
+	for _, group := range groups {
+		seen[group.ID] = true
+		directIDs = append(directIDs, group.ID)
+	}
+
+	// Añadir los subgrupos de esos grupos. Firestore admite como máximo 30 valores
+	// en una consulta "array-contains-any".
+	for start := 0; start < len(directIDs); start += 30 {
+		end := start + 30
+		if end > len(directIDs) {
+			end = len(directIDs)
+		}
+
+		subgroups, err := s.queryGroups(ctx, s.scoped(groupsRef.Where("path", "array-contains-any", directIDs[start:end])))
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range subgroups {
+			if !seen[group.ID] {
+				seen[group.ID] = true
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	pointers := make([]*models.Group, len(groups))
+	for i := range groups {
+		pointers[i] = &groups[i]
+	}
+	if err := InheritRoles(ctx, nil, pointers...); err != nil {
+		log.Printf("Error fetching parent groups: %v", err)
+		return nil, err
+	}
+
+	return hierarchyOrder(groups), nil
+}
+
+// queryGroups obtiene los grupos de la consulta, sin calcular los roles heredados
+func (s *GroupService) queryGroups(ctx context.Context, query firestore.Query) ([]models.Group, error) {
+	iter := query.Documents(ctx)
+	defer iter.Stop()
 
 	var groups []models.Group
 	for {
@@ -81,7 +257,7 @@ func (s *GroupService) GetUserGroups(userID string) ([]models.Group, error) {
 		}
 
 		var group models.Group
-		if err := doc.DataTo(&group); err != nil {
+		if err := s.decodeGroup(doc, &group); err != nil {
 			log.Printf("Error converting document to Group: %v", err)
 			continue
 		}
@@ -92,38 +268,35 @@ func (s *GroupService) GetUserGroups(userID string) ([]models.Group, error) {
 	return groups, nil
 }
 
-// GetGroupMembersDetails obtiene información detallada de los miembros de un grupo
+// GetGroupMembersDetails obtiene información detallada de los miembros de un grupo,
+// en el mismo orden. El espacio de trabajo se comprueba con UserWorkspaces y el
+// resto del perfil se lee de UserProfiles; los usuarios que no existen o son de
+// otro espacio de trabajo se omiten.
 func (s *GroupService) GetGroupMembersDetails(memberIDs []string) ([]models.User, error) {
 	if len(memberIDs) == 0 {
 		return []models.User{}, nil
 	}
 
 	ctx := context.Background()
-	var members []models.User
+	workspaces, err := UserWorkspaces(ctx, memberIDs)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := UserProfiles.Get(ctx, memberIDs)
+	if err != nil {
+		return nil, err
+	}
 
-	// Obtener detalles de cada miembro
+	members := make([]models.User, 0, len(memberIDs))
+	seen := make(map[string]bool, len(memberIDs))
 	for _, memberID := range memberIDs {
-		doc, err := database.Client.Collection("users").Doc(memberID).Get(ctx)
-		if err != nil {
-			log.Printf("Error getting user %s: %v", memberID, err)
+		workspaceID, inWorkspace := workspaces[memberID]
+		user, ok := profiles[memberID]
+		if !ok || !inWorkspace || seen[memberID] || !s.inScope(workspaceID) {
 			continue
 		}
-
-		userData := doc.Data()
-
-		// Verificar si el campo "role" existe y no está vacío
-		role, ok := userData["role"].(string)
-		if !ok || role == "" {
-			role = "user" // Valor por defecto
-		}
-
-		user := models.User{
-			ID:       userData["id"].(string),
-			Username: userData["username"].(string),
-			Email:    userData["email"].(string),
-			Role:     role,
-		}
-
+		user.WorkspaceID = workspaceID
+		seen[memberID] = true
 		members = append(members, user)
 	}
 
@@ -131,144 +304,524 @@ func (s *GroupService) GetGroupMembersDetails(memberIDs []string) ([]models.User
 }
 
 // AddMemberToGroup agrega un miembro a un grupo
-func (s *GroupService) AddMemberToGroup(groupID, userID string) error {
+func (s *GroupService) AddMemberToGroup(groupID, userID string, check VersionCheck) error {
 	ctx := context.Background()
 
-	// Verificar si el grupo existe
-	group, err := s.GetGroupByID(groupID)
+	groupRef := database.Client.Collection("groups").Doc(groupID)
+	userRef := database.Client.Collection("users").Doc(userID)
+
+	// La lectura y la escritura se hacen en una transacción para no perder
+	// miembros añadidos de forma concurrente
+	err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(groupRef)
+		if err != nil {
+			return err
+		}
+
+		var group models.Group
+		if err := s.decodeGroup(doc, &group); err != nil {
+			return err
+		}
+
+		if check != nil && !check(group.Version) {
+			return ErrVersionConflict
+		}
+
+		// Verificar si el usuario existe y es del espacio de trabajo del grupo
+		userDoc, err := tx.Get(userRef)
+		if err != nil {
+			return ErrUserNotFound
+		}
+		if workspaceID, _ := userDoc.Data()["workspace_id"].(string); !models.SameWorkspace(workspaceID, group.WorkspaceID) {
+			return ErrUserNotFound
+		}
+
+		return addGroupMember(tx, groupRef, &group, userID)
+	})
 	if err != nil {
+		log.Printf("Error updating group members: %v", err)
 		return err
 	}
 
-	// Verificar si el usuario existe
-	userDoc, err := database.Client.Collection("users").Doc(userID).Get(ctx)
-	if err != nil {
-		return errors.New("user not found")
-	}
+	linkUserGroup(ctx, userID, groupID)
 
+	return nil
+}
+
+// addGroupMember añade el usuario a los miembros del grupo dentro de una transacción
+func addGroupMember(tx *firestore.Transaction, groupRef *firestore.DocumentRef, group *models.Group, userID string) error {
 	// Verificar si el usuario ya es miembro
 	for _, memberID := range group.Members {
 		if memberID == userID {
-			return errors.New("user is already a member of this group")
+			return ErrAlreadyMember
 		}
 	}
 
-	// Agregar el usuario a los miembros del grupo
-	_, err = database.Client.Collection("groups").Doc(groupID).Update(ctx, []firestore.Update{
-		{
-			Path:  "members",
-			Value: append(group.Members, userID),
-		},
+	return tx.Update(groupRef, []firestore.Update{
+		{Path: "members", Value: firestore.ArrayUnion(userID)},
+		{FieldPath: firestore.FieldPath{"roles", userID}, Value: models.GroupRoleMember},
+		{Path: "version", Value: group.Version + 1},
+		{Path: "updated_at", Value: time.Now()},
 	})
+}
 
+// linkUserGroup agrega el grupo a la lista de grupos del usuario, si se mantiene esta
+// relación en el modelo de usuario. Un error no revierte la adición al grupo.
+func linkUserGroup(ctx context.Context, userID, groupID string) {
+	_, err := database.Client.Collection("users").Doc(userID).Update(ctx, []firestore.Update{
+		{Path: "groups", Value: firestore.ArrayUnion(groupID)},
+	})
+	if err != nil {
+		log.Printf("Error updating user groups: %v", err)
+	}
+}
+
+// RemoveMemberFromGroup elimina un miembro de un grupo. Cualquier miembro puede
+// salir del grupo; para eliminar a otro hace falta gestionar miembros y tener un
+// rol superior al suyo. El propietario no puede salir ni ser eliminado, y los
+// miembros heredados solo se eliminan en el grupo antecesor.
+func (s *GroupService) RemoveMemberFromGroup(groupID, actorID, userID string, check VersionCheck) error {
+	ctx := context.Background()
+
+	groupRef := database.Client.Collection("groups").Doc(groupID)
+
+	err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(groupRef)
+		if err != nil {
+			return err
+		}
+
+		var group models.Group
+		if err := s.loadGroup(ctx, tx, doc, &group); err != nil {
+			return err
+		}
+
+		if check != nil && !check(group.Version) {
+			return ErrVersionConflict
+		}
+
+		role := group.RoleOf(userID)
+		if actorID != userID && (!group.Can(actorID, models.PermManageMembers) || !group.Outranks(actorID, role)) {
+			return ErrGroupForbidden
+		}
+		if role == "" {
+			return ErrNotMember
+		}
+		if !group.IsDirectMember(userID) {
+			return ErrInheritedMember
+		}
+		if role == models.GroupRoleOwner {
+			return ErrOwnerRole
+		}
+
+		return tx.Update(groupRef, []firestore.Update{
+			{Path: "members", Value: firestore.ArrayRemove(userID)},
+			{FieldPath: firestore.FieldPath{"roles", userID}, Value: firestore.Delete},
+			{Path: "version", Value: group.Version + 1},
+			{Path: "updated_at", Value: time.Now()},
+		})
+	})
 	if err != nil {
 		log.Printf("Error updating group members: %v", err)
 		return err
 	}
 
-	// También agregar el grupo al usuario si se mantiene esta relación en el modelo de usuario
-	userData := userDoc.Data()
-	userGroups, ok := userData["groups"].([]string)
-	if !ok {
-		userGroups = []string{}
+	// También eliminar el grupo de la lista del usuario si se mantiene esta relación
+	_, err = database.Client.Collection("users").Doc(userID).Update(ctx, []firestore.Update{
+		{Path: "groups", Value: firestore.ArrayRemove(groupID)},
+	})
+	if err != nil {
+		log.Printf("Error updating user groups: %v", err)
+		// No devolvemos error aquí para no revertir la eliminación del grupo
 	}
 
-	// Verificar si el grupo ya está en la lista del usuario
-	groupExists := false
-	for _, gID := range userGroups {
-		if gID == groupID {
-			groupExists = true
-			break
-		}
+	return nil
+}
+
+// SetMemberRole cambia el rol de un miembro. Quien lo cambia debe poder gestionar
+// roles y, salvo el propietario, solo puede hacerlo con miembros de rol inferior al
+// suyo y asignarles roles también inferiores. El rol de propietario solo cambia
+// con TransferOwnership. En los miembros heredados, el rol guardado sustituye en
+// este grupo y sus subgrupos al heredado.
+func (s *GroupService) SetMemberRole(groupID, actorID, userID, role string, check VersionCheck) (*models.Group, error) {
+	if !models.IsGroupRole(role) || role == models.GroupRoleOwner {
+		return nil, ErrInvalidRole
 	}
 
-	if !groupExists {
-		_, err = database.Client.Collection("users").Doc(userID).Update(ctx, []firestore.Update{
-			{
-				Path:  "groups",
-				Value: append(userGroups, groupID),
-			},
+	ctx := context.Background()
+
+	groupRef := database.Client.Collection("groups").Doc(groupID)
+	var group models.Group
+
+	err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(groupRef)
+		if err != nil {
+			return err
+		}
+
+		group = models.Group{}
+		if err := s.loadGroup(ctx, tx, doc, &group); err != nil {
+			return err
+		}
+
+		if check != nil && !check(group.Version) {
+			return ErrVersionConflict
+		}
+
+		current := group.RoleOf(userID)
+		if !group.Can(actorID, models.PermManageRoles) || !group.Outranks(actorID, current) || !group.Outranks(actorID, role) {
+			return ErrGroupForbidden
+		}
+		if current == "" {
+			return ErrNotMember
+		}
+		if current == models.GroupRoleOwner {
+			return ErrOwnerRole
+		}
+
+		if group.Roles == nil {
+			group.Roles = map[string]string{}
+		}
+		group.Roles[userID] = role
+		group.Version++
+		group.UpdatedAt = time.Now()
+
+		return tx.Update(groupRef, []firestore.Update{
+			{FieldPath: firestore.FieldPath{"roles", userID}, Value: role},
+			{Path: "version", Value: group.Version},
+			{Path: "updated_at", Value: group.UpdatedAt},
 		})
+	})
+	if err != nil {
+		log.Printf("Error updating member role: %v", err)
+		return nil, err
+	}
 
+	return &group, nil
+}
+
+// TransferOwnership cede la propiedad del grupo a otro miembro directo. El
+// propietario anterior pasa a ser administrador; CreatorID no cambia.
+func (s *GroupService) TransferOwnership(groupID, actorID, newOwnerID string, check VersionCheck) (*models.Group, error) {
+	ctx := context.Background()
+
+	groupRef := database.Client.Collection("groups").Doc(groupID)
+	var group models.Group
+
+	err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(groupRef)
 		if err != nil {
-			log.Printf("Error updating user groups: %v", err)
-			// No devolvemos error aquí para no revertir la adición al grupo
+			return err
+		}
+
+		group = models.Group{}
+		if err := s.loadGroup(ctx, tx, doc, &group); err != nil {
+			return err
+		}
+
+		if check != nil && !check(group.Version) {
+			return ErrVersionConflict
+		}
+
+		if !group.Can(actorID, models.PermTransferOwnership) {
+			return ErrGroupForbidden
+		}
+		if !group.IsDirectMember(newOwnerID) {
+			return ErrNotMember
 		}
+		if newOwnerID == actorID {
+			return nil
+		}
+
+		if group.Roles == nil {
+			group.Roles = map[string]string{}
+		}
+		group.Roles[actorID] = models.GroupRoleAdmin
+		group.Roles[newOwnerID] = models.GroupRoleOwner
+		group.Version++
+		group.UpdatedAt = time.Now()
+
+		return tx.Update(groupRef, []firestore.Update{
+			{FieldPath: firestore.FieldPath{"roles", actorID}, Value: models.GroupRoleAdmin},
+			{FieldPath: firestore.FieldPath{"roles", newOwnerID}, Value: models.GroupRoleOwner},
+			{Path: "version", Value: group.Version},
+			{Path: "updated_at", Value: group.UpdatedAt},
+		})
+	})
+	if err != nil {
+		log.Printf("Error transferring group ownership: %v", err)
+		return nil, err
 	}
 
-	return nil
+	return &group, nil
+}
+
+// GroupChanges son los cambios que se pueden hacer en un grupo; los campos nil no cambian
+type GroupChanges struct {
+	Name        *string
+	Description *string
+	Workflow    *models.Workflow // Flujo de trabajo de las tareas del grupo
 }
 
-// RemoveMemberFromGroup elimina un miembro de un grupo
-func (s *GroupService) RemoveMemberFromGroup(groupID, userID string) error {
+// UpdateGroup modifica el nombre, la descripción o el flujo de trabajo de un grupo.
+// El cambio de flujo se rechaza si alguna tarea del grupo quedaría en un estado inexistente.
+func (s *GroupService) UpdateGroup(groupID, actorID string, changes GroupChanges, check VersionCheck) (*models.Group, error) {
 	ctx := context.Background()
 
-	// Verificar si el grupo existe
-	group, err := s.GetGroupByID(groupID)
+	groupRef := database.Client.Collection("groups").Doc(groupID)
+	var group models.Group
+
+	err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(groupRef)
+		if err != nil {
+			return err
+		}
+
+		group = models.Group{}
+		if err := s.loadGroup(ctx, tx, doc, &group); err != nil {
+			return err
+		}
+
+		if check != nil && !check(group.Version) {
+			return ErrVersionConflict
+		}
+
+		if !group.Can(actorID, models.PermEditGroup) {
+			return ErrGroupForbidden
+		}
+
+		var updates []firestore.Update
+		if changes.Name != nil {
+			group.Name = *changes.Name
+			updates = append(updates, firestore.Update{Path: "name", Value: group.Name})
+		}
+		if changes.Description != nil {
+			group.Description = *changes.Description
+			updates = append(updates, firestore.Update{Path: "description", Value: group.Description})
+		}
+		if changes.Workflow != nil {
+			group.Workflow = changes.Workflow
+			updates = append(updates, firestore.Update{Path: "workflow", Value: group.Workflow})
+		}
+
+		if !group.Validate() {
+			return ErrInvalidGroup
+		}
+
+		// Verificar que ninguna tarea del grupo quede en un estado eliminado
+		if changes.Workflow != nil {
+			taskDocs, err := tx.Documents(s.scoped(database.Client.Collection("tasks").Where("group_id", "==", groupID))).GetAll()
+			if err != nil {
+				return err
+			}
+			for _, taskDoc := range taskDocs {
+				var task models.Task
+				if err := taskDoc.DataTo(&task); err != nil {
+					continue
+				}
+				if !changes.Workflow.HasStatus(task.Status) {
+					return ErrStatusInUse
+				}
+			}
+		}
+
+		group.Version++
+		group.UpdatedAt = time.Now()
+		updates = append(updates,
+			firestore.Update{Path: "version", Value: group.Version},
+			firestore.Update{Path: "updated_at", Value: group.UpdatedAt},
+		)
+
+		return tx.Update(groupRef, updates)
+	})
 	if err != nil {
-		return err
+		log.Printf("Error updating group: %v", err)
+		return nil, err
 	}
 
-	// El creador no puede ser eliminado del grupo
-	if group.CreatorID == userID {
-		return errors.New("creator cannot be removed from the group")
+	return &group, nil
+}
+
+// Qué hacer con las tareas de un grupo al eliminarlo
+const (
+	GroupTasksArchive  = "archive"  // Se mueven a archived_tasks y el grupo se copia en archived_groups
+	GroupTasksReassign = "reassign" // Pasan a ser tareas personales del propietario del grupo
+	GroupTasksDelete   = "delete"   // Se eliminan
+)
+
+// deleteGroupBatch es el número de tareas que se procesan en cada transacción al
+// eliminar un grupo. Archivar una tarea son dos escrituras y Firestore admite 500.
+const deleteGroupBatch = 200
+
+// IsGroupTaskDisposal indica si la opción para las tareas de un grupo eliminado existe
+func IsGroupTaskDisposal(disposal string) bool {
+	return disposal == GroupTasksArchive || disposal == GroupTasksReassign || disposal == GroupTasksDelete
+}
+
+// DeleteGroup elimina un grupo sin subgrupos y archiva, reasigna o elimina sus tareas según disposal.
+// Las tareas se procesan por lotes y el grupo se elimina en la misma transacción que
+// el último lote, de modo que una eliminación interrumpida se puede repetir. Después
+// se eliminan las etiquetas, plantillas, webhooks e invitaciones del grupo.
+func (s *GroupService) DeleteGroup(groupID, actorID, disposal string, check VersionCheck) (*models.Group, error) {
+	if !IsGroupTaskDisposal(disposal) {
+		return nil, ErrInvalidGroup
 	}
 
-	// Crear nueva lista sin el miembro
-	var newMembers []string
-	memberFound := false
+	ctx := context.Background()
 
-	for _, memberID := range group.Members {
-		if memberID != userID {
-			newMembers = append(newMembers, memberID)
-		} else {
-			memberFound = true
+	groupRef := database.Client.Collection("groups").Doc(groupID)
+	tasksQuery := s.scoped(database.Client.Collection("tasks").Where("group_id", "==", groupID)).Limit(deleteGroupBatch)
+	var group models.Group
+
+	for done := false; !done; {
+		err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+			done = false
+
+			doc, err := tx.Get(groupRef)
+			if err != nil {
+				return err
+			}
+
+			group = models.Group{}
+			if err := s.loadGroup(ctx, tx, doc, &group); err != nil {
+				return err
+			}
+
+			if check != nil && !check(group.Version) {
+				return ErrVersionConflict
+			}
+
+			if !group.Can(actorID, models.PermDeleteGroup) {
+				return ErrGroupForbidden
+			}
+
+			subgroups, err := tx.Documents(s.scoped(database.Client.Collection("groups").Where("parent_id", "==", groupID)).Limit(1)).GetAll()
+			if err != nil {
+				return err
+			}
+			if len(subgroups) > 0 {
+				return ErrHasSubgroups
+			}
+
+			taskDocs, err := tx.Documents(tasksQuery).GetAll()
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			for _, taskDoc := range taskDocs {
+				if err := disposeGroupTask(tx, taskDoc, &group, disposal, now); err != nil {
+					return err
+				}
+			}
+
+			// Cuando ya no quedan tareas se elimina el grupo
+			if len(taskDocs) == deleteGroupBatch {
+				return nil
+			}
+			if disposal == GroupTasksArchive {
+				archived := models.ArchivedGroup{Group: group, ArchivedAt: now, ArchivedBy: actorID}
+				if err := tx.Set(database.Client.Collection("archived_groups").Doc(groupID), archived); err != nil {
+					return err
+				}
+			}
+			done = true
+			return tx.Delete(groupRef)
+		})
+		if err != nil {
+			log.Printf("Error deleting group: %v", err)
+			return nil, err
 		}
 	}
 
-	if !memberFound {
-		return errors.New("user is not a member of this group")
-	}
+	deleteGroupData(ctx, &group)
 
-	// Actualizar los miembros del grupo
-	_, err = database.Client.Collection("groups").Doc(groupID).Update(ctx, []firestore.Update{
-		{
-			Path:  "members",
-			Value: newMembers,
-		},
-	})
+	return &group, nil
+}
 
-	if err != nil {
-		log.Printf("Error updating group members: %v", err)
+// disposeGroupTask archiva, reasigna o elimina una tarea de un grupo que se elimina
+func disposeGroupTask(tx *firestore.Transaction, doc *firestore.DocumentSnapshot, group *models.Group, disposal string, now time.Time) error {
+	switch disposal {
+	case GroupTasksDelete:
+		return tx.Delete(doc.Ref)
+	case GroupTasksArchive:
+		data := doc.Data()
+		data["archived_at"] = now
+		if err := tx.Set(database.Client.Collection("archived_tasks").Doc(doc.Ref.ID), data); err != nil {
+			return err
+		}
+		return tx.Delete(doc.Ref)
+	}
+
+	var task models.Task
+	if err := doc.DataTo(&task); err != nil {
 		return err
 	}
 
-	// También eliminar el grupo de la lista del usuario si se mantiene esta relación
-	userDoc, err := database.Client.Collection("users").Doc(userID).Get(ctx)
-	if err == nil {
-		userData := userDoc.Data()
-		userGroups, ok := userData["groups"].([]string)
-		if ok {
-			var newUserGroups []string
-			for _, gID := range userGroups {
-				if gID != groupID {
-					newUserGroups = append(newUserGroups, gID)
-				}
-			}
+	// Las tareas personales usan el flujo por defecto: los estados propios del
+	// grupo pasan a completed si eran finales y a pending si no
+	if !models.DefaultWorkflow().HasStatus(task.Status) {
+		if group.TaskWorkflow().IsFinal(task.Status) {
+			task.Status = models.TaskStatusCompleted
+		} else {
+			task.Status = models.TaskStatusPending
+		}
+	}
 
-			_, err = database.Client.Collection("users").Doc(userID).Update(ctx, []firestore.Update{
-				{
-					Path:  "groups",
-					Value: newUserGroups,
-				},
-			})
+	// Las etiquetas y la posición en el tablero eran del grupo; la tarea recibe una
+	// nueva posición al final de su columna cuando esta se reordena al mover una
+	// tarea o con la migración backfill-ranks
+	task.UserID = group.OwnerID()
+	task.GroupID = nil
+	task.LabelIDs = nil
+	task.Labels = nil
+	task.Rank = ""
+	task.UpdatedAt = now
+	task.Version++
+
+	return tx.Set(doc.Ref, task)
+}
 
-			if err != nil {
-				log.Printf("Error updating user groups: %v", err)
-				// No devolvemos error aquí para no revertir la eliminación del grupo
+// deleteGroupData elimina los datos que dependen de un grupo ya eliminado y lo quita
+// de la lista de grupos de sus miembros. Los errores solo se registran.
+func deleteGroupData(ctx context.Context, group *models.Group) {
+	writer := database.Client.BulkWriter(ctx)
+
+	for _, memberID := range group.Members {
+		if _, err := writer.Update(database.Client.Collection("users").Doc(memberID), []firestore.Update{
+			{Path: "groups", Value: firestore.ArrayRemove(group.ID)},
+		}); err != nil {
+			log.Printf("Error updating user groups: %v", err)
+		}
+	}
+
+	for _, collection := range []string{"labels", "task_templates", "group_invitations", "invite_links"} {
+		docs, err := database.Client.Collection(collection).Where("group_id", "==", group.ID).Documents(ctx).GetAll()
+		if err != nil {
+			log.Printf("Error fetching %s of deleted group: %v", collection, err)
+			continue
+		}
+		for _, doc := range docs {
+			if _, err := writer.Delete(doc.Ref); err != nil {
+				log.Printf("Error deleting %s/%s: %v", collection, doc.Ref.ID, err)
 			}
 		}
 	}
 
-	return nil
+	writer.End()
+
+	// Los webhooks se eliminan con su historial de entregas
+	webhookService := NewWebhookService()
+	docs, err := database.InWorkspace(database.Client.Collection("webhooks").Query, group.WorkspaceID).
+		Where("group_id", "==", group.ID).
+		Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error fetching webhooks of deleted group: %v", err)
+		return
+	}
+	for _, doc := range docs {
+		if err := webhookService.DeleteWebhook(doc.Ref.ID); err != nil {
+			log.Printf("Error deleting webhook %s: %v", doc.Ref.ID, err)
+		}
+	}
 }
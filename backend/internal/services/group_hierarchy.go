@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"log"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/models"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// InheritRoles calcula los roles que los grupos heredan de sus antecesores (ver
+// models.Group.Inherited). Cada nivel hereda los roles efectivos del anterior, de
+// modo que el rol guardado en un grupo también se aplica a sus subgrupos. Los
+// antecesores se leen de una vez y, si tx no es nil, dentro de la transacción.
+func InheritRoles(ctx context.Context, tx *firestore.Transaction, groups ...*models.Group) error {
+	known := make(map[string]*models.Group, len(groups))
+	for _, group := range groups {
+		known[group.ID] = group
+	}
+
+	var refs []*firestore.DocumentRef
+	for _, group := range groups {
+		for _, ancestorID := range group.Path {
+			if _, ok := known[ancestorID]; !ok {
+				known[ancestorID] = nil
+				refs = append(refs, database.Client.Collection("groups").Doc(ancestorID))
+			}
+		}
+	}
+
+	if len(refs) > 0 {
+		var docs []*firestore.DocumentSnapshot
+		var err error
+		if tx != nil {
+			docs, err = tx.GetAll(refs)
+		} else {
+			docs, err = database.Client.GetAll(ctx, refs)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, doc := range docs {
+			if !doc.Exists() {
+				continue
+			}
+			var ancestor models.Group
+			if err := doc.DataTo(&ancestor); err != nil {
+				return err
+			}
+			known[doc.Ref.ID] = &ancestor
+		}
+	}
+
+	// La ruta va de la raíz al padre, así que cada antecesor se calcula antes que sus subgrupos
+	for _, group := range groups {
+		inherited := map[string]string{}
+		for _, ancestorID := range group.Path {
+			ancestor := known[ancestorID]
+			if ancestor == nil {
+				continue
+			}
+			ancestor.Inherited = inherited
+			inherited = ancestor.ChildRoles()
+		}
+		group.Inherited = inherited
+	}
+
+	return nil
+}
+
+// hierarchyOrder ordena los grupos de modo que cada uno vaya seguido de sus subgrupos
+func hierarchyOrder(groups []models.Group) []models.Group {
+	ordered := make([]models.Group, 0, len(groups))
+
+	var walk func(nodes []*models.GroupNode)
+	walk = func(nodes []*models.GroupNode) {
+		for _, node := range nodes {
+			ordered = append(ordered, node.Group)
+			walk(node.Children)
+		}
+	}
+	walk(models.GroupTree(groups))
+
+	return ordered
+}
+
+// MoveGroup mueve un grupo con todos sus subgrupos bajo otro grupo o, si parentID
+// es nil, a la raíz. Quien lo mueve debe poder editar el grupo, su padre actual y
+// el nuevo. Un grupo no se puede mover dentro de sí mismo ni de sus subgrupos.
+func (s *GroupService) MoveGroup(groupID, actorID string, parentID *string, check VersionCheck) (*models.Group, error) {
+	if parentID != nil && *parentID == "" {
+		parentID = nil
+	}
+	if parentID != nil && *parentID == groupID {
+		return nil, ErrGroupCycle
+	}
+
+	ctx := context.Background()
+
+	groupsRef := database.Client.Collection("groups")
+	groupRef := groupsRef.Doc(groupID)
+	var group models.Group
+
+	err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(groupRef)
+		if err != nil {
+			return err
+		}
+
+		group = models.Group{}
+		if err := s.loadGroup(ctx, tx, doc, &group); err != nil {
+			return err
+		}
+
+		if check != nil && !check(group.Version) {
+			return ErrVersionConflict
+		}
+
+		if !group.Can(actorID, models.PermEditGroup) {
+			return ErrGroupForbidden
+		}
+		if !group.IsRoot() {
+			current, err := s.loadParent(ctx, tx, *group.ParentID)
+			if err != nil {
+				return err
+			}
+			if !current.Can(actorID, models.PermEditGroup) {
+				return ErrGroupForbidden
+			}
+		}
+
+		path := []string{}
+		if parentID != nil {
+			parent, err := s.loadParent(ctx, tx, *parentID)
+			if err != nil {
+				return err
+			}
+			if !parent.Can(actorID, models.PermEditGroup) {
+				return ErrGroupForbidden
+			}
+			for _, ancestorID := range parent.Path {
+				if ancestorID == groupID {
+					return ErrGroupCycle
+				}
+			}
+			path = append(append(path, parent.Path...), parent.ID)
+		}
+		if len(path) >= models.MaxGroupDepth {
+			return ErrGroupTooDeep
+		}
+
+		descendants, err := tx.Documents(groupsRef.Where("path", "array-contains", groupID)).GetAll()
+		if err != nil {
+			return err
+		}
+
+		// La ruta de cada subgrupo cambia en la parte anterior al grupo movido
+		now := time.Now()
+		descendantPaths := make(map[*firestore.DocumentRef][]string, len(descendants))
+		for _, descendantDoc := range descendants {
+			var descendant models.Group
+			if err := descendantDoc.DataTo(&descendant); err != nil {
+				return err
+			}
+			for i, ancestorID := range descendant.Path {
+				if ancestorID == groupID {
+					descendantPath := append(append([]string{}, path...), descendant.Path[i:]...)
+					if len(descendantPath) >= models.MaxGroupDepth {
+						return ErrGroupTooDeep
+					}
+					descendantPaths[descendantDoc.Ref] = descendantPath
+					break
+				}
+			}
+		}
+
+		group.ParentID = parentID
+		group.Path = path
+		group.Version++
+		group.UpdatedAt = now
+
+		var parentValue interface{} = firestore.Delete
+		if parentID != nil {
+			parentValue = *parentID
+		}
+		if err := tx.Update(groupRef, []firestore.Update{
+			{Path: "parent_id", Value: parentValue},
+			{Path: "path", Value: group.Path},
+			{Path: "version", Value: group.Version},
+			{Path: "updated_at", Value: group.UpdatedAt},
+		}); err != nil {
+			return err
+		}
+
+		// Los subgrupos cambian de versión porque cambian sus miembros heredados
+		for ref, descendantPath := range descendantPaths {
+			if err := tx.Update(ref, []firestore.Update{
+				{Path: "path", Value: descendantPath},
+				{Path: "version", Value: firestore.Increment(1)},
+				{Path: "updated_at", Value: now},
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error moving group: %v", err)
+		return nil, err
+	}
+
+	if err := InheritRoles(ctx, nil, &group); err != nil {
+		log.Printf("Error fetching parent groups: %v", err)
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// loadParent obtiene dentro de la transacción un grupo padre, con sus roles heredados
+func (s *GroupService) loadParent(ctx context.Context, tx *firestore.Transaction, groupID string) (*models.Group, error) {
+	doc, err := tx.Get(database.Client.Collection("groups").Doc(groupID))
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrGroupNotFound
+		}
+		return nil, err
+	}
+
+	var group models.Group
+	if err := s.loadGroup(ctx, tx, doc, &group); err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
@@ -0,0 +1,580 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/i18n"
+	"task-manager-backend/internal/mailer"
+	"task-manager-backend/internal/models"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrInvitationNotFound indica que la invitación no existe o es para otro usuario
+	ErrInvitationNotFound = errors.New("invitation not found")
+	// ErrInvitationExists indica que el usuario ya tiene una invitación pendiente al grupo
+	ErrInvitationExists = errors.New("user already has a pending invitation to this group")
+	// ErrInvitationClosed indica que la invitación ya fue respondida, revocada o caducó
+	ErrInvitationClosed = errors.New("invitation is no longer pending")
+	// ErrInviteLinkNotFound indica que el enlace de invitación no existe
+	ErrInviteLinkNotFound = errors.New("invite link not found")
+	// ErrInviteLinkUnusable indica que el enlace fue revocado, caducó o agotó sus usos
+	ErrInviteLinkUnusable = errors.New("invite link is no longer valid")
+)
+
+// invitationTTL es el plazo para responder a una invitación
+const invitationTTL = 7 * 24 * time.Hour
+
+var invitationTemplate = mustParseMailTemplate("invitation")
+
+// InvitationService proporciona métodos para gestionar las invitaciones a grupos
+// y los enlaces de invitación
+type InvitationService struct {
+	mailer mailer.Mailer
+	appURL string
+}
+
+// NewInvitationService crea una nueva instancia de InvitationService. Las
+// invitaciones por correo se envían con m a menos que sea nil; appURL es la
+// dirección de la aplicación que aparece en el correo.
+func NewInvitationService(m mailer.Mailer, appURL string) *InvitationService {
+	return &InvitationService{mailer: m, appURL: appURL}
+}
+
+// Invite invita a un grupo a un usuario (inviteeID) o a una dirección de correo,
+// que puede no estar registrada todavía. Los usuarios registrados deben ser del
+// espacio de trabajo del grupo.
+func (s *InvitationService) Invite(group *models.Group, inviterID, inviteeID, email string) (*models.GroupInvitation, error) {
+	ctx := context.Background()
+
+	var invitee *models.User
+	if inviteeID != "" {
+		user, err := LoadUser(ctx, inviteeID)
+		if err != nil {
+			return nil, err
+		}
+		if !models.SameWorkspace(user.WorkspaceID, group.WorkspaceID) {
+			return nil, ErrUserNotFound
+		}
+		invitee = user
+	} else {
+		user, err := findUserByEmail(ctx, group.WorkspaceID, email)
+		if err != nil && !errors.Is(err, ErrUserNotFound) {
+			return nil, err
+		}
+		invitee = user
+	}
+
+	invitation := &models.GroupInvitation{
+		ID:          uuid.New().String(),
+		GroupID:     group.ID,
+		GroupName:   group.Name,
+		WorkspaceID: models.WorkspaceOf(group.WorkspaceID),
+		InviterID:   inviterID,
+		Email:       strings.ToLower(strings.TrimSpace(email)),
+		Status:      models.InvitationPending,
+		CreatedAt:   time.Now(),
+	}
+	invitation.ExpiresAt = invitation.CreatedAt.Add(invitationTTL)
+	if invitee != nil {
+		invitation.InviteeID = &invitee.ID
+		invitation.Email = strings.ToLower(invitee.Email)
+		if group.IsDirectMember(invitee.ID) {
+			return nil, ErrAlreadyMember
+		}
+	}
+
+	pending, err := s.pendingGroupInvitations(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range pending {
+		if (invitee != nil && existing.IsFor(invitee.ID, invitee.Email)) || existing.IsFor("", invitation.Email) {
+			return nil, ErrInvitationExists
+		}
+	}
+
+	if _, err := database.Client.Collection("group_invitations").Doc(invitation.ID).Set(ctx, invitation); err != nil {
+		log.Printf("Error creating invitation in Firestore: %v", err)
+		return nil, err
+	}
+
+	if invitation.Email != "" {
+		s.sendInvitationEmail(ctx, invitation, invitee != nil)
+	}
+
+	return invitation, nil
+}
+
+// ListGroupInvitations obtiene las invitaciones de un grupo, de la más reciente a la más antigua
+func (s *InvitationService) ListGroupInvitations(workspaceID, groupID string) ([]models.GroupInvitation, error) {
+	ctx := context.Background()
+
+	docs, err := database.InWorkspace(database.Client.Collection("group_invitations").Query, workspaceID).
+		Where("group_id", "==", groupID).
+		Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error listing group invitations: %v", err)
+		return nil, err
+	}
+
+	return decodeInvitations(docs), nil
+}
+
+// ListUserInvitations obtiene las invitaciones pendientes de un usuario, también
+// las que se enviaron a su correo antes de que se registrara
+func (s *InvitationService) ListUserInvitations(userID string) ([]models.GroupInvitation, error) {
+	ctx := context.Background()
+
+	user, err := LoadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	email := user.Email
+
+	invitationsRef := database.Client.Collection("group_invitations")
+	queries := []firestore.Query{
+		invitationsRef.Where("invitee_id", "==", userID).Where("status", "==", models.InvitationPending),
+	}
+	if email != "" {
+		queries = append(queries, invitationsRef.Where("email", "==", strings.ToLower(email)).Where("status", "==", models.InvitationPending))
+	}
+
+	seen := map[string]bool{}
+	var docs []*firestore.DocumentSnapshot
+	for _, q := range queries {
+		results, err := q.Documents(ctx).GetAll()
+		if err != nil {
+			log.Printf("Error listing user invitations: %v", err)
+			return nil, err
+		}
+		for _, doc := range results {
+			if !seen[doc.Ref.ID] {
+				seen[doc.Ref.ID] = true
+				docs = append(docs, doc)
+			}
+		}
+	}
+
+	invitations := []models.GroupInvitation{}
+	for _, invitation := range decodeInvitations(docs) {
+		// Las invitaciones a otros espacios de trabajo no le sirven al usuario
+		if invitation.Status == models.InvitationPending && models.SameWorkspace(invitation.WorkspaceID, user.WorkspaceID) {
+			invitations = append(invitations, invitation)
+		}
+	}
+
+	return invitations, nil
+}
+
+// GetInvitation obtiene una invitación por su ID
+func (s *InvitationService) GetInvitation(invitationID string) (*models.GroupInvitation, error) {
+	ctx := context.Background()
+
+	doc, err := database.Client.Collection("group_invitations").Doc(invitationID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrInvitationNotFound
+		}
+		log.Printf("Error getting invitation from Firestore: %v", err)
+		return nil, err
+	}
+
+	var invitation models.GroupInvitation
+	if err := doc.DataTo(&invitation); err != nil {
+		return nil, err
+	}
+	invitation.Refresh(time.Now())
+
+	return &invitation, nil
+}
+
+// Respond acepta o rechaza una invitación del usuario. Al aceptarla, el usuario
+// pasa a ser miembro del grupo.
+func (s *InvitationService) Respond(invitationID, userID string, accept bool) (*models.GroupInvitation, error) {
+	ctx := context.Background()
+
+	user, err := LoadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	invitationRef := database.Client.Collection("group_invitations").Doc(invitationID)
+
+	var invitation models.GroupInvitation
+	err = database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(invitationRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrInvitationNotFound
+			}
+			return err
+		}
+		invitation = models.GroupInvitation{}
+		if err := doc.DataTo(&invitation); err != nil {
+			return err
+		}
+		if !invitation.IsFor(user.ID, user.Email) || !models.SameWorkspace(invitation.WorkspaceID, user.WorkspaceID) {
+			return ErrInvitationNotFound
+		}
+
+		now := time.Now()
+		invitation.Refresh(now)
+		if invitation.Status != models.InvitationPending {
+			return ErrInvitationClosed
+		}
+
+		invitation.Status = models.InvitationDeclined
+		if accept {
+			invitation.Status = models.InvitationAccepted
+
+			groupRef := database.Client.Collection("groups").Doc(invitation.GroupID)
+			groupDoc, err := tx.Get(groupRef)
+			if err != nil {
+				return err
+			}
+			var group models.Group
+			if err := groupDoc.DataTo(&group); err != nil {
+				return err
+			}
+			if !models.SameWorkspace(group.WorkspaceID, user.WorkspaceID) {
+				return ErrInvitationNotFound
+			}
+			if err := addGroupMember(tx, groupRef, &group, user.ID); err != nil && !errors.Is(err, ErrAlreadyMember) {
+				return err
+			}
+		}
+
+		invitation.InviteeID = &user.ID
+		invitation.RespondedAt = &now
+		return tx.Update(invitationRef, []firestore.Update{
+			{Path: "status", Value: invitation.Status},
+			{Path: "invitee_id", Value: user.ID},
+			{Path: "responded_at", Value: now},
+		})
+	})
+	if err != nil {
+		if !errors.Is(err, ErrInvitationNotFound) && !errors.Is(err, ErrInvitationClosed) {
+			log.Printf("Error responding to invitation: %v", err)
+		}
+		return nil, err
+	}
+
+	if accept {
+		linkUserGroup(ctx, user.ID, invitation.GroupID)
+	}
+
+	return &invitation, nil
+}
+
+// RevokeInvitation cancela una invitación pendiente
+func (s *InvitationService) RevokeInvitation(invitationID string) error {
+	ctx := context.Background()
+	invitationRef := database.Client.Collection("group_invitations").Doc(invitationID)
+
+	return database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(invitationRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrInvitationNotFound
+			}
+			return err
+		}
+
+		var invitation models.GroupInvitation
+		if err := doc.DataTo(&invitation); err != nil {
+			return err
+		}
+		invitation.Refresh(time.Now())
+		if invitation.Status != models.InvitationPending {
+			return ErrInvitationClosed
+		}
+
+		return tx.Update(invitationRef, []firestore.Update{
+			{Path: "status", Value: models.InvitationRevoked},
+		})
+	})
+}
+
+// CreateInviteLink crea un enlace de invitación al grupo. maxUses 0 significa sin
+// límite y expiresAt nil que no caduca. Devuelve el token en claro, que no se
+// puede recuperar después.
+func (s *InvitationService) CreateInviteLink(groupID, creatorID string, maxUses int, expiresAt *time.Time) (string, *models.InviteLink, error) {
+	ctx := context.Background()
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	link := &models.InviteLink{
+		ID:        hashToken(token),
+		GroupID:   groupID,
+		CreatorID: creatorID,
+		MaxUses:   maxUses,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+
+	if _, err := database.Client.Collection("invite_links").Doc(link.ID).Set(ctx, link); err != nil {
+		log.Printf("Error creating invite link in Firestore: %v", err)
+		return "", nil, err
+	}
+
+	return token, link, nil
+}
+
+// ListInviteLinks obtiene los enlaces de invitación de un grupo
+func (s *InvitationService) ListInviteLinks(groupID string) ([]models.InviteLink, error) {
+	ctx := context.Background()
+
+	docs, err := database.Client.Collection("invite_links").Where("group_id", "==", groupID).Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error listing invite links: %v", err)
+		return nil, err
+	}
+
+	links := []models.InviteLink{}
+	for _, doc := range docs {
+		var link models.InviteLink
+		if err := doc.DataTo(&link); err != nil {
+			log.Printf("Error converting document to InviteLink: %v", err)
+			continue
+		}
+		links = append(links, link)
+	}
+
+	sort.Slice(links, func(i, j int) bool { return links[i].CreatedAt.After(links[j].CreatedAt) })
+
+	return links, nil
+}
+
+// RevokeInviteLink desactiva un enlace de invitación del grupo
+func (s *InvitationService) RevokeInviteLink(groupID, linkID string) error {
+	ctx := context.Background()
+	linkRef := database.Client.Collection("invite_links").Doc(linkID)
+
+	return database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(linkRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrInviteLinkNotFound
+			}
+			return err
+		}
+
+		var link models.InviteLink
+		if err := doc.DataTo(&link); err != nil {
+			return err
+		}
+		if link.GroupID != groupID {
+			return ErrInviteLinkNotFound
+		}
+
+		return tx.Update(linkRef, []firestore.Update{{Path: "revoked", Value: true}})
+	})
+}
+
+// GetInviteLink obtiene un enlace de invitación a partir de su token
+func (s *InvitationService) GetInviteLink(token string) (*models.InviteLink, error) {
+	ctx := context.Background()
+
+	doc, err := database.Client.Collection("invite_links").Doc(hashToken(token)).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrInviteLinkNotFound
+		}
+		log.Printf("Error getting invite link from Firestore: %v", err)
+		return nil, err
+	}
+
+	var link models.InviteLink
+	if err := doc.DataTo(&link); err != nil {
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// JoinWithLink añade al usuario al grupo del enlace y consume un uso. Los enlaces
+// de grupos de otros espacios de trabajo no existen para el usuario.
+func (s *InvitationService) JoinWithLink(token, userID string) (*models.InviteLink, error) {
+	ctx := context.Background()
+
+	user, err := LoadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	linkRef := database.Client.Collection("invite_links").Doc(hashToken(token))
+
+	var link models.InviteLink
+	err = database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(linkRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrInviteLinkNotFound
+			}
+			return err
+		}
+		link = models.InviteLink{}
+		if err := doc.DataTo(&link); err != nil {
+			return err
+		}
+		if !link.Usable(time.Now()) {
+			return ErrInviteLinkUnusable
+		}
+
+		groupRef := database.Client.Collection("groups").Doc(link.GroupID)
+		groupDoc, err := tx.Get(groupRef)
+		if err != nil {
+			return err
+		}
+		var group models.Group
+		if err := groupDoc.DataTo(&group); err != nil {
+			return err
+		}
+		if !models.SameWorkspace(group.WorkspaceID, user.WorkspaceID) {
+			return ErrInviteLinkNotFound
+		}
+
+		if err := addGroupMember(tx, groupRef, &group, userID); err != nil {
+			return err
+		}
+
+		link.Uses++
+		return tx.Update(linkRef, []firestore.Update{{Path: "uses", Value: link.Uses}})
+	})
+	if err != nil {
+		if !errors.Is(err, ErrInviteLinkNotFound) && !errors.Is(err, ErrInviteLinkUnusable) && !errors.Is(err, ErrAlreadyMember) {
+			log.Printf("Error joining group with invite link: %v", err)
+		}
+		return nil, err
+	}
+
+	linkUserGroup(ctx, userID, link.GroupID)
+
+	return &link, nil
+}
+
+// pendingGroupInvitations obtiene las invitaciones pendientes y vigentes de un grupo
+func (s *InvitationService) pendingGroupInvitations(ctx context.Context, groupID string) ([]models.GroupInvitation, error) {
+	docs, err := database.Client.Collection("group_invitations").
+		Where("group_id", "==", groupID).
+		Where("status", "==", models.InvitationPending).
+		Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error fetching pending invitations: %v", err)
+		return nil, err
+	}
+
+	var pending []models.GroupInvitation
+	for _, invitation := range decodeInvitations(docs) {
+		if invitation.Status == models.InvitationPending {
+			pending = append(pending, invitation)
+		}
+	}
+
+	return pending, nil
+}
+
+// sendInvitationEmail avisa por correo al invitado, en su idioma si tiene cuenta
+// o, si no, en el de quien invita. Un fallo no anula la invitación.
+func (s *InvitationService) sendInvitationEmail(ctx context.Context, invitation *models.GroupInvitation, registered bool) {
+	if s.mailer == nil {
+		return
+	}
+
+	var inviter string
+	locale := i18n.Default
+	if user, err := LoadUser(ctx, invitation.InviterID); err == nil {
+		inviter = user.Username
+		locale = i18n.Of(user.Locale)
+	}
+	if invitation.InviteeID != nil {
+		if inviteeLocale, ok := UserLocale(ctx, *invitation.InviteeID); ok {
+			locale = inviteeLocale
+		}
+	}
+	if inviter == "" {
+		inviter = i18n.T(locale, "A Task Manager user")
+	}
+
+	data := struct {
+		Inviter    string
+		Group      string
+		Email      string
+		AppURL     string
+		Registered bool
+		ExpiresAt  time.Time
+	}{inviter, invitation.GroupName, invitation.Email, s.appURL, registered, invitation.ExpiresAt}
+
+	text, html, err := invitationTemplate.Render(locale, data)
+	if err != nil {
+		log.Printf("Error rendering invitation email: %v", err)
+		return
+	}
+
+	if err := s.mailer.Send(ctx, mailer.Message{
+		To:      []string{invitation.Email},
+		Subject: i18n.T(locale, "email.invitation.subject", inviter, invitation.GroupName),
+		Text:    text,
+		HTML:    html,
+	}); err != nil {
+		log.Printf("Error sending invitation email: %v", err)
+	}
+}
+
+// decodeInvitations convierte los documentos en invitaciones, marca las caducadas
+// y las ordena de la más reciente a la más antigua
+func decodeInvitations(docs []*firestore.DocumentSnapshot) []models.GroupInvitation {
+	now := time.Now()
+	invitations := []models.GroupInvitation{}
+	for _, doc := range docs {
+		var invitation models.GroupInvitation
+		if err := doc.DataTo(&invitation); err != nil {
+			log.Printf("Error converting document to GroupInvitation: %v", err)
+			continue
+		}
+		invitation.Refresh(now)
+		invitations = append(invitations, invitation)
+	}
+
+	sort.Slice(invitations, func(i, j int) bool {
+		return invitations[i].CreatedAt.After(invitations[j].CreatedAt)
+	})
+
+	return invitations
+}
+
+// findUserByEmail busca un usuario del espacio de trabajo por su correo, tal como
+// se registró o en minúsculas
+func findUserByEmail(ctx context.Context, workspaceID, email string) (*models.User, error) {
+	email = strings.TrimSpace(email)
+	candidates := []string{email}
+	if lower := strings.ToLower(email); lower != email {
+		candidates = append(candidates, lower)
+	}
+
+	docs, err := database.InWorkspace(database.Client.Collection("users").Query, workspaceID).
+		Where("email", "in", candidates).
+		Limit(1).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	return DecodeUser(docs[0])
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/models"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrLabelNotFound indica que la etiqueta no existe
+	ErrLabelNotFound = errors.New("label not found")
+	// ErrLabelExists indica que ya existe una etiqueta con ese nombre en el mismo ámbito
+	ErrLabelExists = errors.New("a label with this name already exists")
+	// ErrLabelScope indica que la etiqueta no puede usarse en la tarea
+	ErrLabelScope = errors.New("label cannot be used on this task")
+)
+
+// LabelService proporciona métodos para gestionar etiquetas
+type LabelService struct{}
+
+// NewLabelService crea una nueva instancia de LabelService
+func NewLabelService() *LabelService {
+	return &LabelService{}
+}
+
+// scopeQuery devuelve la consulta de las etiquetas de un usuario o de un grupo
+func (s *LabelService) scopeQuery(ownerID string, groupID *string) firestore.Query {
+	labelsRef := database.Client.Collection("labels")
+	if groupID != nil && *groupID != "" {
+		return labelsRef.Where("group_id", "==", *groupID)
+	}
+	return labelsRef.Where("owner_id", "==", ownerID)
+}
+
+// ListLabels obtiene las etiquetas personales de un usuario o las de un grupo
+func (s *LabelService) ListLabels(ownerID string, groupID *string) ([]models.Label, error) {
+	ctx := context.Background()
+
+	docs, err := s.scopeQuery(ownerID, groupID).Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error listing labels: %v", err)
+		return nil, err
+	}
+
+	labels := []models.Label{}
+	for _, doc := range docs {
+		var label models.Label
+		if err := doc.DataTo(&label); err != nil {
+			log.Printf("Error converting document to Label: %v", err)
+			continue
+		}
+		// Las etiquetas personales no incluyen las de grupo creadas por el mismo usuario
+		if (groupID == nil || *groupID == "") && label.IsGroupLabel() {
+			continue
+		}
+		labels = append(labels, label)
+	}
+
+	return labels, nil
+}
+
+// GetLabel obtiene una etiqueta por su ID
+func (s *LabelService) GetLabel(labelID string) (*models.Label, error) {
+	ctx := context.Background()
+
+	doc, err := database.Client.Collection("labels").Doc(labelID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrLabelNotFound
+		}
+		log.Printf("Error getting label from Firestore: %v", err)
+		return nil, err
+	}
+
+	var label models.Label
+	if err := doc.DataTo(&label); err != nil {
+		return nil, err
+	}
+
+	return &label, nil
+}
+
+// nameTaken comprueba si el nombre ya se usa en el ámbito de la etiqueta
+func (s *LabelService) nameTaken(label *models.Label) (bool, error) {
+	existing, err := s.ListLabels(label.OwnerID, label.GroupID)
+	if err != nil {
+		return false, err
+	}
+	for _, other := range existing {
+		if other.ID != label.ID && strings.EqualFold(other.Name, label.Name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CreateLabel crea una nueva etiqueta
+func (s *LabelService) CreateLabel(label *models.Label) error {
+	ctx := context.Background()
+
+	taken, err := s.nameTaken(label)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrLabelExists
+	}
+
+	label.ID = uuid.New().String()
+	label.CreatedAt = time.Now()
+	label.UpdatedAt = label.CreatedAt
+
+	if _, err := database.Client.Collection("labels").Doc(label.ID).Set(ctx, label); err != nil {
+		log.Printf("Error creating label in Firestore: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateLabel cambia el nombre o el color de una etiqueta y actualiza la copia
+// guardada en todas las tareas que la usan
+func (s *LabelService) UpdateLabel(label *models.Label) error {
+	ctx := context.Background()
+
+	taken, err := s.nameTaken(label)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrLabelExists
+	}
+
+	label.UpdatedAt = time.Now()
+	_, err = database.Client.Collection("labels").Doc(label.ID).Update(ctx, []firestore.Update{
+		{Path: "name", Value: label.Name},
+		{Path: "color", Value: label.Color},
+		{Path: "updated_at", Value: label.UpdatedAt},
+	})
+	if err != nil {
+		log.Printf("Error updating label: %v", err)
+		return err
+	}
+
+	return s.rewriteTaskLabels(ctx, label.ID, func(labels []models.TaskLabel) []models.TaskLabel {
+		for i := range labels {
+			if labels[i].ID == label.ID {
+				labels[i] = label.ToTaskLabel()
+			}
+		}
+		return labels
+	}, nil)
+}
+
+// DeleteLabel elimina una etiqueta y la quita de todas las tareas que la usan
+func (s *LabelService) DeleteLabel(labelID string) error {
+	ctx := context.Background()
+
+	err := s.rewriteTaskLabels(ctx, labelID, func(labels []models.TaskLabel) []models.TaskLabel {
+		var kept []models.TaskLabel
+		for _, l := range labels {
+			if l.ID != labelID {
+				kept = append(kept, l)
+			}
+		}
+		return kept
+	}, []firestore.Update{{Path: "label_ids", Value: firestore.ArrayRemove(labelID)}})
+	if err != nil {
+		return err
+	}
+
+	if _, err := database.Client.Collection("labels").Doc(labelID).Delete(ctx); err != nil {
+		log.Printf("Error deleting label: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// rewriteTaskLabels aplica un cambio a la copia de etiquetas de todas las tareas que usan la etiqueta
+func (s *LabelService) rewriteTaskLabels(ctx context.Context, labelID string, rewrite func([]models.TaskLabel) []models.TaskLabel, extra []firestore.Update) error {
+	docs, err := database.Client.Collection("tasks").Where("label_ids", "array-contains", labelID).Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error fetching tasks with label %s: %v", labelID, err)
+		return err
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+
+	writer := database.Client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(docs))
+
+	for _, doc := range docs {
+		var task models.Task
+		if err := doc.DataTo(&task); err != nil {
+			log.Printf("Error converting document to task: %v", err)
+			continue
+		}
+
+		updates := append([]firestore.Update{
+			{Path: "labels", Value: rewrite(task.Labels)},
+			{Path: "version", Value: firestore.Increment(1)},
+		}, extra...)
+
+		job, err := writer.Update(doc.Ref, updates)
+		if err != nil {
+			log.Printf("Error queueing label update for task %s: %v", doc.Ref.ID, err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	writer.End()
+
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			log.Printf("Error updating task labels: %v", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ResolveTaskLabels convierte los IDs de etiqueta de una tarea en sus copias,
+// comprobando que cada etiqueta pertenezca al ámbito de la tarea
+func (s *LabelService) ResolveTaskLabels(task *models.Task) error {
+	if len(task.LabelIDs) == 0 {
+		task.LabelIDs = nil
+		task.Labels = nil
+		return nil
+	}
+
+	ctx := context.Background()
+
+	seen := make(map[string]bool)
+	var refs []*firestore.DocumentRef
+	for _, id := range task.LabelIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		refs = append(refs, database.Client.Collection("labels").Doc(id))
+	}
+
+	docs, err := database.Client.GetAll(ctx, refs)
+	if err != nil {
+		log.Printf("Error fetching labels: %v", err)
+		return err
+	}
+
+	ids := make([]string, 0, len(docs))
+	labels := make([]models.TaskLabel, 0, len(docs))
+	for _, doc := range docs {
+		if !doc.Exists() {
+			return ErrLabelNotFound
+		}
+
+		var label models.Label
+		if err := doc.DataTo(&label); err != nil {
+			return err
+		}
+
+		// Las tareas de grupo usan etiquetas del grupo; las personales, las del propietario
+		if task.GroupID != nil && *task.GroupID != "" {
+			if !label.IsGroupLabel() || *label.GroupID != *task.GroupID {
+				return ErrLabelScope
+			}
+		} else if label.IsGroupLabel() || label.OwnerID != task.UserID {
+			return ErrLabelScope
+		}
+
+		ids = append(ids, label.ID)
+		labels = append(labels, label.ToTaskLabel())
+	}
+
+	task.LabelIDs = ids
+	task.Labels = labels
+	return nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"task-manager-backend/internal/i18n"
+	texttemplate "text/template"
+	"time"
+)
+
+// templateFS contiene las plantillas de los correos, en una carpeta por idioma
+//
+//go:embed templates/*/*.tmpl
+var templateFS embed.FS
+
+// mailTemplate es la plantilla de un correo, en texto plano y en HTML, en cada
+// idioma admitido
+type mailTemplate struct {
+	text map[i18n.Locale]*texttemplate.Template
+	html map[i18n.Locale]*htmltemplate.Template
+}
+
+// mustParseMailTemplate carga templates/<idioma>/<name>.txt.tmpl y
+// templates/<idioma>/<name>.html.tmpl de todos los idiomas admitidos
+func mustParseMailTemplate(name string) *mailTemplate {
+	t := &mailTemplate{
+		text: make(map[i18n.Locale]*texttemplate.Template),
+		html: make(map[i18n.Locale]*htmltemplate.Template),
+	}
+	for _, locale := range i18n.Supported() {
+		funcs := mailTemplateFuncs(locale)
+		path := "templates/" + string(locale) + "/" + name
+		t.text[locale] = texttemplate.Must(texttemplate.New(name+".txt.tmpl").Funcs(funcs).ParseFS(templateFS, path+".txt.tmpl"))
+		t.html[locale] = htmltemplate.Must(htmltemplate.New(name+".html.tmpl").Funcs(funcs).ParseFS(templateFS, path+".html.tmpl"))
+	}
+	return t
+}
+
+// mailTemplateFuncs son las funciones de las plantillas, que dan formato a las
+// fechas y traducen los valores en el idioma de la plantilla
+func mailTemplateFuncs(locale i18n.Locale) map[string]interface{} {
+	return map[string]interface{}{
+		"date":      func(t time.Time) string { return i18n.FormatTime(locale, t, "Monday, January 2") },
+		"datetime":  func(t time.Time) string { return i18n.FormatTime(locale, t, "Jan 2 15:04 MST") },
+		"fulldate":  func(t time.Time) string { return i18n.FormatTime(locale, t, "January 2, 2006") },
+		"frequency": func(frequency string) string { return i18n.T(locale, "digest."+frequency) },
+	}
+}
+
+// Render genera el cuerpo en texto plano y en HTML en el idioma indicado, o en
+// el de por defecto si no está admitido
+func (t *mailTemplate) Render(locale i18n.Locale, data interface{}) (string, string, error) {
+	if _, ok := t.text[locale]; !ok {
+		locale = i18n.Default
+	}
+
+	var text, html bytes.Buffer
+	if err := t.text[locale].Execute(&text, data); err != nil {
+		return "", "", err
+	}
+	if err := t.html[locale].Execute(&html, data); err != nil {
+		return "", "", err
+	}
+	return text.String(), html.String(), nil
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/events"
+	"task-manager-backend/internal/models"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrNotificationNotFound indica que la notificación no existe o pertenece a otro usuario
+var ErrNotificationNotFound = errors.New("notification not found")
+
+const (
+	// notificationTTL es el tiempo que se conserva una notificación si no se indica otro
+	notificationTTL = 30 * 24 * time.Hour
+	// maxNotifications es el número máximo de notificaciones que se devuelven en una lista
+	maxNotifications = 100
+)
+
+// NotificationService proporciona métodos para gestionar la bandeja de notificaciones.
+// Las notificaciones caducan en expires_at: si la colección tiene una política TTL
+// de Firestore sobre ese campo se eliminan solas; DeleteExpired hace la misma limpieza.
+type NotificationService struct{}
+
+// NewNotificationService crea una nueva instancia de NotificationService
+func NewNotificationService() *NotificationService {
+	return &NotificationService{}
+}
+
+// Notify guarda una notificación si el destinatario tiene activado su tipo y la
+// publica en el bus de eventos. Si la notificación trae un ID y ya existe, no se
+// duplica. Devuelve si se creó.
+func (s *NotificationService) Notify(notification *models.Notification) (bool, error) {
+	ctx := context.Background()
+
+	preferences, err := s.GetPreferences(notification.UserID)
+	if err != nil {
+		return false, err
+	}
+	if !preferences.Enabled(notification.Type) {
+		return false, nil
+	}
+
+	if notification.ID == "" {
+		notification.ID = uuid.New().String()
+	}
+	notification.Read = false
+	notification.ReadAt = nil
+	notification.CreatedAt = time.Now()
+	if notification.ExpiresAt.IsZero() {
+		notification.ExpiresAt = notification.CreatedAt.Add(notificationTTL)
+	}
+
+	if _, err := database.Client.Collection("notifications").Doc(notification.ID).Create(ctx, notification); err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			return false, nil
+		}
+		log.Printf("Error creating notification in Firestore: %v", err)
+		return false, err
+	}
+
+	events.Publish(events.Event{
+		Type:     events.NotificationCreated,
+		Data:     *notification,
+		Audience: []string{notification.UserID},
+		ActorID:  notification.ActorID,
+	})
+
+	return true, nil
+}
+
+// ListNotifications obtiene las notificaciones vigentes de un usuario, de la más
+// reciente a la más antigua
+func (s *NotificationService) ListNotifications(userID string, unreadOnly bool) ([]models.Notification, error) {
+	ctx := context.Background()
+
+	q := database.Client.Collection("notifications").Where("user_id", "==", userID)
+	if unreadOnly {
+		q = q.Where("read", "==", false)
+	}
+
+	docs, err := q.Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error listing notifications: %v", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	notifications := []models.Notification{}
+	for _, doc := range docs {
+		var notification models.Notification
+		if err := doc.DataTo(&notification); err != nil {
+			log.Printf("Error converting document to Notification: %v", err)
+			continue
+		}
+		if notification.Expired(now) {
+			continue
+		}
+		notifications = append(notifications, notification)
+	}
+
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+	})
+	if len(notifications) > maxNotifications {
+		notifications = notifications[:maxNotifications]
+	}
+
+	return notifications, nil
+}
+
+// UnreadCount cuenta las notificaciones sin leer de un usuario
+func (s *NotificationService) UnreadCount(userID string) (int64, error) {
+	ctx := context.Background()
+
+	q := database.Client.Collection("notifications").
+		Where("user_id", "==", userID).
+		Where("read", "==", false)
+
+	result, err := q.NewAggregationQuery().WithCount("unread").Get(ctx)
+	if err != nil {
+		log.Printf("Error counting unread notifications: %v", err)
+		return 0, err
+	}
+
+	count, ok := result["unread"].(*firestorepb.Value)
+	if !ok {
+		return 0, errors.New("unexpected aggregation result")
+	}
+
+	return count.GetIntegerValue(), nil
+}
+
+// MarkRead marca como leída una notificación del usuario
+func (s *NotificationService) MarkRead(userID, notificationID string) (*models.Notification, error) {
+	ctx := context.Background()
+	notificationRef := database.Client.Collection("notifications").Doc(notificationID)
+
+	var notification models.Notification
+	err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(notificationRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrNotificationNotFound
+			}
+			return err
+		}
+		if err := doc.DataTo(&notification); err != nil {
+			return err
+		}
+		if notification.UserID != userID {
+			return ErrNotificationNotFound
+		}
+		if notification.Read {
+			return nil
+		}
+
+		now := time.Now()
+		notification.Read = true
+		notification.ReadAt = &now
+		return tx.Update(notificationRef, []firestore.Update{
+			{Path: "read", Value: true},
+			{Path: "read_at", Value: now},
+		})
+	})
+	if err != nil {
+		if !errors.Is(err, ErrNotificationNotFound) {
+			log.Printf("Error marking notification as read: %v", err)
+		}
+		return nil, err
+	}
+
+	return &notification, nil
+}
+
+// MarkAllRead marca como leídas todas las notificaciones del usuario y devuelve cuántas cambiaron
+func (s *NotificationService) MarkAllRead(userID string) (int, error) {
+	ctx := context.Background()
+
+	docs, err := database.Client.Collection("notifications").
+		Where("user_id", "==", userID).
+		Where("read", "==", false).
+		Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error fetching unread notifications: %v", err)
+		return 0, err
+	}
+
+	now := time.Now()
+	writer := database.Client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(docs))
+	for _, doc := range docs {
+		job, err := writer.Update(doc.Ref, []firestore.Update{
+			{Path: "read", Value: true},
+			{Path: "read_at", Value: now},
+		})
+		if err != nil {
+			log.Printf("Error marking notification %s as read: %v", doc.Ref.ID, err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	writer.End()
+
+	updated := 0
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			log.Printf("Error marking notification as read: %v", err)
+			continue
+		}
+		updated++
+	}
+
+	return updated, nil
+}
+
+// DeleteExpired elimina las notificaciones caducadas y devuelve cuántas eliminó
+func (s *NotificationService) DeleteExpired() (int, error) {
+	ctx := context.Background()
+
+	docs, err := database.Client.Collection("notifications").Where("expires_at", "<=", time.Now()).Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error fetching expired notifications: %v", err)
+		return 0, err
+	}
+
+	writer := database.Client.BulkWriter(ctx)
+	for _, doc := range docs {
+		if _, err := writer.Delete(doc.Ref); err != nil {
+			log.Printf("Error deleting notification %s: %v", doc.Ref.ID, err)
+		}
+	}
+	writer.End()
+
+	return len(docs), nil
+}
+
+// GetPreferences obtiene las preferencias de notificación de un usuario.
+// Si no las ha configurado, todos los tipos están activados.
+func (s *NotificationService) GetPreferences(userID string) (*models.NotificationPreferences, error) {
+	ctx := context.Background()
+
+	doc, err := database.Client.Collection("notification_preferences").Doc(userID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return &models.NotificationPreferences{UserID: userID, Types: map[string]bool{}}, nil
+		}
+		log.Printf("Error getting notification preferences: %v", err)
+		return nil, err
+	}
+
+	var preferences models.NotificationPreferences
+	if err := doc.DataTo(&preferences); err != nil {
+		return nil, err
+	}
+	if preferences.Types == nil {
+		preferences.Types = map[string]bool{}
+	}
+
+	return &preferences, nil
+}
+
+// UpdatePreferences guarda las preferencias de notificación de un usuario
+func (s *NotificationService) UpdatePreferences(preferences *models.NotificationPreferences) error {
+	ctx := context.Background()
+
+	preferences.UpdatedAt = time.Now()
+
+	if _, err := database.Client.Collection("notification_preferences").Doc(preferences.UserID).Set(ctx, preferences); err != nil {
+		log.Printf("Error saving notification preferences: %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sync"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/events"
+	"task-manager-backend/internal/i18n"
+	"task-manager-backend/internal/models"
+	"time"
+)
+
+const (
+	// dueSoonWindow es la antelación con la que se avisa de que una tarea vence
+	dueSoonWindow = 24 * time.Hour
+	// notifierInterval es cada cuánto se buscan tareas próximas a vencer y notificaciones caducadas
+	notifierInterval = 15 * time.Minute
+)
+
+// mentionPattern reconoce las menciones @username (ver isValidUsername)
+var mentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9_@-])@([A-Za-z0-9_-]{3,20})`)
+
+// Notifier crea las notificaciones a partir de los eventos del bus y, periódicamente,
+// las de las tareas próximas a vencer
+type Notifier struct {
+	notificationService *NotificationService
+	groupService        *GroupService
+	ctx                 context.Context
+	cancel              context.CancelFunc
+	wg                  sync.WaitGroup
+}
+
+// NewNotifier crea un Notifier
+func NewNotifier() *Notifier {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Notifier{
+		notificationService: NewNotificationService(),
+		groupService:        NewGroupService(),
+		ctx:                 ctx,
+		cancel:              cancel,
+	}
+}
+
+// Start suscribe el notifier al bus de eventos y arranca la revisión periódica
+func (n *Notifier) Start() {
+	n.wg.Add(2)
+	go func() {
+		defer n.wg.Done()
+		events.Consume(n.ctx, nil, n.handleEvent)
+	}()
+	go func() {
+		defer n.wg.Done()
+		n.sweep()
+
+		ticker := time.NewTicker(notifierInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-n.ctx.Done():
+				return
+			case <-ticker.C:
+				n.sweep()
+			}
+		}
+	}()
+}
+
+// Stop detiene el notifier
+func (n *Notifier) Stop() {
+	n.cancel()
+	n.wg.Wait()
+}
+
+// handleEvent crea las notificaciones de asignación, mención e invitación a un grupo
+func (n *Notifier) handleEvent(event events.Event) {
+	switch event.Type {
+	case events.TaskCreated, events.TaskUpdated:
+		payload, ok := event.Data.(events.TaskPayload)
+		if !ok {
+			return
+		}
+		n.notifyAssignment(event, payload)
+		n.notifyMentions(event, payload)
+	case events.GroupInvitationCreated:
+		payload, ok := event.Data.(events.InvitationPayload)
+		invitation := payload.Invitation
+		if !ok || invitation.InviteeID == nil || *invitation.InviteeID == event.ActorID {
+			return
+		}
+		n.notify(&models.Notification{
+			UserID:  *invitation.InviteeID,
+			Type:    models.NotificationGroupInvite,
+			Title:   n.title(*invitation.InviteeID, models.NotificationGroupInvite, invitation.GroupName),
+			ActorID: event.ActorID,
+			GroupID: &invitation.GroupID,
+		})
+	}
+}
+
+// notifyAssignment avisa al usuario asignado si la asignación es nueva
+func (n *Notifier) notifyAssignment(event events.Event, payload events.TaskPayload) {
+	task := payload.Task
+	if task.AssignedTo == nil || *task.AssignedTo == "" || *task.AssignedTo == event.ActorID {
+		return
+	}
+	if previous := payload.Previous; previous != nil && previous.AssignedTo != nil && *previous.AssignedTo == *task.AssignedTo {
+		return
+	}
+
+	n.notify(&models.Notification{
+		UserID:  *task.AssignedTo,
+		Type:    models.NotificationAssignment,
+		Title:   n.title(*task.AssignedTo, models.NotificationAssignment, task.Title),
+		ActorID: event.ActorID,
+		TaskID:  &task.ID,
+		GroupID: task.GroupID,
+	})
+}
+
+// notifyMentions avisa a los usuarios mencionados en el título o la descripción.
+// En las actualizaciones solo cuentan las menciones nuevas, y solo se avisa a
+// quienes pueden ver la tarea.
+func (n *Notifier) notifyMentions(event events.Event, payload events.TaskPayload) {
+	task := payload.Task
+	usernames := mentions(task.Title + "\n" + task.Description)
+	if payload.Previous != nil {
+		for username := range mentions(payload.Previous.Title + "\n" + payload.Previous.Description) {
+			delete(usernames, username)
+		}
+	}
+	if len(usernames) == 0 {
+		return
+	}
+
+	userIDs, err := userIDsByUsername(models.WorkspaceOf(task.WorkspaceID), usernames)
+	if err != nil {
+		return
+	}
+
+	for _, userID := range userIDs {
+		if userID == event.ActorID || !event.VisibleTo(userID) {
+			continue
+		}
+		n.notify(&models.Notification{
+			UserID:  userID,
+			Type:    models.NotificationMention,
+			Title:   n.title(userID, models.NotificationMention, task.Title),
+			ActorID: event.ActorID,
+			TaskID:  &task.ID,
+			GroupID: task.GroupID,
+		})
+	}
+}
+
+// sweep avisa de las tareas que vencen pronto y elimina las notificaciones caducadas
+func (n *Notifier) sweep() {
+	n.notifyDueSoon()
+
+	if _, err := n.notificationService.DeleteExpired(); err != nil {
+		log.Printf("Error deleting expired notifications: %v", err)
+	}
+}
+
+// notifyDueSoon avisa al asignado (o al propietario, si no hay) de las tareas con
+// fecha de vencimiento en las próximas dueSoonWindow horas que no están terminadas.
+// El ID de la notificación depende de la tarea, el usuario y la fecha, de modo que
+// cada vencimiento se avisa una sola vez aunque la revisión se repita.
+func (n *Notifier) notifyDueSoon() {
+	ctx := n.ctx
+	now := time.Now()
+
+	docs, err := database.Client.Collection("tasks").
+		Where("due_date", ">", now).
+		Where("due_date", "<=", now.Add(dueSoonWindow)).
+		Documents(ctx).GetAll()
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Error fetching tasks due soon: %v", err)
+		}
+		return
+	}
+
+	workflows := map[string]*models.Workflow{}
+	for _, doc := range docs {
+		var task models.Task
+		if err := doc.DataTo(&task); err != nil || task.DueDate == nil {
+			continue
+		}
+
+		workflow, ok := n.taskWorkflow(&task, workflows)
+		if !ok || workflow.IsFinal(task.Status) {
+			continue
+		}
+
+		userID := task.UserID
+		if task.AssignedTo != nil && *task.AssignedTo != "" {
+			userID = *task.AssignedTo
+		}
+
+		taskID := task.ID
+		locale, _ := UserLocale(ctx, userID)
+		n.notify(&models.Notification{
+			ID:        fmt.Sprintf("due_soon_%s_%s_%d", task.ID, userID, task.DueDate.Unix()),
+			UserID:    userID,
+			Type:      models.NotificationDueSoon,
+			Title:     i18n.T(locale, "notification."+models.NotificationDueSoon, task.Title, i18n.FormatTime(locale, task.DueDate.UTC(), time.RFC1123)),
+			TaskID:    &taskID,
+			GroupID:   task.GroupID,
+			ExpiresAt: task.DueDate.Add(dueSoonWindow),
+		})
+	}
+}
+
+// taskWorkflow devuelve el flujo de trabajo de la tarea, guardando los de los grupos en workflows
+func (n *Notifier) taskWorkflow(task *models.Task, workflows map[string]*models.Workflow) (*models.Workflow, bool) {
+	if task.GroupID == nil || *task.GroupID == "" {
+		return models.DefaultWorkflow(), true
+	}
+
+	if workflow, ok := workflows[*task.GroupID]; ok {
+		return workflow, workflow != nil
+	}
+
+	group, err := n.groupService.GetGroupByID(*task.GroupID)
+	if err != nil {
+		workflows[*task.GroupID] = nil
+		return nil, false
+	}
+	workflows[*task.GroupID] = group.TaskWorkflow()
+	return workflows[*task.GroupID], true
+}
+
+// title compone el título de una notificación en el idioma del destinatario (ver
+// UserLocale). La clave del catálogo es el tipo de notificación.
+func (n *Notifier) title(userID, notificationType string, args ...interface{}) string {
+	locale, _ := UserLocale(n.ctx, userID)
+	return i18n.T(locale, "notification."+notificationType, args...)
+}
+
+func (n *Notifier) notify(notification *models.Notification) {
+	if _, err := n.notificationService.Notify(notification); err != nil {
+		log.Printf("Error creating %s notification for user %s: %v", notification.Type, notification.UserID, err)
+	}
+}
+
+// mentions devuelve los nombres de usuario mencionados en el texto
+func mentions(text string) map[string]bool {
+	usernames := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		usernames[match[1]] = true
+	}
+	return usernames
+}
+
+// userIDsByUsername obtiene los IDs de los usuarios del espacio de trabajo con esos nombres
+func userIDsByUsername(workspaceID string, usernames map[string]bool) ([]string, error) {
+	ctx := context.Background()
+
+	wanted := make([]string, 0, len(usernames))
+	for username := range usernames {
+		wanted = append(wanted, username)
+	}
+
+	var userIDs []string
+	// Firestore admite como máximo 30 valores en una consulta "in"
+	for start := 0; start < len(wanted); start += 30 {
+		end := start + 30
+		if end > len(wanted) {
+			end = len(wanted)
+		}
+
+		docs, err := database.InWorkspace(database.Client.Collection("users").Query, workspaceID).
+			Where("username", "in", wanted[start:end]).
+			Documents(ctx).GetAll()
+		if err != nil {
+			log.Printf("Error fetching mentioned users: %v", err)
+			return nil, err
+		}
+		for _, doc := range docs {
+			userIDs = append(userIDs, doc.Ref.ID)
+		}
+	}
+
+	return userIDs, nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrTemplateNotFound indica que la plantilla no existe
+var ErrTemplateNotFound = errors.New("template not found")
+
+// TemplateService proporciona métodos para gestionar plantillas de tareas
+type TemplateService struct{}
+
+// NewTemplateService crea una nueva instancia de TemplateService
+func NewTemplateService() *TemplateService {
+	return &TemplateService{}
+}
+
+// ListTemplates obtiene las plantillas personales de un usuario o las compartidas con un grupo
+func (s *TemplateService) ListTemplates(ownerID string, groupID *string) ([]models.TaskTemplate, error) {
+	ctx := context.Background()
+
+	templatesRef := database.Client.Collection("task_templates")
+	q := templatesRef.Where("owner_id", "==", ownerID)
+	if groupID != nil && *groupID != "" {
+		q = templatesRef.Where("group_id", "==", *groupID)
+	}
+
+	docs, err := q.Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error listing templates: %v", err)
+		return nil, err
+	}
+
+	templates := []models.TaskTemplate{}
+	for _, doc := range docs {
+		var template models.TaskTemplate
+		if err := doc.DataTo(&template); err != nil {
+			log.Printf("Error converting document to TaskTemplate: %v", err)
+			continue
+		}
+		// La lista personal no incluye las plantillas compartidas con grupos
+		if (groupID == nil || *groupID == "") && template.IsGroupTemplate() {
+			continue
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, nil
+}
+
+// GetTemplate obtiene una plantilla por su ID
+func (s *TemplateService) GetTemplate(templateID string) (*models.TaskTemplate, error) {
+	ctx := context.Background()
+
+	doc, err := database.Client.Collection("task_templates").Doc(templateID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrTemplateNotFound
+		}
+		log.Printf("Error getting template from Firestore: %v", err)
+		return nil, err
+	}
+
+	var template models.TaskTemplate
+	if err := doc.DataTo(&template); err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// CreateTemplate crea una nueva plantilla
+func (s *TemplateService) CreateTemplate(template *models.TaskTemplate) error {
+	ctx := context.Background()
+
+	template.ID = uuid.New().String()
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = template.CreatedAt
+
+	if _, err := database.Client.Collection("task_templates").Doc(template.ID).Set(ctx, template); err != nil {
+		log.Printf("Error creating template in Firestore: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateTemplate reemplaza el contenido de una plantilla
+func (s *TemplateService) UpdateTemplate(template *models.TaskTemplate) error {
+	ctx := context.Background()
+
+	template.UpdatedAt = time.Now()
+
+	if _, err := database.Client.Collection("task_templates").Doc(template.ID).Set(ctx, template); err != nil {
+		log.Printf("Error updating template in Firestore: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteTemplate elimina una plantilla
+func (s *TemplateService) DeleteTemplate(templateID string) error {
+	ctx := context.Background()
+
+	if _, err := database.Client.Collection("task_templates").Doc(templateID).Delete(ctx); err != nil {
+		log.Printf("Error deleting template: %v", err)
+		return err
+	}
+
+	return nil
+}
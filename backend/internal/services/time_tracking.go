@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/models"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrTimerRunning indica que el usuario ya tiene un temporizador en marcha
+	ErrTimerRunning = errors.New("a timer is already running")
+	// ErrNoTimerRunning indica que el usuario no tiene ningún temporizador en marcha
+	ErrNoTimerRunning = errors.New("no timer is running")
+	// ErrTimeEntryNotFound indica que el registro de tiempo no existe
+	ErrTimeEntryNotFound = errors.New("time entry not found")
+)
+
+// ActiveTimer es el documento que marca el temporizador en marcha de un usuario.
+// Se guarda con el ID del usuario para que solo pueda existir uno.
+type ActiveTimer struct {
+	EntryID   string    `json:"entry_id" firestore:"entry_id"`
+	TaskID    string    `json:"task_id" firestore:"task_id"`
+	StartedAt time.Time `json:"started_at" firestore:"started_at"`
+}
+
+// TimeSummaryFilter acota los registros que se agregan en un resumen
+type TimeSummaryFilter struct {
+	TaskID  string
+	UserID  string
+	GroupID string
+	From    time.Time
+	To      time.Time
+	GroupBy string         // task, user, group o date
+	Loc     *time.Location // Zona horaria para agrupar por fecha
+}
+
+// TimeSummaryBucket es el tiempo total de una clave del resumen
+type TimeSummaryBucket struct {
+	Key      string        `json:"key"`
+	Duration time.Duration `json:"duration"`
+	Hours    float64       `json:"hours"`
+	Entries  int           `json:"entries"`
+}
+
+// TimeTrackingService proporciona métodos para registrar tiempo sobre tareas
+type TimeTrackingService struct{}
+
+// NewTimeTrackingService crea una nueva instancia de TimeTrackingService
+func NewTimeTrackingService() *TimeTrackingService {
+	return &TimeTrackingService{}
+}
+
+// StartTimer inicia un temporizador del usuario sobre la tarea
+func (s *TimeTrackingService) StartTimer(task *models.Task, userID string) (*models.TimeEntry, error) {
+	ctx := context.Background()
+
+	now := time.Now()
+	entry := models.TimeEntry{
+		ID:        uuid.New().String(),
+		TaskID:    task.ID,
+		UserID:    userID,
+		GroupID:   task.GroupID,
+		StartedAt: now,
+		Source:    models.TimeEntrySourceTimer,
+		CreatedAt: now,
+	}
+
+	activeRef := database.Client.Collection("active_timers").Doc(userID)
+	entryRef := database.Client.Collection("time_entries").Doc(entry.ID)
+
+	err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		_, err := tx.Get(activeRef)
+		if err == nil {
+			return ErrTimerRunning
+		}
+		if status.Code(err) != codes.NotFound {
+			return err
+		}
+
+		if err := tx.Create(entryRef, entry); err != nil {
+			return err
+		}
+		return tx.Create(activeRef, ActiveTimer{EntryID: entry.ID, TaskID: task.ID, StartedAt: now})
+	})
+	if err != nil {
+		if !errors.Is(err, ErrTimerRunning) {
+			log.Printf("Error starting timer: %v", err)
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// StopTimer detiene el temporizador en marcha del usuario y cierra su registro
+func (s *TimeTrackingService) StopTimer(userID string) (*models.TimeEntry, error) {
+	ctx := context.Background()
+
+	activeRef := database.Client.Collection("active_timers").Doc(userID)
+	var entry models.TimeEntry
+
+	err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		activeDoc, err := tx.Get(activeRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrNoTimerRunning
+			}
+			return err
+		}
+
+		var active ActiveTimer
+		if err := activeDoc.DataTo(&active); err != nil {
+			return err
+		}
+
+		entryRef := database.Client.Collection("time_entries").Doc(active.EntryID)
+		entryDoc, err := tx.Get(entryRef)
+		if err != nil {
+			return err
+		}
+
+		entry = models.TimeEntry{}
+		if err := entryDoc.DataTo(&entry); err != nil {
+			return err
+		}
+
+		ended := time.Now()
+		entry.EndedAt = &ended
+		entry.Duration = ended.Sub(entry.StartedAt)
+
+		if err := tx.Update(entryRef, []firestore.Update{
+			{Path: "ended_at", Value: ended},
+			{Path: "duration", Value: entry.Duration},
+		}); err != nil {
+			return err
+		}
+		return tx.Delete(activeRef)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrNoTimerRunning) {
+			log.Printf("Error stopping timer: %v", err)
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// GetActiveTimer obtiene el temporizador en marcha del usuario (nil si no hay ninguno)
+func (s *TimeTrackingService) GetActiveTimer(userID string) (*ActiveTimer, error) {
+	ctx := context.Background()
+
+	doc, err := database.Client.Collection("active_timers").Doc(userID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var active ActiveTimer
+	if err := doc.DataTo(&active); err != nil {
+		return nil, err
+	}
+
+	return &active, nil
+}
+
+// AddManualEntry guarda un registro de tiempo introducido a mano
+func (s *TimeTrackingService) AddManualEntry(entry *models.TimeEntry) error {
+	ctx := context.Background()
+
+	entry.ID = uuid.New().String()
+	entry.Source = models.TimeEntrySourceManual
+	entry.CreatedAt = time.Now()
+
+	if _, err := database.Client.Collection("time_entries").Doc(entry.ID).Set(ctx, entry); err != nil {
+		log.Printf("Error creating time entry: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetEntry obtiene un registro de tiempo por su ID
+func (s *TimeTrackingService) GetEntry(entryID string) (*models.TimeEntry, error) {
+	ctx := context.Background()
+
+	doc, err := database.Client.Collection("time_entries").Doc(entryID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrTimeEntryNotFound
+		}
+		return nil, err
+	}
+
+	var entry models.TimeEntry
+	if err := doc.DataTo(&entry); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// DeleteEntry elimina un registro de tiempo; si es un temporizador en marcha también lo detiene
+func (s *TimeTrackingService) DeleteEntry(entry *models.TimeEntry) error {
+	ctx := context.Background()
+
+	entryRef := database.Client.Collection("time_entries").Doc(entry.ID)
+	activeRef := database.Client.Collection("active_timers").Doc(entry.UserID)
+
+	return database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		activeDoc, err := tx.Get(activeRef)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+
+		clearActive := false
+		if err == nil {
+			var active ActiveTimer
+			if err := activeDoc.DataTo(&active); err == nil && active.EntryID == entry.ID {
+				clearActive = true
+			}
+		}
+
+		if err := tx.Delete(entryRef); err != nil {
+			return err
+		}
+		if clearActive {
+			return tx.Delete(activeRef)
+		}
+		return nil
+	})
+}
+
+// ListTaskEntries obtiene los registros de tiempo de una tarea, del más reciente al más antiguo
+func (s *TimeTrackingService) ListTaskEntries(taskID string) ([]models.TimeEntry, error) {
+	ctx := context.Background()
+
+	docs, err := database.Client.Collection("time_entries").Where("task_id", "==", taskID).Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error listing time entries: %v", err)
+		return nil, err
+	}
+
+	entries := decodeTimeEntries(docs)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].StartedAt.After(entries[j].StartedAt)
+	})
+
+	return entries, nil
+}
+
+// Summarize agrega el tiempo registrado según el filtro indicado.
+// Solo se cuentan los registros cerrados; los temporizadores en marcha se ignoran.
+func (s *TimeTrackingService) Summarize(filter TimeSummaryFilter) ([]TimeSummaryBucket, time.Duration, error) {
+	ctx := context.Background()
+
+	// Las consultas por rango de fechas combinadas con igualdad requieren índices compuestos
+	q := database.Client.Collection("time_entries").Query
+	if filter.TaskID != "" {
+		q = q.Where("task_id", "==", filter.TaskID)
+	}
+	if filter.UserID != "" {
+		q = q.Where("user_id", "==", filter.UserID)
+	}
+	if filter.GroupID != "" {
+		q = q.Where("group_id", "==", filter.GroupID)
+	}
+	if !filter.From.IsZero() {
+		q = q.Where("started_at", ">=", filter.From)
+	}
+	if !filter.To.IsZero() {
+		q = q.Where("started_at", "<", filter.To)
+	}
+
+	docs, err := q.Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error summarizing time entries: %v", err)
+		return nil, 0, err
+	}
+
+	loc := filter.Loc
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	buckets := make(map[string]*TimeSummaryBucket)
+	var total time.Duration
+
+	for _, entry := range decodeTimeEntries(docs) {
+		if entry.IsRunning() {
+			continue
+		}
+
+		var key string
+		switch filter.GroupBy {
+		case "user":
+			key = entry.UserID
+		case "group":
+			if entry.GroupID != nil {
+				key = *entry.GroupID
+			}
+		case "date":
+			key = entry.StartedAt.In(loc).Format("2006-01-02")
+		default:
+			key = entry.TaskID
+		}
+
+		bucket, ok := buckets[key]
+		if !ok {
+			bucket = &TimeSummaryBucket{Key: key}
+			buckets[key] = bucket
+		}
+		bucket.Duration += entry.Duration
+		bucket.Entries++
+		total += entry.Duration
+	}
+
+	result := make([]TimeSummaryBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		bucket.Hours = roundHours(bucket.Duration)
+		result = append(result, *bucket)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+
+	return result, total, nil
+}
+
+func decodeTimeEntries(docs []*firestore.DocumentSnapshot) []models.TimeEntry {
+	entries := make([]models.TimeEntry, 0, len(docs))
+	for _, doc := range docs {
+		var entry models.TimeEntry
+		if err := doc.DataTo(&entry); err != nil {
+			log.Printf("Error converting document to TimeEntry: %v", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// roundHours convierte una duración en horas con dos decimales
+func roundHours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/i18n"
+	"task-manager-backend/internal/models"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DecodeUser convierte un documento de la colección users en un usuario. Los
+// campos que faltan se completan con models.User.Normalize; un campo con un tipo
+// incorrecto produce un error en lugar de un pánico.
+func DecodeUser(doc *firestore.DocumentSnapshot) (*models.User, error) {
+	var user models.User
+	if err := doc.DataTo(&user); err != nil {
+		return nil, fmt.Errorf("decoding user %s: %w", doc.Ref.ID, err)
+	}
+	user.Normalize(doc.Ref.ID)
+	return &user, nil
+}
+
+// LoadUser obtiene un usuario por su ID, o ErrUserNotFound si no existe
+func LoadUser(ctx context.Context, userID string) (*models.User, error) {
+	doc, err := database.Client.Collection("users").Doc(userID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return DecodeUser(doc)
+}
+
+// FindUserByUsername obtiene el usuario con ese nombre, o ErrUserNotFound si no
+// existe. Los nombres de usuario son únicos en todos los espacios de trabajo.
+func FindUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	docs, err := database.Client.Collection("users").Where("username", "==", username).Limit(1).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, ErrUserNotFound
+	}
+	return DecodeUser(docs[0])
+}
+
+// UserWorkspaces obtiene el espacio de trabajo de cada usuario indexado por su ID.
+// Decide permisos, así que se lee de Firestore y no de la caché de perfiles; los
+// usuarios que no existen no aparecen en el resultado.
+func UserWorkspaces(ctx context.Context, userIDs []string) (map[string]string, error) {
+	refs := make([]*firestore.DocumentRef, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if userID != "" {
+			refs = append(refs, database.Client.Collection("users").Doc(userID))
+		}
+	}
+
+	docs, err := database.GetAll(ctx, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaces := make(map[string]string, len(docs))
+	for _, doc := range docs {
+		if !doc.Exists() {
+			continue
+		}
+		workspaceID, _ := doc.Data()["workspace_id"].(string)
+		workspaces[doc.Ref.ID] = models.WorkspaceOf(workspaceID)
+	}
+	return workspaces, nil
+}
+
+// UserLocale devuelve el idioma que eligió el usuario y si eligió alguno. Se lee
+// de la caché de perfiles, así que un cambio tarda como mucho userProfileTTL en
+// aplicarse en otras instancias.
+func UserLocale(ctx context.Context, userID string) (i18n.Locale, bool) {
+	profiles, err := UserProfiles.Get(ctx, []string{userID})
+	if err != nil {
+		return i18n.Default, false
+	}
+	locale, ok := i18n.Parse(profiles[userID].Locale)
+	if !ok {
+		return i18n.Default, false
+	}
+	return locale, true
+}
+
+// SetUserLocale guarda el idioma preferido del usuario. Con locale vacío se
+// elimina la preferencia y se vuelve a usar el de cada petición.
+func SetUserLocale(ctx context.Context, userID string, locale i18n.Locale) error {
+	value := interface{}(string(locale))
+	if locale == "" {
+		value = firestore.Delete
+	}
+
+	_, err := database.Client.Collection("users").Doc(userID).Update(ctx, []firestore.Update{
+		{Path: "locale", Value: value},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	UserProfiles.Invalidate(userID)
+	return nil
+}
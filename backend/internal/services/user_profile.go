@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/models"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+const (
+	// userProfileTTL es cuánto tiempo se reutiliza un perfil leído de Firestore
+	userProfileTTL = 30 * time.Second
+	// maxUserProfiles limita el número de perfiles guardados en la caché
+	maxUserProfiles = 10000
+)
+
+// UserProfiles es la caché de perfiles de usuario que comparten todos los
+// servicios y handlers
+var UserProfiles = NewUserProfileCache(userProfileTTL)
+
+// UserProfileCache guarda durante poco tiempo los perfiles de usuario (sin la
+// contraseña) para no leerlos de Firestore en cada petición. Los datos que deciden
+// permisos, como el rol o el propio espacio de trabajo, deben leerse siempre de
+// Firestore (véase UserWorkspaces).
+type UserProfileCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cachedProfile
+}
+
+type cachedProfile struct {
+	user      models.User
+	expiresAt time.Time
+}
+
+// NewUserProfileCache crea una caché cuyos perfiles caducan a los ttl
+func NewUserProfileCache(ttl time.Duration) *UserProfileCache {
+	return &UserProfileCache{
+		ttl:     ttl,
+		entries: make(map[string]cachedProfile),
+	}
+}
+
+// Get obtiene los perfiles de los usuarios indexados por ID. Los que no están en
+// la caché se leen de una vez; los usuarios que no existen no aparecen en el resultado.
+func (c *UserProfileCache) Get(ctx context.Context, userIDs []string) (map[string]models.User, error) {
+	profiles := make(map[string]models.User, len(userIDs))
+	now := time.Now()
+
+	var missing []*firestore.DocumentRef
+	c.mu.Lock()
+	for _, userID := range userIDs {
+		if _, ok := profiles[userID]; ok || userID == "" {
+			continue
+		}
+		if entry, ok := c.entries[userID]; ok && now.Before(entry.expiresAt) {
+			profiles[userID] = entry.user
+			continue
+		}
+		missing = append(missing, database.Client.Collection("users").Doc(userID))
+	}
+	c.mu.Unlock()
+
+	if len(missing) == 0 {
+		return profiles, nil
+	}
+
+	docs, err := database.GetAll(ctx, missing)
+	if err != nil {
+		log.Printf("Error fetching users: %v", err)
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prune(now)
+	for _, doc := range docs {
+		if !doc.Exists() {
+			continue
+		}
+		user, err := decodeUserProfile(doc)
+		if err != nil {
+			log.Printf("Error converting document to User %s: %v", doc.Ref.ID, err)
+			continue
+		}
+		profiles[user.ID] = user
+		c.entries[user.ID] = cachedProfile{user: user, expiresAt: now.Add(c.ttl)}
+	}
+
+	return profiles, nil
+}
+
+// Invalidate descarta los perfiles de los usuarios, que se volverán a leer
+func (c *UserProfileCache) Invalidate(userIDs ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, userID := range userIDs {
+		delete(c.entries, userID)
+	}
+}
+
+// prune elimina los perfiles caducados cuando la caché está llena
+func (c *UserProfileCache) prune(now time.Time) {
+	if len(c.entries) < maxUserProfiles {
+		return
+	}
+	for userID, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, userID)
+		}
+	}
+	if len(c.entries) >= maxUserProfiles {
+		c.entries = make(map[string]cachedProfile)
+	}
+}
+
+// decodeUserProfile convierte el documento en un usuario sin la contraseña
+func decodeUserProfile(doc *firestore.DocumentSnapshot) (models.User, error) {
+	user, err := DecodeUser(doc)
+	if err != nil {
+		return models.User{}, err
+	}
+	user.Password = ""
+	return *user, nil
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sort"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrWebhookNotFound indica que el webhook no existe
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrWebhookDeliveryNotFound indica que la entrega no existe
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// maxDeliveryLog es el número de entregas que se devuelven en el registro de un webhook
+const maxDeliveryLog = 100
+
+// WebhookService proporciona métodos para gestionar webhooks y su registro de entregas
+type WebhookService struct{}
+
+// NewWebhookService crea una nueva instancia de WebhookService
+func NewWebhookService() *WebhookService {
+	return &WebhookService{}
+}
+
+// ListWebhooks obtiene los webhooks personales de un usuario o los de un grupo
+func (s *WebhookService) ListWebhooks(workspaceID, ownerID string, groupID *string) ([]models.Webhook, error) {
+	ctx := context.Background()
+
+	webhooksRef := database.InWorkspace(database.Client.Collection("webhooks").Query, workspaceID)
+	q := webhooksRef.Where("owner_id", "==", ownerID)
+	if groupID != nil && *groupID != "" {
+		q = webhooksRef.Where("group_id", "==", *groupID)
+	}
+
+	docs, err := q.Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error listing webhooks: %v", err)
+		return nil, err
+	}
+
+	webhooks := []models.Webhook{}
+	for _, doc := range docs {
+		var webhook models.Webhook
+		if err := doc.DataTo(&webhook); err != nil {
+			log.Printf("Error converting document to Webhook: %v", err)
+			continue
+		}
+		// La lista personal no incluye los webhooks de grupo
+		if (groupID == nil || *groupID == "") && webhook.IsGroupWebhook() {
+			continue
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, nil
+}
+
+// GetWebhook obtiene un webhook por su ID
+func (s *WebhookService) GetWebhook(webhookID string) (*models.Webhook, error) {
+	ctx := context.Background()
+
+	doc, err := database.Client.Collection("webhooks").Doc(webhookID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrWebhookNotFound
+		}
+		log.Printf("Error getting webhook from Firestore: %v", err)
+		return nil, err
+	}
+
+	var webhook models.Webhook
+	if err := doc.DataTo(&webhook); err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// CreateWebhook crea un webhook y genera su clave de firma
+func (s *WebhookService) CreateWebhook(webhook *models.Webhook) error {
+	ctx := context.Background()
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+
+	webhook.ID = uuid.New().String()
+	webhook.Secret = hex.EncodeToString(secret)
+	webhook.CreatedAt = time.Now()
+	webhook.UpdatedAt = webhook.CreatedAt
+
+	if _, err := database.Client.Collection("webhooks").Doc(webhook.ID).Set(ctx, webhook); err != nil {
+		log.Printf("Error creating webhook in Firestore: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateWebhook reemplaza la configuración de un webhook
+func (s *WebhookService) UpdateWebhook(webhook *models.Webhook) error {
+	ctx := context.Background()
+
+	webhook.UpdatedAt = time.Now()
+
+	if _, err := database.Client.Collection("webhooks").Doc(webhook.ID).Set(ctx, webhook); err != nil {
+		log.Printf("Error updating webhook in Firestore: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteWebhook elimina un webhook junto con su registro de entregas
+func (s *WebhookService) DeleteWebhook(webhookID string) error {
+	ctx := context.Background()
+
+	docs, err := database.Client.Collection("webhook_deliveries").Where("webhook_id", "==", webhookID).Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error fetching webhook deliveries: %v", err)
+		return err
+	}
+
+	writer := database.Client.BulkWriter(ctx)
+	for _, doc := range docs {
+		if _, err := writer.Delete(doc.Ref); err != nil {
+			log.Printf("Error deleting webhook delivery %s: %v", doc.Ref.ID, err)
+		}
+	}
+	writer.End()
+
+	if _, err := database.Client.Collection("webhooks").Doc(webhookID).Delete(ctx); err != nil {
+		log.Printf("Error deleting webhook: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// SubscribedWebhooks obtiene los webhooks activos de un espacio de trabajo suscritos a un evento
+func (s *WebhookService) SubscribedWebhooks(workspaceID, event string) ([]models.Webhook, error) {
+	ctx := context.Background()
+
+	docs, err := database.InWorkspace(database.Client.Collection("webhooks").Query, workspaceID).
+		Where("events", "array-contains", event).
+		Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error fetching webhooks for event %s: %v", event, err)
+		return nil, err
+	}
+
+	var webhooks []models.Webhook
+	for _, doc := range docs {
+		var webhook models.Webhook
+		if err := doc.DataTo(&webhook); err != nil {
+			log.Printf("Error converting document to Webhook: %v", err)
+			continue
+		}
+		if webhook.Active {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+
+	return webhooks, nil
+}
+
+// ListDeliveries obtiene las últimas entregas de un webhook, de la más reciente a la más antigua
+func (s *WebhookService) ListDeliveries(webhookID string) ([]models.WebhookDelivery, error) {
+	ctx := context.Background()
+
+	docs, err := database.Client.Collection("webhook_deliveries").Where("webhook_id", "==", webhookID).Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error listing webhook deliveries: %v", err)
+		return nil, err
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	for _, doc := range docs {
+		var delivery models.WebhookDelivery
+		if err := doc.DataTo(&delivery); err != nil {
+			log.Printf("Error converting document to WebhookDelivery: %v", err)
+			continue
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	if len(deliveries) > maxDeliveryLog {
+		deliveries = deliveries[:maxDeliveryLog]
+	}
+
+	return deliveries, nil
+}
+
+// GetDelivery obtiene una entrega por su ID
+func (s *WebhookService) GetDelivery(deliveryID string) (*models.WebhookDelivery, error) {
+	ctx := context.Background()
+
+	doc, err := database.Client.Collection("webhook_deliveries").Doc(deliveryID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		log.Printf("Error getting webhook delivery from Firestore: %v", err)
+		return nil, err
+	}
+
+	var delivery models.WebhookDelivery
+	if err := doc.DataTo(&delivery); err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// SaveDelivery crea o actualiza el registro de una entrega
+func (s *WebhookService) SaveDelivery(delivery *models.WebhookDelivery) error {
+	ctx := context.Background()
+
+	if delivery.ID == "" {
+		delivery.ID = uuid.New().String()
+	}
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = time.Now()
+	}
+
+	if _, err := database.Client.Collection("webhook_deliveries").Doc(delivery.ID).Set(ctx, delivery); err != nil {
+		log.Printf("Error saving webhook delivery: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// PendingDeliveries obtiene las entregas que aún tienen reintentos pendientes
+func (s *WebhookService) PendingDeliveries() ([]models.WebhookDelivery, error) {
+	ctx := context.Background()
+
+	docs, err := database.Client.Collection("webhook_deliveries").Where("status", "==", models.WebhookDeliveryPending).Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error fetching pending webhook deliveries: %v", err)
+		return nil, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, doc := range docs {
+		var delivery models.WebhookDelivery
+		if err := doc.DataTo(&delivery); err != nil {
+			log.Printf("Error converting document to WebhookDelivery: %v", err)
+			continue
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,384 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"task-manager-backend/internal/events"
+	"task-manager-backend/internal/models"
+	"time"
+)
+
+const (
+	// webhookWorkers es el número de entregas que se envían en paralelo
+	webhookWorkers = 4
+	// webhookQueueSize es el número de entregas que pueden esperar a un worker
+	webhookQueueSize = 1000
+	// webhookTimeout es el tiempo máximo de espera de la respuesta del endpoint
+	webhookTimeout = 10 * time.Second
+	// webhookMaxAttempts es el número de intentos antes de dar la entrega por fallida
+	webhookMaxAttempts = 8
+	// webhookBaseBackoff es la espera antes del primer reintento; se duplica en cada intento
+	webhookBaseBackoff = 10 * time.Second
+	// webhookMaxBackoff limita la espera entre reintentos
+	webhookMaxBackoff = time.Hour
+)
+
+// errBlockedAddress indica que el endpoint resuelve a una dirección de red interna
+var errBlockedAddress = errors.New("webhook endpoint resolves to a private or loopback address")
+
+// WebhookPayload es el cuerpo JSON que recibe un webhook
+type WebhookPayload struct {
+	ID        string      `json:"id"`   // ID del evento
+	Type      string      `json:"type"` // Tipo de evento del webhook
+	CreatedAt time.Time   `json:"created_at"`
+	ActorID   string      `json:"actor_id,omitempty"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookDispatcher consume los eventos del bus y los entrega a los webhooks
+// suscritos en segundo plano, de modo que las peticiones no esperan a los endpoints
+// externos. Las entregas fallidas se reintentan con espera exponencial.
+type WebhookDispatcher struct {
+	webhookService *WebhookService
+	groupService   *GroupService
+	client         *http.Client
+	queue          chan *models.WebhookDelivery
+	ctx            context.Context
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
+}
+
+// NewWebhookDispatcher crea un dispatcher. Salvo que allowPrivateNetworks sea true,
+// no se entregan eventos a direcciones de loopback ni de redes privadas.
+func NewWebhookDispatcher(allowPrivateNetworks bool) *WebhookDispatcher {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !allowPrivateNetworks {
+		// Se comprueba la dirección ya resuelta para que un DNS no pueda redirigir a la red interna
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isBlockedIP(ip) {
+				return errBlockedAddress
+			}
+			return nil
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &WebhookDispatcher{
+		webhookService: NewWebhookService(),
+		groupService:   NewGroupService(),
+		client: &http.Client{
+			Timeout: webhookTimeout,
+			// Sin proxy: la comprobación de la dirección se hace al conectar
+			Transport: &http.Transport{DialContext: dialer.DialContext},
+			// Las redirecciones se tratan como un fallo de la entrega
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		queue:  make(chan *models.WebhookDelivery, webhookQueueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start suscribe el dispatcher al bus de eventos, arranca los workers y retoma
+// las entregas que quedaron pendientes antes de reiniciar el servidor
+func (d *WebhookDispatcher) Start() {
+	for i := 0; i < webhookWorkers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		events.Consume(d.ctx, nil, d.handleEvent)
+	}()
+
+	go d.resumePending()
+}
+
+// Stop detiene el dispatcher. Las entregas en curso se cancelan y quedan pendientes.
+func (d *WebhookDispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// Redeliver crea una entrega nueva con el mismo contenido que otra y la envía
+func (d *WebhookDispatcher) Redeliver(original *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{
+		WebhookID:    original.WebhookID,
+		EventID:      original.EventID,
+		EventType:    original.EventType,
+		Payload:      original.Payload,
+		Status:       models.WebhookDeliveryPending,
+		RedeliveryOf: original.ID,
+	}
+	if err := d.webhookService.SaveDelivery(delivery); err != nil {
+		return nil, err
+	}
+
+	d.enqueue(delivery)
+	return delivery, nil
+}
+
+// handleEvent crea las entregas de un evento para los webhooks suscritos
+func (d *WebhookDispatcher) handleEvent(event events.Event) {
+	eventTypes := []string{event.Type}
+	groupID, workspaceID := "", ""
+
+	switch payload := event.Data.(type) {
+	case events.TaskPayload:
+		workspaceID = payload.Task.WorkspaceID
+		if payload.Task.GroupID != nil {
+			groupID = *payload.Task.GroupID
+		}
+		if event.Type == events.TaskUpdated && d.completed(payload) {
+			eventTypes = append(eventTypes, models.WebhookEventTaskCompleted)
+		}
+	case events.GroupPayload:
+		workspaceID = payload.Group.WorkspaceID
+		groupID = payload.Group.ID
+	case events.InvitationPayload:
+		workspaceID = payload.Invitation.WorkspaceID
+	}
+
+	for _, eventType := range eventTypes {
+		if !models.IsWebhookEvent(eventType) {
+			continue
+		}
+
+		webhooks, err := d.webhookService.SubscribedWebhooks(workspaceID, eventType)
+		if err != nil {
+			continue
+		}
+
+		var body []byte
+		for _, webhook := range webhooks {
+			// Los webhooks de grupo reciben los eventos del grupo y los personales
+			// los eventos que su propietario puede ver
+			if webhook.IsGroupWebhook() {
+				if *webhook.GroupID != groupID {
+					continue
+				}
+			} else if !event.VisibleTo(webhook.OwnerID) {
+				continue
+			}
+
+			if body == nil {
+				if body, err = json.Marshal(WebhookPayload{
+					ID:        event.ID,
+					Type:      eventType,
+					CreatedAt: event.CreatedAt,
+					ActorID:   event.ActorID,
+					Data:      event.Data,
+				}); err != nil {
+					log.Printf("Error encoding webhook payload: %v", err)
+					break
+				}
+			}
+
+			delivery := &models.WebhookDelivery{
+				WebhookID: webhook.ID,
+				EventID:   event.ID,
+				EventType: eventType,
+				Payload:   string(body),
+				Status:    models.WebhookDeliveryPending,
+			}
+			if err := d.webhookService.SaveDelivery(delivery); err != nil {
+				continue
+			}
+			d.enqueue(delivery)
+		}
+	}
+}
+
+// completed indica si una actualización llevó la tarea a un estado final de su flujo
+func (d *WebhookDispatcher) completed(payload events.TaskPayload) bool {
+	if payload.PreviousStatus == payload.Task.Status {
+		return false
+	}
+
+	workflow := models.DefaultWorkflow()
+	if payload.Task.GroupID != nil && *payload.Task.GroupID != "" {
+		group, err := d.groupService.GetGroupByID(*payload.Task.GroupID)
+		if err != nil {
+			log.Printf("Error fetching group workflow for webhook: %v", err)
+			return false
+		}
+		workflow = group.TaskWorkflow()
+	}
+
+	return workflow.IsFinal(payload.Task.Status) && !workflow.IsFinal(payload.PreviousStatus)
+}
+
+// enqueue pone una entrega en la cola; si está llena, la reintenta más tarde
+func (d *WebhookDispatcher) enqueue(delivery *models.WebhookDelivery) {
+	select {
+	case <-d.ctx.Done():
+	case d.queue <- delivery:
+	default:
+		d.schedule(delivery, webhookBaseBackoff)
+	}
+}
+
+// schedule vuelve a poner la entrega en la cola tras la espera indicada
+func (d *WebhookDispatcher) schedule(delivery *models.WebhookDelivery, delay time.Duration) {
+	time.AfterFunc(delay, func() {
+		if d.ctx.Err() == nil {
+			d.enqueue(delivery)
+		}
+	})
+}
+
+// resumePending retoma las entregas pendientes guardadas
+func (d *WebhookDispatcher) resumePending() {
+	deliveries, err := d.webhookService.PendingDeliveries()
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		delay := time.Duration(0)
+		if delivery.NextAttemptAt != nil && delivery.NextAttemptAt.After(now) {
+			delay = delivery.NextAttemptAt.Sub(now)
+		}
+		d.schedule(delivery, delay)
+	}
+}
+
+func (d *WebhookDispatcher) work() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case delivery := <-d.queue:
+			d.deliver(delivery)
+		}
+	}
+}
+
+// deliver envía una entrega y registra el resultado
+func (d *WebhookDispatcher) deliver(delivery *models.WebhookDelivery) {
+	webhook, err := d.webhookService.GetWebhook(delivery.WebhookID)
+	if err != nil {
+		if errors.Is(err, ErrWebhookNotFound) {
+			// El webhook se eliminó junto con su registro de entregas
+			return
+		}
+		d.schedule(delivery, webhookBaseBackoff)
+		return
+	}
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.NextAttemptAt = nil
+	delivery.ResponseStatus = 0
+	delivery.Error = ""
+
+	if !webhook.Active {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.Error = "Webhook is disabled"
+		d.webhookService.SaveDelivery(delivery)
+		return
+	}
+
+	responseStatus, err := d.send(webhook, delivery)
+	delivery.ResponseStatus = responseStatus
+
+	switch {
+	case err == nil:
+		delivery.Status = models.WebhookDeliverySucceeded
+	case d.ctx.Err() != nil:
+		// Apagado del servidor: la entrega se retoma al arrancar
+		delivery.Attempts--
+		delivery.Status = models.WebhookDeliveryPending
+	case delivery.Attempts >= webhookMaxAttempts:
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.Error = err.Error()
+	default:
+		delivery.Status = models.WebhookDeliveryPending
+		delivery.Error = err.Error()
+		backoff := webhookBackoff(delivery.Attempts)
+		next := now.Add(backoff)
+		delivery.NextAttemptAt = &next
+		d.schedule(delivery, backoff)
+	}
+
+	d.webhookService.SaveDelivery(delivery)
+}
+
+// send hace la petición firmada al endpoint del webhook
+func (d *WebhookDispatcher) send(webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, webhook.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TaskManager-Webhooks/1.0")
+	req.Header.Set("X-Webhook-ID", webhook.ID)
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", delivery.ID)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+SignWebhookPayload(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload calcula la firma HMAC-SHA256 (en hexadecimal) de una entrega.
+// Se firma "<timestamp>.<cuerpo>" para que el receptor pueda rechazar reenvíos antiguos.
+func SignWebhookPayload(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff devuelve la espera antes del siguiente intento, con un 20 % de variación aleatoria
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff << uint(attempts-1)
+	if backoff <= 0 || backoff > webhookMaxBackoff {
+		backoff = webhookMaxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(backoff) / 5))
+	return backoff - backoff/10 + jitter
+}
+
+// isBlockedIP indica si la dirección pertenece a la red local o interna
+func isBlockedIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast()
+}
@@ -0,0 +1,457 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/i18n"
+	"task-manager-backend/internal/mailer"
+	"task-manager-backend/internal/models"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrInvalidWorkspace indica que los datos del espacio de trabajo no son válidos
+	ErrInvalidWorkspace = errors.New("invalid workspace data")
+	// ErrWorkspaceInviteNotFound indica que la invitación no existe o es de otro espacio de trabajo
+	ErrWorkspaceInviteNotFound = errors.New("workspace invite not found")
+	// ErrWorkspaceInviteUnusable indica que la invitación ya se usó, caducó o es para otro correo
+	ErrWorkspaceInviteUnusable = errors.New("workspace invite is no longer valid")
+	// ErrWorkspaceInviteExists indica que el correo ya tiene una invitación pendiente
+	ErrWorkspaceInviteExists = errors.New("this email already has a pending invite to the workspace")
+	// ErrAlreadyInWorkspace indica que ya hay un usuario con ese correo en el espacio de trabajo
+	ErrAlreadyInWorkspace = errors.New("a user with this email already belongs to the workspace")
+	// ErrLastAdmin indica que el cambio dejaría el espacio de trabajo sin administradores
+	ErrLastAdmin = errors.New("the workspace must keep at least one admin")
+)
+
+// workspaceInviteTTL es el plazo para registrarse con una invitación
+const workspaceInviteTTL = 7 * 24 * time.Hour
+
+var workspaceInviteTemplate = mustParseMailTemplate("workspace_invite")
+
+// WorkspaceService proporciona métodos para gestionar los espacios de trabajo,
+// sus miembros y sus invitaciones
+type WorkspaceService struct {
+	mailer mailer.Mailer
+	appURL string
+}
+
+// NewWorkspaceService crea una nueva instancia de WorkspaceService. Las
+// invitaciones se envían por correo con m a menos que sea nil; appURL es la
+// dirección de la aplicación que aparece en el correo.
+func NewWorkspaceService(m mailer.Mailer, appURL string) *WorkspaceService {
+	return &WorkspaceService{mailer: m, appURL: appURL}
+}
+
+// GetWorkspace obtiene un espacio de trabajo por su ID. El de por defecto existe
+// aunque todavía no tenga documento.
+func (s *WorkspaceService) GetWorkspace(workspaceID string) (*models.Workspace, error) {
+	ctx := context.Background()
+
+	doc, err := database.Client.Collection("workspaces").Doc(workspaceID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound && workspaceID == models.DefaultWorkspaceID {
+			return defaultWorkspace(), nil
+		}
+		log.Printf("Error getting workspace from Firestore: %v", err)
+		return nil, err
+	}
+
+	var workspace models.Workspace
+	if err := doc.DataTo(&workspace); err != nil {
+		return nil, err
+	}
+
+	return &workspace, nil
+}
+
+// defaultWorkspace es el espacio de trabajo por defecto antes de que se guarde
+func defaultWorkspace() *models.Workspace {
+	return &models.Workspace{ID: models.DefaultWorkspaceID, Name: "Default"}
+}
+
+// CreateWorkspace crea un espacio de trabajo y registra en él al usuario, que
+// pasa a ser su administrador
+func (s *WorkspaceService) CreateWorkspace(name string, user *models.User) (*models.Workspace, error) {
+	ctx := context.Background()
+
+	now := time.Now()
+	workspace := &models.Workspace{
+		ID:        uuid.New().String(),
+		Name:      strings.TrimSpace(name),
+		CreatedBy: user.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if !workspace.Validate() {
+		return nil, ErrInvalidWorkspace
+	}
+
+	user.WorkspaceID = workspace.ID
+	user.WorkspaceRole = models.WorkspaceRoleAdmin
+
+	err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := tx.Create(database.Client.Collection("workspaces").Doc(workspace.ID), workspace); err != nil {
+			return err
+		}
+		return tx.Create(database.Client.Collection("users").Doc(user.ID), user.ToMap())
+	})
+	if err != nil {
+		log.Printf("Error creating workspace: %v", err)
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// RenameWorkspace cambia el nombre del espacio de trabajo
+func (s *WorkspaceService) RenameWorkspace(workspaceID, name string) (*models.Workspace, error) {
+	ctx := context.Background()
+
+	workspaceRef := database.Client.Collection("workspaces").Doc(workspaceID)
+	var workspace *models.Workspace
+
+	err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(workspaceRef)
+		switch {
+		case status.Code(err) == codes.NotFound && workspaceID == models.DefaultWorkspaceID:
+			workspace = defaultWorkspace()
+			workspace.CreatedAt = time.Now()
+		case err != nil:
+			return err
+		default:
+			workspace = &models.Workspace{}
+			if err := doc.DataTo(workspace); err != nil {
+				return err
+			}
+		}
+
+		workspace.Name = strings.TrimSpace(name)
+		workspace.UpdatedAt = time.Now()
+		if !workspace.Validate() {
+			return ErrInvalidWorkspace
+		}
+
+		return tx.Set(workspaceRef, workspace)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrInvalidWorkspace) {
+			log.Printf("Error updating workspace: %v", err)
+		}
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// ListMembers obtiene los usuarios del espacio de trabajo ordenados por nombre
+func (s *WorkspaceService) ListMembers(workspaceID string) ([]models.User, error) {
+	ctx := context.Background()
+
+	docs, err := database.InWorkspace(database.Client.Collection("users").Query, workspaceID).Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error listing workspace members: %v", err)
+		return nil, err
+	}
+
+	members := []models.User{}
+	for _, doc := range docs {
+		user, err := DecodeUser(doc)
+		if err != nil {
+			log.Printf("Error converting document to User: %v", err)
+			continue
+		}
+		members = append(members, *user)
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return strings.ToLower(members[i].Username) < strings.ToLower(members[j].Username)
+	})
+
+	return members, nil
+}
+
+// MemberRole devuelve el rol del usuario en el espacio de trabajo, o "" si no pertenece a él
+func (s *WorkspaceService) MemberRole(workspaceID, userID string) (string, error) {
+	user, err := LoadUser(context.Background(), userID)
+	if err != nil {
+		return "", err
+	}
+	if !models.SameWorkspace(user.WorkspaceID, workspaceID) {
+		return "", nil
+	}
+	if user.WorkspaceRole == "" {
+		return models.WorkspaceRoleMember, nil
+	}
+	return user.WorkspaceRole, nil
+}
+
+// SetMemberRole cambia el rol de un usuario del espacio de trabajo. El espacio
+// de trabajo no puede quedarse sin administradores.
+func (s *WorkspaceService) SetMemberRole(workspaceID, userID, role string) (*models.User, error) {
+	if !models.IsWorkspaceRole(role) {
+		return nil, ErrInvalidRole
+	}
+
+	ctx := context.Background()
+
+	userRef := database.Client.Collection("users").Doc(userID)
+	adminsQuery := database.InWorkspace(database.Client.Collection("users").Query, workspaceID).
+		Where("workspace_role", "==", models.WorkspaceRoleAdmin)
+
+	var user models.User
+	err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(userRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		decoded, err := DecodeUser(doc)
+		if err != nil {
+			return err
+		}
+		user = *decoded
+		if !models.SameWorkspace(user.WorkspaceID, workspaceID) {
+			return ErrUserNotFound
+		}
+
+		if user.WorkspaceRole == models.WorkspaceRoleAdmin && role != models.WorkspaceRoleAdmin {
+			admins, err := tx.Documents(adminsQuery).GetAll()
+			if err != nil {
+				return err
+			}
+			if len(admins) <= 1 {
+				return ErrLastAdmin
+			}
+		}
+
+		user.WorkspaceRole = role
+		return tx.Update(userRef, []firestore.Update{{Path: "workspace_role", Value: role}})
+	})
+	if err != nil {
+		if !errors.Is(err, ErrUserNotFound) && !errors.Is(err, ErrLastAdmin) {
+			log.Printf("Error updating workspace role: %v", err)
+		}
+		return nil, err
+	}
+	UserProfiles.Invalidate(userID)
+
+	return &user, nil
+}
+
+// CreateInvite invita a una dirección de correo a registrarse en el espacio de
+// trabajo con el rol indicado y le envía el enlace de registro. Devuelve el token
+// en claro, que no se puede recuperar después.
+func (s *WorkspaceService) CreateInvite(workspaceID, inviterID, email, role string) (string, *models.WorkspaceInvite, error) {
+	ctx := context.Background()
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !models.IsWorkspaceRole(role) {
+		return "", nil, ErrInvalidRole
+	}
+
+	if _, err := findUserByEmail(ctx, workspaceID, email); err == nil {
+		return "", nil, ErrAlreadyInWorkspace
+	} else if !errors.Is(err, ErrUserNotFound) {
+		return "", nil, err
+	}
+
+	invites, err := s.ListInvites(workspaceID)
+	if err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	for _, existing := range invites {
+		if existing.Email == email && existing.Usable(now) {
+			return "", nil, ErrWorkspaceInviteExists
+		}
+	}
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	invite := &models.WorkspaceInvite{
+		ID:          hashToken(token),
+		WorkspaceID: workspaceID,
+		Email:       email,
+		Role:        role,
+		InviterID:   inviterID,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(workspaceInviteTTL),
+	}
+
+	if _, err := database.Client.Collection("workspace_invites").Doc(invite.ID).Set(ctx, invite); err != nil {
+		log.Printf("Error creating workspace invite in Firestore: %v", err)
+		return "", nil, err
+	}
+
+	s.sendInviteEmail(ctx, invite, token)
+
+	return token, invite, nil
+}
+
+// ListInvites obtiene las invitaciones del espacio de trabajo, de la más reciente a la más antigua
+func (s *WorkspaceService) ListInvites(workspaceID string) ([]models.WorkspaceInvite, error) {
+	ctx := context.Background()
+
+	docs, err := database.InWorkspace(database.Client.Collection("workspace_invites").Query, workspaceID).Documents(ctx).GetAll()
+	if err != nil {
+		log.Printf("Error listing workspace invites: %v", err)
+		return nil, err
+	}
+
+	invites := []models.WorkspaceInvite{}
+	for _, doc := range docs {
+		var invite models.WorkspaceInvite
+		if err := doc.DataTo(&invite); err != nil {
+			log.Printf("Error converting document to WorkspaceInvite: %v", err)
+			continue
+		}
+		invites = append(invites, invite)
+	}
+
+	sort.Slice(invites, func(i, j int) bool { return invites[i].CreatedAt.After(invites[j].CreatedAt) })
+
+	return invites, nil
+}
+
+// RevokeInvite elimina una invitación del espacio de trabajo que aún no se usó
+func (s *WorkspaceService) RevokeInvite(workspaceID, inviteID string) error {
+	ctx := context.Background()
+	inviteRef := database.Client.Collection("workspace_invites").Doc(inviteID)
+
+	return database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(inviteRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrWorkspaceInviteNotFound
+			}
+			return err
+		}
+
+		var invite models.WorkspaceInvite
+		if err := doc.DataTo(&invite); err != nil {
+			return err
+		}
+		if invite.WorkspaceID != workspaceID {
+			return ErrWorkspaceInviteNotFound
+		}
+		if invite.AcceptedBy != nil {
+			return ErrWorkspaceInviteUnusable
+		}
+
+		return tx.Delete(inviteRef)
+	})
+}
+
+// JoinWithInvite registra al usuario en el espacio de trabajo de la invitación,
+// con el rol de la invitación, y la marca como usada. El correo del usuario debe
+// ser el invitado.
+func (s *WorkspaceService) JoinWithInvite(token string, user *models.User) (*models.WorkspaceInvite, error) {
+	ctx := context.Background()
+	inviteRef := database.Client.Collection("workspace_invites").Doc(hashToken(token))
+
+	var invite models.WorkspaceInvite
+	err := database.Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(inviteRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrWorkspaceInviteNotFound
+			}
+			return err
+		}
+
+		invite = models.WorkspaceInvite{}
+		if err := doc.DataTo(&invite); err != nil {
+			return err
+		}
+		now := time.Now()
+		if !invite.Usable(now) || !strings.EqualFold(invite.Email, strings.TrimSpace(user.Email)) {
+			return ErrWorkspaceInviteUnusable
+		}
+
+		user.WorkspaceID = invite.WorkspaceID
+		user.WorkspaceRole = invite.Role
+		if err := tx.Create(database.Client.Collection("users").Doc(user.ID), user.ToMap()); err != nil {
+			return err
+		}
+
+		invite.AcceptedBy = &user.ID
+		invite.AcceptedAt = &now
+		return tx.Update(inviteRef, []firestore.Update{
+			{Path: "accepted_by", Value: user.ID},
+			{Path: "accepted_at", Value: now},
+		})
+	})
+	if err != nil {
+		if !errors.Is(err, ErrWorkspaceInviteNotFound) && !errors.Is(err, ErrWorkspaceInviteUnusable) {
+			log.Printf("Error joining workspace with invite: %v", err)
+		}
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+// sendInviteEmail envía al invitado el enlace de registro, en el idioma de quien
+// invita (el invitado aún no tiene cuenta). Un fallo no anula la invitación.
+func (s *WorkspaceService) sendInviteEmail(ctx context.Context, invite *models.WorkspaceInvite, token string) {
+	if s.mailer == nil {
+		return
+	}
+
+	var inviter string
+	locale := i18n.Default
+	if user, err := LoadUser(ctx, invite.InviterID); err == nil {
+		inviter = user.Username
+		locale = i18n.Of(user.Locale)
+	}
+	if inviter == "" {
+		inviter = i18n.T(locale, "A Task Manager user")
+	}
+	workspaceName := invite.WorkspaceID
+	if workspace, err := s.GetWorkspace(invite.WorkspaceID); err == nil {
+		workspaceName = workspace.Name
+	}
+
+	data := struct {
+		Inviter   string
+		Workspace string
+		Email     string
+		SignupURL string
+		ExpiresAt time.Time
+	}{inviter, workspaceName, invite.Email, strings.TrimRight(s.appURL, "/") + "/register?invite_token=" + url.QueryEscape(token), invite.ExpiresAt}
+
+	text, html, err := workspaceInviteTemplate.Render(locale, data)
+	if err != nil {
+		log.Printf("Error rendering workspace invite email: %v", err)
+		return
+	}
+
+	if err := s.mailer.Send(ctx, mailer.Message{
+		To:      []string{invite.Email},
+		Subject: i18n.T(locale, "email.workspace_invite.subject", inviter, workspaceName),
+		Text:    text,
+		HTML:    html,
+	}); err != nil {
+		log.Printf("Error sending workspace invite email: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -9,8 +10,11 @@ import (
 	"syscall"
 	"task-manager-backend/api/handlers"
 	"task-manager-backend/api/middleware"
+	"task-manager-backend/api/problem"
 	"task-manager-backend/config"
 	"task-manager-backend/internal/database"
+	"task-manager-backend/internal/mailer"
+	"task-manager-backend/internal/services"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -45,17 +49,40 @@ func main() {
 		// Not fatal as Firestore creates collections on first use
 	}
 
-	// Configure router with custom logger and recovery middleware
+	// Los listados filtran por workspace_id: avisar si quedan datos sin migrar
+	if err := database.CheckWorkspaces(ctx); err != nil {
+		log.Printf("Warning: Workspace migration pending: %v", err)
+	}
+
+	// Configure router with custom logger and recovery middleware. Cada línea del
+	// log lleva el ID de correlación, el token de ?access_token se oculta y los
+	// pánicos se responden como errores internos.
 	r := gin.New()
-	r.Use(gin.Logger())
-	r.Use(gin.Recovery())
+	r.Use(middleware.CorrelationID())
+	r.Use(middleware.Locale())
+	r.Use(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		correlationID, _ := param.Keys[problem.CorrelationIDKey].(string)
+		return fmt.Sprintf("[GIN] %s | %s | %3d | %13v | %15s | %-7s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			correlationID,
+			param.StatusCode,
+			param.Latency,
+			param.ClientIP,
+			param.Method,
+			middleware.RedactToken(param.Path),
+			param.ErrorMessage,
+		)
+	}))
+	r.Use(gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		problem.Respond(c, problem.Internal("", fmt.Errorf("panic: %v", recovered)))
+	}))
 
 	// CORS configuration
 	corsConfig := cors.Config{
 		AllowOrigins:     []string{"https://taskman-lac.vercel.app"}, // Especifica el origen permitido
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Accept-Language", "Authorization", "If-Match", "If-None-Match", "Last-Event-ID", middleware.CorrelationIDHeader},
+		ExposeHeaders:    []string{"Content-Length", "ETag", middleware.CorrelationIDHeader},
 		AllowCredentials: true,
 		AllowOriginFunc: func(origin string) bool {
 			for _, allowedOrigin := range cfg.Server.AllowedOrigins {
@@ -69,16 +96,47 @@ func main() {
 	}
 	r.Use(cors.New(corsConfig))
 
+	// Validar peticiones y respuestas contra la especificación OpenAPI: las
+	// peticiones que no la cumplen se rechazan y las respuestas se registran.
+	// Solo en desarrollo, con OPENAPI_VALIDATE=true; los tests la usan siempre.
+	if cfg.Server.ValidateAPI {
+		r.Use(apiSpec.Validate())
+	}
+
 	// Health check endpoint
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "healthy",
-			"time":   time.Now().Format(time.RFC3339),
-		})
+	r.GET("/health", healthCheck)
+
+	// Entregar los eventos a los webhooks en segundo plano
+	webhookDispatcher := services.NewWebhookDispatcher(cfg.Webhooks.AllowPrivateNetworks)
+	webhookDispatcher.Start()
+
+	// Crear las notificaciones de los usuarios en segundo plano
+	notifier := services.NewNotifier()
+	notifier.Start()
+
+	// Enviar los resúmenes por correo a su hora
+	mail, err := mailer.New(mailer.Config{
+		Backend:  cfg.Mail.Backend,
+		From:     cfg.Mail.From,
+		Dir:      cfg.Mail.Dir,
+		SMTPAddr: cfg.Mail.SMTPAddr,
+		Username: cfg.Mail.Username,
+		Password: cfg.Mail.Password,
 	})
+	if err != nil {
+		log.Fatalf("Failed to configure mailer: %v", err)
+	}
+	digestScheduler := services.NewDigestScheduler(mail)
+	digestScheduler.Start()
+
+	invitationService := services.NewInvitationService(mail, cfg.Server.AppURL)
+	workspaceService := services.NewWorkspaceService(mail, cfg.Server.AppURL)
 
 	// Setup routes
-	setupRoutes(r)
+	setupRoutes(r, webhookDispatcher, invitationService, workspaceService)
+	if err := apiSpec.CheckRoutes(r.Routes()); err != nil {
+		log.Fatalf("Invalid API specification: %v", err)
+	}
 
 	// Create server with timeout configurations
 	srv := &http.Server{
@@ -89,6 +147,9 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Los streams de eventos no terminan por sí solos: se cierran al apagar el servidor
+	srv.RegisterOnShutdown(handlers.CloseStreams)
+
 	// Start server in a goroutine
 	go func() {
 		log.Printf("Server starting on port %s", cfg.Server.Port)
@@ -112,50 +173,17 @@ func main() {
 		log.Fatal("Server forced to shutdown:", err)
 	}
 
+	webhookDispatcher.Stop()
+	notifier.Stop()
+	digestScheduler.Stop()
+
 	log.Println("Server exiting")
 }
 
-// setupRoutes extracts route configuration for better organization
-// setupRoutes configura todas las rutas de la aplicación
-func setupRoutes(r *gin.Engine) {
-	api := r.Group("/api")
-
-	// Auth routes
-	auth := api.Group("/auth")
-	{
-		auth.POST("/register", handlers.Register)
-		auth.POST("/login", handlers.Login)
-	}
-
-	// Protected routes
-	protected := api.Group("/")
-	protected.Use(middleware.AuthMiddleware())
-	{
-		// User routes
-		protected.GET("/user", handlers.GetUser)
-
-		// Buscar usuarios por correo electrónico
-		protected.GET("/users/search", handlers.SearchUser)
-
-		// Task routes
-		tasks := protected.Group("/tasks")
-		{
-			tasks.GET("", handlers.GetUserTasks)
-			tasks.POST("", handlers.CreateTask)
-			tasks.PUT("/:id", handlers.UpdateTask)
-			tasks.DELETE("/:id", handlers.DeleteTask)
-			//FOR GET A TASK BY ID
-			tasks.GET("/:id", handlers.GetTaskByID)
-		}
-		// Group routes
-		groupHandler := handlers.NewGroupHandler()
-		groups := protected.Group("/groups")
-		{
-			groups.GET("", groupHandler.GetAllGroupsHandler)
-			groups.POST("", groupHandler.CreateGroupHandler)
-			groups.GET("/:id", groupHandler.GetGroupHandler)
-			groups.POST("/:id/members/:user_id", groupHandler.AddMemberHandler)
-			groups.DELETE("/:id/members/:user_id", groupHandler.RemoveMemberHandler)
-		}
-	}
+// healthCheck responde si el servidor está en marcha
+func healthCheck(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "healthy",
+		"time":   time.Now().Format(time.RFC3339),
+	})
 }
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task-manager-backend/api/handlers"
+	"task-manager-backend/api/middleware"
+	"task-manager-backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestRouter monta las rutas de la aplicación como main, sin Firestore ni
+// servicios en segundo plano. report recibe las respuestas que no cumplen la
+// especificación.
+func newTestRouter(report func(c *gin.Context, err error)) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.CorrelationID())
+	r.Use(middleware.Locale())
+	r.Use(apiSpec.ValidateWith(report))
+	r.GET("/health", healthCheck)
+	setupRoutes(r, nil, nil, nil)
+	return r
+}
+
+func TestAPISpecCoversRoutes(t *testing.T) {
+	r := newTestRouter(func(*gin.Context, error) {})
+	if err := apiSpec.CheckRoutes(r.Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Las peticiones se limitan a las que se responden sin acceder a Firestore:
+// rutas públicas, errores de validación y rutas protegidas sin token válido
+func TestAPIResponsesMatchSpec(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		header     map[string]string
+		wantStatus int
+	}{
+		{name: "health", method: http.MethodGet, path: "/health", wantStatus: http.StatusOK},
+		{name: "openapi document", method: http.MethodGet, path: "/api/openapi.json", wantStatus: http.StatusOK},
+		{name: "docs page", method: http.MethodGet, path: "/api/docs", wantStatus: http.StatusOK},
+		{
+			name: "register with an invite and a workspace name", method: http.MethodPost, path: "/api/auth/register",
+			body:       `{"username":"ana","email":"ana@example.com","password":"secret","role":"user","invite_token":"t","workspace_name":"w"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "register with an unsupported locale", method: http.MethodPost, path: "/api/auth/register",
+			body:       `{"username":"ana","email":"ana@example.com","password":"secret","role":"user","locale":"xx"}`,
+			header:     map[string]string{"Accept-Language": "es"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "login without password", method: http.MethodPost, path: "/api/auth/login",
+			body: `{"username":"ana"}`, wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "login with a malformed body", method: http.MethodPost, path: "/api/auth/login",
+			body: `{"username":`, wantStatus: http.StatusBadRequest,
+		},
+		{name: "tasks without a token", method: http.MethodGet, path: "/api/tasks", wantStatus: http.StatusUnauthorized},
+		{
+			name: "tasks with an invalid token", method: http.MethodGet, path: "/api/tasks",
+			header: map[string]string{"Authorization": "Bearer invalid"}, wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "patch a task without a token", method: http.MethodPatch, path: "/api/tasks/123",
+			body: `{"title":"x"}`, header: map[string]string{"Content-Type": "application/merge-patch+json"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{name: "stream without a token", method: http.MethodGet, path: "/api/stream", wantStatus: http.StatusUnauthorized},
+		{
+			name: "stream with an invalid access_token", method: http.MethodGet, path: "/api/stream?access_token=invalid",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	var mismatch error
+	r := newTestRouter(func(c *gin.Context, err error) { mismatch = err })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mismatch = nil
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			for name, value := range tt.header {
+				req.Header.Set(name, value)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if mismatch != nil {
+				t.Errorf("response %d does not match the API specification: %v; body: %s", w.Code, mismatch, w.Body.String())
+			}
+		})
+	}
+}
+
+func ptr(s string) *string { return &s }
+
+// Valores fijos de los modelos tal como los devuelven los handlers: uno con
+// todos los campos opcionales y otro con los mínimos
+var (
+	testTime = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	fullTask = models.Task{
+		ID: "t1", UserID: "u1", GroupID: ptr("g1"), WorkspaceID: "w1", Title: "Write docs", Description: "API",
+		TimeUntilFinish: 2 * time.Hour, RemindMe: true, Status: models.TaskStatusInProgress, Category: "work",
+		CreatedAt: testTime, UpdatedAt: testTime, CreatedBy: "u1", AssignedTo: ptr("u2"),
+		ArrCollaborators: []string{"u3"}, Version: 3, Rank: "m", Priority: models.TaskPriorityHigh,
+		LabelIDs: []string{"l1"}, Labels: []models.TaskLabel{{ID: "l1", Name: "docs", Color: "#00ff00"}},
+		EstimatedEffort: time.Hour, DueDate: &testTime, ParentID: ptr("t0"), Recurrence: "FREQ=WEEKLY;BYDAY=MO",
+		AssignedAt: &testTime,
+	}
+	minimalTask = models.Task{
+		ID: "t2", UserID: "u1", WorkspaceID: "w1", Title: "Call", Status: models.TaskStatusPending,
+		CreatedAt: testTime, UpdatedAt: testTime, CreatedBy: "u1", Version: 1,
+	}
+
+	fullGroup = models.Group{
+		ID: "g2", WorkspaceID: "w1", CreatorID: "u1", Name: "Docs", Description: "Writers",
+		Members: []string{"u1", "u2"}, Roles: map[string]string{"u1": models.GroupRoleOwner, "u2": models.GroupRoleMember},
+		CreatedAt: testTime, UpdatedAt: testTime, Version: 2, Workflow: models.DefaultWorkflow(),
+		ParentID: ptr("g1"), Path: []string{"g1"}, Inherited: map[string]string{"u3": models.GroupRoleAdmin},
+	}
+	minimalGroup = models.Group{
+		ID: "g1", WorkspaceID: "w1", CreatorID: "u1", Name: "Team",
+		Members: []string{"u1"}, Roles: map[string]string{"u1": models.GroupRoleOwner},
+		CreatedAt: testTime, UpdatedAt: testTime, Version: 1, Path: []string{},
+	}
+
+	member = models.User{
+		ID: "u2", Username: "ana", Email: "ana@example.com", Password: "hash", CreatedAt: testTime, Role: "user",
+		WorkspaceID: "w1", WorkspaceRole: models.WorkspaceRoleMember, Locale: "es",
+	}
+
+	invitation = models.GroupInvitation{
+		ID: "i1", GroupID: "g1", GroupName: "Team", WorkspaceID: "w1", InviterID: "u1", InviteeID: ptr("u2"),
+		Email: "ana@example.com", Status: models.InvitationPending, CreatedAt: testTime, ExpiresAt: testTime,
+	}
+
+	fullNotification = models.Notification{
+		ID: "n1", UserID: "u2", Type: models.NotificationAssignment, Title: "Assigned", Body: "Write docs",
+		ActorID: "u1", TaskID: ptr("t1"), GroupID: ptr("g1"), Read: true, ReadAt: &testTime,
+		CreatedAt: testTime, ExpiresAt: testTime,
+	}
+	minimalNotification = models.Notification{
+		ID: "n2", UserID: "u2", Type: models.NotificationComment, Title: "Comment", CreatedAt: testTime, ExpiresAt: testTime,
+	}
+)
+
+// Las respuestas correctas dependen de Firestore, así que cada caso sirve en la
+// ruta real el mismo cuerpo que construye su handler con valores fijos
+func TestSuccessResponsesMatchSpec(t *testing.T) {
+	workflow := minimalGroup.TaskWorkflow()
+	columns := make([]handlers.BoardColumn, 0, len(workflow.Statuses))
+	for _, status := range workflow.Statuses {
+		tasks := []models.Task{}
+		if status.Key == minimalTask.Status {
+			tasks = append(tasks, fullTask, minimalTask)
+		}
+		columns = append(columns, handlers.BoardColumn{Status: status, Tasks: tasks})
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string // Ruta tal como se registra en setupRoutes
+		status int
+		body   gin.H
+	}{
+		{name: "list tasks", method: http.MethodGet, path: "/api/tasks", status: http.StatusOK,
+			body: gin.H{"tasks": []models.Task{fullTask, minimalTask}}},
+		{name: "no tasks", method: http.MethodGet, path: "/api/tasks", status: http.StatusOK,
+			body: gin.H{"tasks": []models.Task{}}},
+		{name: "get a task", method: http.MethodGet, path: "/api/tasks/:id", status: http.StatusOK,
+			body: gin.H{"task": fullTask}},
+		{name: "create a task", method: http.MethodPost, path: "/api/tasks", status: http.StatusCreated,
+			body: gin.H{"task": minimalTask}},
+		{name: "update a task", method: http.MethodPut, path: "/api/tasks/:id", status: http.StatusOK,
+			body: gin.H{"message": "Task updated successfully", "task": fullTask}},
+		{name: "move a task", method: http.MethodPost, path: "/api/tasks/:id/move", status: http.StatusOK,
+			body: gin.H{"message": "Task moved successfully", "task": minimalTask}},
+		{name: "group tasks", method: http.MethodGet, path: "/api/groups/:id/tasks", status: http.StatusOK,
+			body: gin.H{"tasks": []models.Task{fullTask}}},
+
+		{name: "list groups", method: http.MethodGet, path: "/api/groups", status: http.StatusOK,
+			body: gin.H{"groups": []models.Group{minimalGroup, fullGroup}, "tree": models.GroupTree([]models.Group{minimalGroup, fullGroup})}},
+		{name: "create a group", method: http.MethodPost, path: "/api/groups", status: http.StatusCreated,
+			body: gin.H{"group": minimalGroup, "invitations": []models.GroupInvitation{invitation}}},
+		{name: "get a group", method: http.MethodGet, path: "/api/groups/:id", status: http.StatusOK,
+			body: gin.H{"group": fullGroup, "roles": fullGroup.MemberRoles(), "members": []models.User{member}}},
+		{name: "get a group without member details", method: http.MethodGet, path: "/api/groups/:id", status: http.StatusOK,
+			body: gin.H{"group": minimalGroup, "roles": minimalGroup.MemberRoles()}},
+
+		{name: "board", method: http.MethodGet, path: "/api/groups/:id/board", status: http.StatusOK,
+			body: gin.H{"group_id": minimalGroup.ID, "columns": columns}},
+
+		{name: "list notifications", method: http.MethodGet, path: "/api/notifications", status: http.StatusOK,
+			body: gin.H{"notifications": []models.Notification{fullNotification, minimalNotification}, "unread_count": int64(1)}},
+		{name: "read a notification", method: http.MethodPost, path: "/api/notifications/:id/read", status: http.StatusOK,
+			body: gin.H{"notification": fullNotification}},
+		{name: "notification preferences", method: http.MethodGet, path: "/api/notifications/preferences", status: http.StatusOK,
+			body: gin.H{
+				"preferences": models.NotificationPreferences{UserID: "u2", Types: map[string]bool{models.NotificationComment: false}, UpdatedAt: testTime},
+				"types":       models.NotificationTypes,
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mismatch error
+			r := gin.New()
+			r.Use(apiSpec.ValidateWith(func(c *gin.Context, err error) { mismatch = err }))
+			r.Handle(tt.method, tt.path, func(c *gin.Context) { c.JSON(tt.status, tt.body) })
+
+			url := strings.NewReplacer(":id", "1").Replace(tt.path)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, url, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body: %s", w.Code, tt.status, w.Body.String())
+			}
+			if mismatch != nil {
+				t.Errorf("response does not match the API specification: %v; body: %s", mismatch, w.Body.String())
+			}
+		})
+	}
+}
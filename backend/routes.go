@@ -0,0 +1,484 @@
+package main
+
+import (
+	"net/http"
+	"task-manager-backend/api/handlers"
+	"task-manager-backend/api/middleware"
+	"task-manager-backend/api/openapi"
+	"task-manager-backend/internal/jsonpatch"
+	"task-manager-backend/internal/models"
+	"task-manager-backend/internal/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupRoutes extracts route configuration for better organization
+// setupRoutes configura todas las rutas de la aplicación
+func setupRoutes(r *gin.Engine, webhookDispatcher *services.WebhookDispatcher, invitationService *services.InvitationService, workspaceService *services.WorkspaceService) {
+	api := r.Group("/api")
+
+	// Especificación OpenAPI y su documentación
+	api.GET("/openapi.json", apiSpec.ServeJSON)
+	api.GET("/docs", apiSpec.ServeDocs)
+
+	// Auth routes
+	auth := api.Group("/auth")
+	{
+		auth.POST("/register", handlers.Register)
+		auth.POST("/login", handlers.Login)
+	}
+
+	// Feed iCalendar: el token secreto de la URL sustituye al JWT
+	calendarHandler := handlers.NewCalendarHandler()
+	api.GET("/calendar/:token/tasks.ics", calendarHandler.ServeCalendarFeedHandler)
+
+	// Stream de eventos en tiempo real (SSE); admite el JWT en ?access_token
+	api.GET("/stream", middleware.StreamAuthMiddleware(), handlers.StreamEvents)
+
+	// Protected routes
+	protected := api.Group("/")
+	protected.Use(middleware.AuthMiddleware())
+	{
+		// User routes
+		protected.GET("/user", handlers.GetUser)
+		protected.PUT("/user/locale", handlers.UpdateLocale)
+
+		// Buscar usuarios por correo electrónico
+		protected.GET("/users/search", handlers.SearchUser)
+
+		// Workspace routes
+		workspaceHandler := handlers.NewWorkspaceHandler(workspaceService)
+		workspace := protected.Group("/workspace")
+		{
+			workspace.GET("", workspaceHandler.GetWorkspaceHandler)
+			workspace.PUT("", workspaceHandler.UpdateWorkspaceHandler)
+			workspace.GET("/members", workspaceHandler.GetWorkspaceMembersHandler)
+			workspace.PUT("/members/:user_id/role", workspaceHandler.UpdateWorkspaceMemberRoleHandler)
+			workspace.GET("/invites", workspaceHandler.GetWorkspaceInvitesHandler)
+			workspace.POST("/invites", workspaceHandler.CreateWorkspaceInviteHandler)
+			workspace.DELETE("/invites/:id", workspaceHandler.RevokeWorkspaceInviteHandler)
+		}
+
+		// Notification routes
+		notificationHandler := handlers.NewNotificationHandler()
+		notifications := protected.Group("/notifications")
+		{
+			notifications.GET("", notificationHandler.GetNotificationsHandler)
+			notifications.GET("/unread-count", notificationHandler.GetUnreadCountHandler)
+			notifications.POST("/read-all", notificationHandler.MarkAllReadHandler)
+			notifications.POST("/:id/read", notificationHandler.MarkReadHandler)
+			notifications.GET("/preferences", notificationHandler.GetPreferencesHandler)
+			notifications.PUT("/preferences", notificationHandler.UpdatePreferencesHandler)
+		}
+
+		// Resumen por correo
+		digestHandler := handlers.NewDigestHandler()
+		protected.GET("/digest/settings", digestHandler.GetDigestSettingsHandler)
+		protected.PUT("/digest/settings", digestHandler.UpdateDigestSettingsHandler)
+		protected.GET("/digest/preview", digestHandler.PreviewDigestHandler)
+
+		// Gestión del feed de calendario
+		protected.GET("/calendar/feed", calendarHandler.GetCalendarFeedHandler)
+		protected.POST("/calendar/feed", calendarHandler.RegenerateCalendarFeedHandler)
+		protected.DELETE("/calendar/feed", calendarHandler.RevokeCalendarFeedHandler)
+
+		// Task routes
+		timeHandler := handlers.NewTimeTrackingHandler()
+		templateHandler := handlers.NewTemplateHandler()
+		tasks := protected.Group("/tasks")
+		{
+			tasks.GET("", handlers.GetUserTasks)
+			tasks.POST("", handlers.CreateTask)
+			tasks.POST("/bulk", handlers.BulkUpdateTasks)
+			tasks.GET("/export", handlers.ExportTasks)
+			tasks.POST("/import", handlers.ImportTasks)
+			tasks.PUT("/:id", handlers.UpdateTask)
+			tasks.PATCH("/:id", handlers.PatchTask)
+			tasks.POST("/:id/move", handlers.MoveTask)
+			tasks.POST("/:id/timer/start", timeHandler.StartTimerHandler)
+			tasks.GET("/:id/time-entries", timeHandler.GetTaskTimeEntriesHandler)
+			tasks.POST("/:id/time-entries", timeHandler.CreateTimeEntryHandler)
+			tasks.POST("/from-template/:id", templateHandler.CreateTaskFromTemplateHandler)
+			tasks.DELETE("/:id", handlers.DeleteTask)
+			//FOR GET A TASK BY ID
+			tasks.GET("/:id", handlers.GetTaskByID)
+		}
+		// Time tracking routes
+		protected.GET("/timer", timeHandler.GetActiveTimerHandler)
+		protected.POST("/timer/stop", timeHandler.StopTimerHandler)
+		timeEntries := protected.Group("/time-entries")
+		{
+			timeEntries.GET("/summary", timeHandler.GetTimeSummaryHandler)
+			timeEntries.DELETE("/:id", timeHandler.DeleteTimeEntryHandler)
+		}
+
+		// Template routes
+		templates := protected.Group("/templates")
+		{
+			templates.GET("", templateHandler.GetTemplatesHandler)
+			templates.POST("", templateHandler.CreateTemplateHandler)
+			templates.GET("/:id", templateHandler.GetTemplateHandler)
+			templates.PUT("/:id", templateHandler.UpdateTemplateHandler)
+			templates.DELETE("/:id", templateHandler.DeleteTemplateHandler)
+		}
+
+		// Label routes
+		labelHandler := handlers.NewLabelHandler()
+		labels := protected.Group("/labels")
+		{
+			labels.GET("", labelHandler.GetLabelsHandler)
+			labels.POST("", labelHandler.CreateLabelHandler)
+			labels.PUT("/:id", labelHandler.UpdateLabelHandler)
+			labels.DELETE("/:id", labelHandler.DeleteLabelHandler)
+		}
+
+		// Webhook routes
+		webhookHandler := handlers.NewWebhookHandler(webhookDispatcher)
+		webhooks := protected.Group("/webhooks")
+		{
+			webhooks.GET("", webhookHandler.GetWebhooksHandler)
+			webhooks.POST("", webhookHandler.CreateWebhookHandler)
+			webhooks.GET("/:id", webhookHandler.GetWebhookHandler)
+			webhooks.PUT("/:id", webhookHandler.UpdateWebhookHandler)
+			webhooks.DELETE("/:id", webhookHandler.DeleteWebhookHandler)
+			webhooks.GET("/:id/deliveries", webhookHandler.GetWebhookDeliveriesHandler)
+			webhooks.POST("/:id/deliveries/:delivery_id/redeliver", webhookHandler.RedeliverWebhookHandler)
+		}
+
+		// Invitation routes
+		invitationHandler := handlers.NewInvitationHandler(invitationService)
+		invitations := protected.Group("/invitations")
+		{
+			invitations.GET("", invitationHandler.GetMyInvitationsHandler)
+			invitations.POST("/:id/accept", invitationHandler.AcceptInvitationHandler)
+			invitations.POST("/:id/decline", invitationHandler.DeclineInvitationHandler)
+		}
+		protected.GET("/invite-links/:token", invitationHandler.GetInviteLinkHandler)
+		protected.POST("/invite-links/:token/join", invitationHandler.JoinWithInviteLinkHandler)
+
+		// Group routes
+		groupHandler := handlers.NewGroupHandler(invitationService)
+		groups := protected.Group("/groups")
+		{
+			groups.GET("", groupHandler.GetAllGroupsHandler)
+			groups.POST("", groupHandler.CreateGroupHandler)
+			groups.GET("/:id", groupHandler.GetGroupHandler)
+			groups.PUT("/:id", groupHandler.UpdateGroupHandler)
+			groups.DELETE("/:id", groupHandler.DeleteGroupHandler)
+			groups.POST("/:id/leave", groupHandler.LeaveGroupHandler)
+			groups.POST("/:id/members/:user_id", groupHandler.AddMemberHandler)
+			groups.GET("/:id/invitations", invitationHandler.GetGroupInvitationsHandler)
+			groups.POST("/:id/invitations", invitationHandler.CreateInvitationHandler)
+			groups.DELETE("/:id/invitations/:invitation_id", invitationHandler.RevokeInvitationHandler)
+			groups.GET("/:id/invite-links", invitationHandler.GetInviteLinksHandler)
+			groups.POST("/:id/invite-links", invitationHandler.CreateInviteLinkHandler)
+			groups.DELETE("/:id/invite-links/:link_id", invitationHandler.RevokeInviteLinkHandler)
+			groups.DELETE("/:id/members/:user_id", groupHandler.RemoveMemberHandler)
+			groups.PUT("/:id/members/:user_id/role", groupHandler.UpdateMemberRoleHandler)
+			groups.POST("/:id/transfer-ownership", groupHandler.TransferOwnershipHandler)
+			groups.POST("/:id/move", groupHandler.MoveGroupHandler)
+			groups.PUT("/:id/workflow", groupHandler.UpdateWorkflowHandler)
+			groups.GET("/:id/tasks", groupHandler.GetGroupTasksHandler)
+			groups.GET("/:id/board", groupHandler.GetBoardHandler)
+		}
+	}
+}
+
+// apiSpec es la especificación OpenAPI de la API, servida en /api/openapi.json.
+// Cada ruta de setupRoutes debe tener su operación en apiOperations, justo
+// debajo: main comprueba al arrancar que coinciden (ver Spec.CheckRoutes). Los
+// textos de las operaciones están en inglés porque forman parte del documento
+// que leen los clientes.
+var apiSpec = openapi.New(openapi.Info{
+	Title:   "Task Manager API",
+	Version: "1.0.0",
+	Description: "Durations such as time_until_finish are integers in nanoseconds. " +
+		"Errors are application/problem+json documents with a stable code.",
+}, apiOperations)
+
+// Filtros de la lista de tareas (ver filterAndSortTasks)
+var taskFilters = []openapi.Parameter{
+	{Name: "label", Description: "Label ID; repeat it to require several labels"},
+	{Name: "priority", Description: "Comma-separated priorities (low, medium, high, urgent)"},
+	{Name: "status", Description: "Comma-separated statuses"},
+	{Name: "sort", Description: "priority, created_at, updated_at, title or rank; prefix with - to reverse"},
+}
+
+// groupScope es el parámetro de las rutas que trabajan sobre un grupo o sobre
+// los recursos personales del usuario
+var groupScope = openapi.Parameter{Name: "group_id", Description: "Group ID; the user's own resources if omitted"}
+
+// message es la respuesta de las operaciones que solo confirman el resultado
+var message = openapi.Object{"message": openapi.String("Localized confirmation")}
+
+// memberRoles es la respuesta de los cambios en los miembros de un grupo
+var memberRoles = openapi.Object{"group": models.Group{}, "roles": map[string]string{}}
+
+var apiOperations = []openapi.Operation{
+	{Method: http.MethodGet, Path: "/health", Tag: "health", Summary: "Health check", Public: true,
+		Response: openapi.Object{"status": "", "time": openapi.String("RFC 3339 time")}},
+
+	// Especificación y documentación
+	{Method: http.MethodGet, Path: "/api/openapi.json", Tag: "docs", Summary: "This OpenAPI document", Public: true,
+		Response: openapi.Any("OpenAPI 3 document")},
+	{Method: http.MethodGet, Path: "/api/docs", Tag: "docs", Summary: "API reference page", Public: true,
+		ResponseMedia: map[string]interface{}{"text/html": openapi.String("")}},
+
+	// Autenticación
+	{Method: http.MethodPost, Path: "/api/auth/register", Tag: "auth", Summary: "Register a user", Public: true,
+		Body: handlers.RegisterRequest{}, Status: http.StatusCreated,
+		Response: openapi.Object{"message": "", "workspace_id": ""}},
+	{Method: http.MethodPost, Path: "/api/auth/login", Tag: "auth", Summary: "Log in and get a bearer token", Public: true,
+		Body:     handlers.LoginRequest{},
+		Response: openapi.Object{"token": openapi.String("JWT for the Authorization header"), "username": "", "role": "", "workspace_id": ""}},
+
+	// Feeds iCalendar y eventos en tiempo real
+	{Method: http.MethodGet, Path: "/api/calendar/:token/tasks.ics", Tag: "calendar", Summary: "iCalendar feed of the user's tasks", Public: true,
+		Query:         []openapi.Parameter{{Name: "type", Schema: openapi.Enum("Calendar component of each task", "todo", "event")}},
+		ResponseMedia: map[string]interface{}{"text/calendar": openapi.String("")}},
+	{Method: http.MethodGet, Path: "/api/stream", Tag: "events", Summary: "Server-sent events with the changes visible to the user",
+		Description: "The token may also be sent as access_token, since EventSource cannot set headers. Resumes after Last-Event-ID or last_event_id.",
+		Query: []openapi.Parameter{
+			{Name: "access_token", Description: "Bearer token"},
+			{Name: "last_event_id", Description: "Last event received"},
+		},
+		ResponseMedia: map[string]interface{}{"text/event-stream": openapi.String("")}},
+
+	// Usuario
+	{Method: http.MethodGet, Path: "/api/user", Tag: "users", Summary: "Current user",
+		Response: openapi.Object{"user": models.User{}}},
+	{Method: http.MethodPut, Path: "/api/user/locale", Tag: "users", Summary: "Set the preferred language",
+		Body:     handlers.UpdateLocaleRequest{},
+		Response: openapi.Object{"locale": openapi.String("Stored locale; empty if cleared"), "message": ""}},
+	{Method: http.MethodGet, Path: "/api/users/search", Tag: "users", Summary: "Find users of the workspace by email",
+		Query:    []openapi.Parameter{{Name: "email", Required: true}},
+		Response: openapi.Object{"users": openapi.Optional{Value: []models.User{}}, "message": openapi.Optional{Value: ""}}},
+
+	// Espacio de trabajo
+	{Method: http.MethodGet, Path: "/api/workspace", Tag: "workspace", Summary: "Current workspace and the user's role",
+		Response: openapi.Object{"workspace": models.Workspace{}, "role": ""}},
+	{Method: http.MethodPut, Path: "/api/workspace", Tag: "workspace", Summary: "Rename the workspace",
+		Body: handlers.WorkspaceRequest{}, Response: openapi.Object{"workspace": models.Workspace{}}},
+	{Method: http.MethodGet, Path: "/api/workspace/members", Tag: "workspace", Summary: "Workspace members",
+		Response: openapi.Object{"members": []models.User{}}},
+	{Method: http.MethodPut, Path: "/api/workspace/members/:user_id/role", Tag: "workspace", Summary: "Change a member's workspace role",
+		Body: handlers.WorkspaceRoleRequest{}, Response: openapi.Object{"user": models.User{}}},
+	{Method: http.MethodGet, Path: "/api/workspace/invites", Tag: "workspace", Summary: "Pending workspace invites",
+		Response: openapi.Object{"invites": []models.WorkspaceInvite{}}},
+	{Method: http.MethodPost, Path: "/api/workspace/invites", Tag: "workspace", Summary: "Invite someone to the workspace",
+		Body: handlers.WorkspaceInviteRequest{}, Status: http.StatusCreated,
+		Response: openapi.Object{"invite": models.WorkspaceInvite{}, "token": ""}},
+	{Method: http.MethodDelete, Path: "/api/workspace/invites/:id", Tag: "workspace", Summary: "Revoke a workspace invite",
+		Response: message},
+
+	// Notificaciones
+	{Method: http.MethodGet, Path: "/api/notifications", Tag: "notifications", Summary: "The user's notifications",
+		Query:    []openapi.Parameter{{Name: "unread", Schema: openapi.Boolean("Only unread notifications")}},
+		Response: openapi.Object{"notifications": []models.Notification{}, "unread_count": int64(0)}},
+	{Method: http.MethodGet, Path: "/api/notifications/unread-count", Tag: "notifications", Summary: "Number of unread notifications",
+		Response: openapi.Object{"unread_count": int64(0)}},
+	{Method: http.MethodPost, Path: "/api/notifications/read-all", Tag: "notifications", Summary: "Mark every notification as read",
+		Response: openapi.Object{"updated": 0}},
+	{Method: http.MethodPost, Path: "/api/notifications/:id/read", Tag: "notifications", Summary: "Mark a notification as read",
+		Response: openapi.Object{"notification": models.Notification{}}},
+	{Method: http.MethodGet, Path: "/api/notifications/preferences", Tag: "notifications", Summary: "Notification preferences",
+		Response: openapi.Object{"preferences": models.NotificationPreferences{}, "types": models.NotificationTypes}},
+	{Method: http.MethodPut, Path: "/api/notifications/preferences", Tag: "notifications", Summary: "Enable or disable notification types",
+		Body:     handlers.NotificationPreferencesRequest{},
+		Response: openapi.Object{"preferences": models.NotificationPreferences{}, "types": models.NotificationTypes}},
+
+	// Resumen por correo
+	{Method: http.MethodGet, Path: "/api/digest/settings", Tag: "digest", Summary: "Email digest settings",
+		Response: openapi.Object{"settings": models.DigestSettings{}}},
+	{Method: http.MethodPut, Path: "/api/digest/settings", Tag: "digest", Summary: "Update the email digest settings",
+		Body: handlers.DigestSettingsRequest{}, Response: openapi.Object{"settings": models.DigestSettings{}}},
+	{Method: http.MethodGet, Path: "/api/digest/preview", Tag: "digest", Summary: "Preview the next digest email",
+		Query: []openapi.Parameter{{Name: "format", Description: "text for the plain text version; HTML otherwise"}},
+		ResponseMedia: map[string]interface{}{
+			"text/html":  openapi.String(""),
+			"text/plain": openapi.String(""),
+		}},
+
+	// Feed de calendario
+	{Method: http.MethodGet, Path: "/api/calendar/feed", Tag: "calendar", Summary: "The user's calendar feed",
+		Response: openapi.Object{"active": false, "feed": (*services.CalendarFeed)(nil)}},
+	{Method: http.MethodPost, Path: "/api/calendar/feed", Tag: "calendar", Summary: "Create or regenerate the calendar feed URL",
+		Status:   http.StatusCreated,
+		Response: openapi.Object{"token": "", "url": "", "feed": services.CalendarFeed{}}},
+	{Method: http.MethodDelete, Path: "/api/calendar/feed", Tag: "calendar", Summary: "Revoke the calendar feed",
+		Response: message},
+
+	// Tareas
+	{Method: http.MethodGet, Path: "/api/tasks", Tag: "tasks", Summary: "Tasks visible to the user",
+		Query: taskFilters, Response: openapi.Object{"tasks": []models.Task{}}},
+	{Method: http.MethodPost, Path: "/api/tasks", Tag: "tasks", Summary: "Create a task",
+		Body: handlers.CreateTaskRequest{}, Status: http.StatusCreated, Response: openapi.Object{"task": models.Task{}}},
+	{Method: http.MethodPost, Path: "/api/tasks/bulk", Tag: "tasks", Summary: "Change the status, labels or assignee of many tasks, or delete them",
+		Body:     handlers.BulkTaskRequest{},
+		Response: openapi.Object{"results": []handlers.BulkTaskResult{}, "succeeded": 0, "failed": 0}},
+	{Method: http.MethodGet, Path: "/api/tasks/export", Tag: "tasks", Summary: "Export tasks as JSON or CSV",
+		Query: append([]openapi.Parameter{
+			{Name: "format", Schema: openapi.Enum("Export format (json by default)", "json", "csv")},
+			groupScope,
+		}, taskFilters...),
+		Response:      openapi.Object{"tasks": []handlers.TaskRecord{}},
+		ResponseMedia: map[string]interface{}{"text/csv": openapi.String("One task per row, with a header")}},
+	{Method: http.MethodPost, Path: "/api/tasks/import", Tag: "tasks", Summary: "Import tasks from JSON or CSV",
+		Query: []openapi.Parameter{
+			groupScope,
+			{Name: "dry_run", Schema: openapi.Boolean("Only validate the rows")},
+		},
+		Body:      openapi.Object{"tasks": []handlers.TaskRecord{}},
+		BodyMedia: map[string]interface{}{"text/csv": openapi.String("Same columns as the CSV export")},
+		Response:  openapi.Object{"dry_run": false, "imported": 0, "failed": 0, "results": []handlers.ImportRowResult{}}},
+	{Method: http.MethodPut, Path: "/api/tasks/:id", Tag: "tasks", Summary: "Update a task",
+		Body:     handlers.UpdateTaskRequest{},
+		Response: openapi.Object{"message": "", "task": models.Task{}}},
+	{Method: http.MethodPatch, Path: "/api/tasks/:id", Tag: "tasks", Summary: "Patch a task",
+		Description: "Accepts JSON Merge Patch (application/merge-patch+json or application/json) and JSON Patch (application/json-patch+json) documents.",
+		Body:        openapi.Any("JSON Merge Patch document"),
+		BodyMedia: map[string]interface{}{
+			"application/merge-patch+json": openapi.Any("JSON Merge Patch document"),
+			"application/json-patch+json":  []jsonpatch.Operation{},
+		},
+		Response: openapi.Object{"message": "", "task": models.Task{}}},
+	{Method: http.MethodPost, Path: "/api/tasks/:id/move", Tag: "tasks", Summary: "Move a task on the board",
+		Body:     handlers.MoveTaskRequest{},
+		Response: openapi.Object{"message": "", "task": models.Task{}}},
+	{Method: http.MethodPost, Path: "/api/tasks/:id/timer/start", Tag: "time", Summary: "Start the timer on a task",
+		Status: http.StatusCreated, Response: openapi.Object{"time_entry": models.TimeEntry{}}},
+	{Method: http.MethodGet, Path: "/api/tasks/:id/time-entries", Tag: "time", Summary: "Time entries of a task",
+		Response: openapi.Object{"time_entries": []models.TimeEntry{}, "total": time.Duration(0), "estimated_effort": time.Duration(0)}},
+	{Method: http.MethodPost, Path: "/api/tasks/:id/time-entries", Tag: "time", Summary: "Log time on a task",
+		Body: handlers.ManualTimeEntryRequest{}, Status: http.StatusCreated, Response: openapi.Object{"time_entry": models.TimeEntry{}}},
+	{Method: http.MethodPost, Path: "/api/tasks/from-template/:id", Tag: "templates", Summary: "Create a task and its subtasks from a template",
+		Body: handlers.FromTemplateRequest{}, OptionalBody: true, Status: http.StatusCreated,
+		Response: openapi.Object{"task": models.Task{}, "subtasks": []models.Task{}}},
+	{Method: http.MethodDelete, Path: "/api/tasks/:id", Tag: "tasks", Summary: "Delete a task",
+		Response: message},
+	{Method: http.MethodGet, Path: "/api/tasks/:id", Tag: "tasks", Summary: "Get a task",
+		Description: "Supports If-None-Match with the ETag of the task.",
+		Response:    openapi.Object{"task": models.Task{}}},
+
+	// Registro de tiempo
+	{Method: http.MethodGet, Path: "/api/timer", Tag: "time", Summary: "The user's running timer",
+		Response: openapi.Object{"timer": (*services.ActiveTimer)(nil)}},
+	{Method: http.MethodPost, Path: "/api/timer/stop", Tag: "time", Summary: "Stop the running timer",
+		Response: openapi.Object{"time_entry": models.TimeEntry{}}},
+	{Method: http.MethodGet, Path: "/api/time-entries/summary", Tag: "time", Summary: "Total time by task, user, group or date",
+		Query: []openapi.Parameter{
+			{Name: "task_id"},
+			{Name: "user_id"},
+			{Name: "group_id"},
+			{Name: "group_by", Schema: openapi.Enum("Bucket key (task by default)", "task", "user", "group", "date")},
+			{Name: "from", Description: "RFC 3339 time or YYYY-MM-DD date"},
+			{Name: "to", Description: "RFC 3339 time or YYYY-MM-DD date, inclusive"},
+			{Name: "tz", Description: "IANA time zone of the dates (UTC by default)"},
+		},
+		Response: openapi.Object{"group_by": "", "buckets": []services.TimeSummaryBucket{}, "total": time.Duration(0)}},
+	{Method: http.MethodDelete, Path: "/api/time-entries/:id", Tag: "time", Summary: "Delete a time entry",
+		Response: message},
+
+	// Plantillas
+	{Method: http.MethodGet, Path: "/api/templates", Tag: "templates", Summary: "Task templates",
+		Query: []openapi.Parameter{groupScope}, Response: openapi.Object{"templates": []models.TaskTemplate{}}},
+	{Method: http.MethodPost, Path: "/api/templates", Tag: "templates", Summary: "Create a task template",
+		Body: handlers.TemplateRequest{}, Status: http.StatusCreated, Response: openapi.Object{"template": models.TaskTemplate{}}},
+	{Method: http.MethodGet, Path: "/api/templates/:id", Tag: "templates", Summary: "Get a task template",
+		Response: openapi.Object{"template": models.TaskTemplate{}}},
+	{Method: http.MethodPut, Path: "/api/templates/:id", Tag: "templates", Summary: "Update a task template",
+		Body: handlers.TemplateRequest{}, Response: openapi.Object{"template": models.TaskTemplate{}}},
+	{Method: http.MethodDelete, Path: "/api/templates/:id", Tag: "templates", Summary: "Delete a task template",
+		Response: message},
+
+	// Etiquetas
+	{Method: http.MethodGet, Path: "/api/labels", Tag: "labels", Summary: "Labels",
+		Query: []openapi.Parameter{groupScope}, Response: openapi.Object{"labels": []models.Label{}}},
+	{Method: http.MethodPost, Path: "/api/labels", Tag: "labels", Summary: "Create a label",
+		Body: handlers.LabelRequest{}, Status: http.StatusCreated, Response: openapi.Object{"label": models.Label{}}},
+	{Method: http.MethodPut, Path: "/api/labels/:id", Tag: "labels", Summary: "Update a label",
+		Body: handlers.LabelRequest{}, Response: openapi.Object{"label": models.Label{}}},
+	{Method: http.MethodDelete, Path: "/api/labels/:id", Tag: "labels", Summary: "Delete a label",
+		Response: message},
+
+	// Webhooks
+	{Method: http.MethodGet, Path: "/api/webhooks", Tag: "webhooks", Summary: "Webhooks and the events they can subscribe to",
+		Query:    []openapi.Parameter{groupScope},
+		Response: openapi.Object{"webhooks": []models.Webhook{}, "events": models.WebhookEvents}},
+	{Method: http.MethodPost, Path: "/api/webhooks", Tag: "webhooks", Summary: "Register a webhook",
+		Description: "The HMAC secret is only returned here.",
+		Body:        handlers.WebhookRequest{}, Status: http.StatusCreated,
+		Response: openapi.Object{"webhook": models.Webhook{}, "secret": ""}},
+	{Method: http.MethodGet, Path: "/api/webhooks/:id", Tag: "webhooks", Summary: "Get a webhook",
+		Response: openapi.Object{"webhook": models.Webhook{}}},
+	{Method: http.MethodPut, Path: "/api/webhooks/:id", Tag: "webhooks", Summary: "Update a webhook",
+		Body: handlers.WebhookRequest{}, Response: openapi.Object{"webhook": models.Webhook{}}},
+	{Method: http.MethodDelete, Path: "/api/webhooks/:id", Tag: "webhooks", Summary: "Delete a webhook",
+		Response: message},
+	{Method: http.MethodGet, Path: "/api/webhooks/:id/deliveries", Tag: "webhooks", Summary: "Recent deliveries of a webhook",
+		Response: openapi.Object{"deliveries": []models.WebhookDelivery{}}},
+	{Method: http.MethodPost, Path: "/api/webhooks/:id/deliveries/:delivery_id/redeliver", Tag: "webhooks", Summary: "Send a delivery again",
+		Status: http.StatusAccepted, Response: openapi.Object{"delivery": models.WebhookDelivery{}}},
+
+	// Invitaciones
+	{Method: http.MethodGet, Path: "/api/invitations", Tag: "invitations", Summary: "The user's pending group invitations",
+		Response: openapi.Object{"invitations": []models.GroupInvitation{}}},
+	{Method: http.MethodPost, Path: "/api/invitations/:id/accept", Tag: "invitations", Summary: "Accept a group invitation",
+		Response: openapi.Object{"invitation": models.GroupInvitation{}}},
+	{Method: http.MethodPost, Path: "/api/invitations/:id/decline", Tag: "invitations", Summary: "Decline a group invitation",
+		Response: openapi.Object{"invitation": models.GroupInvitation{}}},
+	{Method: http.MethodGet, Path: "/api/invite-links/:token", Tag: "invitations", Summary: "Group behind an invite link",
+		Response: openapi.Object{"group_id": "", "group_name": "", "description": "", "valid": false, "expires_at": (*time.Time)(nil)}},
+	{Method: http.MethodPost, Path: "/api/invite-links/:token/join", Tag: "invitations", Summary: "Join a group with an invite link",
+		Response: openapi.Object{"message": "", "group_id": ""}},
+
+	// Grupos
+	{Method: http.MethodGet, Path: "/api/groups", Tag: "groups", Summary: "The user's groups, as a list and as a tree",
+		Response: openapi.Object{"groups": []models.Group{}, "tree": []*models.GroupNode{}}},
+	{Method: http.MethodPost, Path: "/api/groups", Tag: "groups", Summary: "Create a group",
+		Description: "The creator is the only member; every other user in members is sent an invitation, returned in invitations.",
+		Body:        models.Group{}, Status: http.StatusCreated,
+		Response: openapi.Object{"group": models.Group{}, "invitations": []models.GroupInvitation{}}},
+	{Method: http.MethodGet, Path: "/api/groups/:id", Tag: "groups", Summary: "Get a group with its members",
+		Description: "Supports If-None-Match with the ETag of the group.",
+		Response:    openapi.Object{"group": models.Group{}, "roles": map[string]string{}, "members": openapi.Optional{Value: []models.User{}}}},
+	{Method: http.MethodPut, Path: "/api/groups/:id", Tag: "groups", Summary: "Update a group",
+		Body: handlers.UpdateGroupRequest{}, Response: openapi.Object{"group": models.Group{}}},
+	{Method: http.MethodDelete, Path: "/api/groups/:id", Tag: "groups", Summary: "Delete a group",
+		Query: []openapi.Parameter{{Name: "tasks", Schema: openapi.Enum("What happens to the group's tasks (archive by default)",
+			services.GroupTasksArchive, services.GroupTasksReassign, services.GroupTasksDelete)}},
+		Response: openapi.Object{"message": "", "tasks": ""}},
+	{Method: http.MethodPost, Path: "/api/groups/:id/leave", Tag: "groups", Summary: "Leave a group",
+		Response: message},
+	{Method: http.MethodPost, Path: "/api/groups/:id/members/:user_id", Tag: "groups", Summary: "Invite a user to the group",
+		Status: http.StatusCreated, Response: openapi.Object{"invitation": models.GroupInvitation{}}},
+	{Method: http.MethodGet, Path: "/api/groups/:id/invitations", Tag: "invitations", Summary: "Invitations of a group",
+		Response: openapi.Object{"invitations": []models.GroupInvitation{}}},
+	{Method: http.MethodPost, Path: "/api/groups/:id/invitations", Tag: "invitations", Summary: "Invite a user or an email address to the group",
+		Body: handlers.InvitationRequest{}, Status: http.StatusCreated, Response: openapi.Object{"invitation": models.GroupInvitation{}}},
+	{Method: http.MethodDelete, Path: "/api/groups/:id/invitations/:invitation_id", Tag: "invitations", Summary: "Revoke a group invitation",
+		Response: message},
+	{Method: http.MethodGet, Path: "/api/groups/:id/invite-links", Tag: "invitations", Summary: "Invite links of a group",
+		Response: openapi.Object{"links": []models.InviteLink{}}},
+	{Method: http.MethodPost, Path: "/api/groups/:id/invite-links", Tag: "invitations", Summary: "Create an invite link",
+		Body: handlers.InviteLinkRequest{}, Status: http.StatusCreated,
+		Response: openapi.Object{"link": models.InviteLink{}, "token": "", "url": ""}},
+	{Method: http.MethodDelete, Path: "/api/groups/:id/invite-links/:link_id", Tag: "invitations", Summary: "Revoke an invite link",
+		Response: message},
+	{Method: http.MethodDelete, Path: "/api/groups/:id/members/:user_id", Tag: "groups", Summary: "Remove a member from the group",
+		Response: message},
+	{Method: http.MethodPut, Path: "/api/groups/:id/members/:user_id/role", Tag: "groups", Summary: "Change a member's role",
+		Body: handlers.MemberRoleRequest{}, Response: memberRoles},
+	{Method: http.MethodPost, Path: "/api/groups/:id/transfer-ownership", Tag: "groups", Summary: "Make another member the owner",
+		Body: handlers.TransferOwnershipRequest{}, Response: memberRoles},
+	{Method: http.MethodPost, Path: "/api/groups/:id/move", Tag: "groups", Summary: "Move the group under another parent",
+		Body: handlers.MoveGroupRequest{}, Response: memberRoles},
+	{Method: http.MethodPut, Path: "/api/groups/:id/workflow", Tag: "groups", Summary: "Replace the group's task workflow",
+		Body: models.Workflow{}, Response: openapi.Object{"group": models.Group{}}},
+	{Method: http.MethodGet, Path: "/api/groups/:id/tasks", Tag: "groups", Summary: "Tasks of a group",
+		Query:    append([]openapi.Parameter{{Name: "assigned_to", Description: "Only tasks assigned to this user"}}, taskFilters...),
+		Response: openapi.Object{"tasks": []models.Task{}}},
+	{Method: http.MethodGet, Path: "/api/groups/:id/board", Tag: "groups", Summary: "Board of a group, one column per status",
+		Response: openapi.Object{"group_id": "", "columns": []handlers.BoardColumn{}}},
+}